@@ -0,0 +1,30 @@
+/**
+* Copyright 2014 Comcast Cable Communications Management, LLC
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+* http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package rallyresttoolkit
+
+import (
+	"context"
+
+	"github.com/aleksofficial/go-rally-rest-toolkit/models"
+)
+
+// QueryArtifacts - queries the artifact endpoint, which returns defects,
+// stories, tasks and other work items together. Set opts.Types to limit the
+// concrete types, and use each result's Type and Decode to tell them apart.
+func (s *RallyClient) QueryArtifacts(ctx context.Context, query map[string]string, opts QueryOptions) (Page[models.Artifact], error) {
+	return queryPage[models.Artifact](ctx, s, query, "artifact", opts)
+}
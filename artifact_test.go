@@ -0,0 +1,76 @@
+/**
+* Copyright 2014 Comcast Cable Communications Management, LLC
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+* http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package rallyresttoolkit_test
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/aleksofficial/go-rally-rest-toolkit"
+	"github.com/aleksofficial/go-rally-rest-toolkit/fakes"
+	"github.com/aleksofficial/go-rally-rest-toolkit/models"
+)
+
+func TestQueryArtifacts_MixedTypes(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{
+		FakeResponse: jsonResponse(`{"QueryResult": {"TotalResultCount": 2, "StartIndex": 1, "Results": [
+			{"_type": "Defect", "ObjectID": 1, "FormattedID": "DE1", "Name": "Crash on save", "State": "Open"},
+			{"_type": "HierarchicalRequirement", "ObjectID": 2, "FormattedID": "US2", "Name": "Save drafts", "ScheduleState": "Defined"}]}}`),
+	}
+	rallyClient := New("abcdef", "http://myRallyUrl", fakeClient)
+
+	opts := QueryOptions{Types: []string{"defect", "hierarchicalrequirement"}}
+	page, err := rallyClient.QueryArtifacts(context.Background(), map[string]string{"Project.Name": "Payments"}, opts)
+	if err != nil {
+		t.Fatalf("QueryArtifacts failed unexpectedly: %v", err)
+	}
+	if got := fakeClient.SpyRequest.URL.Path; got != "/artifact" {
+		t.Errorf("unexpected path %s", got)
+	}
+	if got := fakeClient.SpyRequest.URL.Query().Get("types"); got != "defect,hierarchicalrequirement" {
+		t.Errorf("unexpected types parameter %q", got)
+	}
+
+	if len(page.Results) != 2 {
+		t.Fatalf("expected 2 artifacts, got %d", len(page.Results))
+	}
+	for _, artifact := range page.Results {
+		switch artifact.Type {
+		case "Defect":
+			var defect models.Defect
+			if err := artifact.Decode(&defect); err != nil {
+				t.Fatalf("failed to decode defect: %v", err)
+			}
+			if defect.FormattedID != "DE1" || defect.State != "Open" {
+				t.Errorf("unexpected defect: %+v", defect)
+			}
+		case "HierarchicalRequirement":
+			var story models.HierarchicalRequirement
+			if err := artifact.Decode(&story); err != nil {
+				t.Fatalf("failed to decode story: %v", err)
+			}
+			if story.FormattedID != "US2" || story.ScheduleState != "Defined" {
+				t.Errorf("unexpected story: %+v", story)
+			}
+		default:
+			t.Errorf("unexpected type %q", artifact.Type)
+		}
+	}
+	if page.Results[0].Name != "Crash on save" || page.Results[1].Name != "Save drafts" {
+		t.Errorf("expected common fields to be read, got %q and %q", page.Results[0].Name, page.Results[1].Name)
+	}
+}
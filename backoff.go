@@ -0,0 +1,165 @@
+/**
+* Copyright 2014 Comcast Cable Communications Management, LLC
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+* http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package rallyresttoolkit
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"time"
+)
+
+// BackoffStrategy computes how long to wait before a retry. attempt is zero for
+// the first retry and baseDelay is the configured RetryDelay. Jitter is added to
+// the returned delay by the client.
+type BackoffStrategy interface {
+	NextDelay(attempt int, baseDelay time.Duration) time.Duration
+}
+
+// ExponentialBackoff doubles the delay on every retry: baseDelay * 2^attempt.
+// It is the default strategy.
+type ExponentialBackoff struct{}
+
+// NextDelay implements BackoffStrategy.
+func (ExponentialBackoff) NextDelay(attempt int, baseDelay time.Duration) time.Duration {
+	delay := baseDelay
+	for i := 0; i < attempt; i++ {
+		if delay > math.MaxInt64/2 {
+			return math.MaxInt64
+		}
+		delay *= 2
+	}
+	return delay
+}
+
+// LinearBackoff grows the delay by baseDelay on every retry: baseDelay * (attempt+1).
+type LinearBackoff struct{}
+
+// NextDelay implements BackoffStrategy.
+func (LinearBackoff) NextDelay(attempt int, baseDelay time.Duration) time.Duration {
+	if baseDelay > 0 && time.Duration(attempt+1) > math.MaxInt64/baseDelay {
+		return math.MaxInt64
+	}
+	return baseDelay * time.Duration(attempt+1)
+}
+
+// ConstantBackoff waits baseDelay before every retry.
+type ConstantBackoff struct{}
+
+// NextDelay implements BackoffStrategy.
+func (ConstantBackoff) NextDelay(attempt int, baseDelay time.Duration) time.Duration {
+	return baseDelay
+}
+
+// MaxBackoff caps the delays of another strategy at max, e.g.
+// MaxBackoff(ExponentialBackoff{}, 30*time.Second).
+func MaxBackoff(strategy BackoffStrategy, max time.Duration) BackoffStrategy {
+	return maxBackoff{strategy: strategy, max: max}
+}
+
+type maxBackoff struct {
+	strategy BackoffStrategy
+	max      time.Duration
+}
+
+// NextDelay implements BackoffStrategy.
+func (b maxBackoff) NextDelay(attempt int, baseDelay time.Duration) time.Duration {
+	delay := b.strategy.NextDelay(attempt, baseDelay)
+	if delay > b.max {
+		return b.max
+	}
+	return delay
+}
+
+// backoffDelay returns the delay before a retry from the configured strategy,
+// clamped to MaxRetryDelay so late attempts don't sleep for minutes. Jitter is
+// not included. A delay set with WithRetryOverride on ctx replaces
+// Config.RetryDelay as the base delay.
+func (s *RallyClient) backoffDelay(ctx context.Context, attempt int) time.Duration {
+	retryDelay := DefaultRetryDelay
+	maxRetryDelay := DefaultMaxRetryDelay
+	var backoff BackoffStrategy = ExponentialBackoff{}
+	if s.config != nil {
+		retryDelay = s.config.RetryDelay
+		if s.config.MaxRetryDelay > 0 {
+			maxRetryDelay = s.config.MaxRetryDelay
+		}
+		if s.config.BackoffStrategy != nil {
+			backoff = s.config.BackoffStrategy
+		}
+	}
+
+	baseDelay := time.Duration(retryDelay) * time.Millisecond
+	if override, ok := retryOverrideFromContext(ctx); ok && override.delay >= 0 {
+		baseDelay = override.delay
+	}
+
+	delay := backoff.NextDelay(attempt, baseDelay)
+	if max := time.Duration(maxRetryDelay) * time.Millisecond; delay > max {
+		delay = max
+	}
+	return delay
+}
+
+// JitterMode controls how randomness is applied to a retry delay to prevent
+// thundering herd.
+type JitterMode int
+
+const (
+	// JitterAdditive adds a random 0-50% on top of the delay. It is the default.
+	JitterAdditive JitterMode = iota
+	// JitterNone waits exactly the backoff delay, for reproducible timings.
+	JitterNone
+	// JitterEqual waits half the delay plus a random value up to the other half.
+	JitterEqual
+	// JitterFull waits a random value between zero and the delay ("full
+	// jitter", as recommended by AWS).
+	JitterFull
+)
+
+// apply returns delay with jitter applied according to the mode.
+func (m JitterMode) apply(delay time.Duration) time.Duration {
+	switch m {
+	case JitterNone:
+		return delay
+	case JitterEqual:
+		half := delay / 2
+		return half + randDuration(delay-half)
+	case JitterFull:
+		return randDuration(delay)
+	default:
+		return delay + randDuration(delay/2)
+	}
+}
+
+// randDuration returns a random duration in [0, n), or 0 if n is not positive.
+func randDuration(n time.Duration) time.Duration {
+	if n <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(n)))
+}
+
+// retryDelay returns the backoff delay with jitter applied per the configured
+// JitterMode.
+func (s *RallyClient) retryDelay(ctx context.Context, attempt int) time.Duration {
+	var mode JitterMode
+	if s.config != nil {
+		mode = s.config.JitterMode
+	}
+	return mode.apply(s.backoffDelay(ctx, attempt))
+}
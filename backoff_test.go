@@ -0,0 +1,218 @@
+/**
+* Copyright 2014 Comcast Cable Communications Management, LLC
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+* http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package rallyresttoolkit_test
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/aleksofficial/go-rally-rest-toolkit"
+	"github.com/aleksofficial/go-rally-rest-toolkit/fakes"
+)
+
+func TestBackoffStrategies(t *testing.T) {
+	base := 100 * time.Millisecond
+	tests := []struct {
+		name     string
+		strategy BackoffStrategy
+		expected []time.Duration
+	}{
+		{"exponential", ExponentialBackoff{}, []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond, 800 * time.Millisecond}},
+		{"linear", LinearBackoff{}, []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 300 * time.Millisecond, 400 * time.Millisecond}},
+		{"constant", ConstantBackoff{}, []time.Duration{100 * time.Millisecond, 100 * time.Millisecond, 100 * time.Millisecond, 100 * time.Millisecond}},
+		{"capped exponential", MaxBackoff(ExponentialBackoff{}, 250*time.Millisecond), []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 250 * time.Millisecond, 250 * time.Millisecond}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for attempt, want := range tt.expected {
+				if got := tt.strategy.NextDelay(attempt, base); got != want {
+					t.Errorf("attempt %d: expected %v, got %v", attempt, want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestBackoffStrategies_NoOverflow(t *testing.T) {
+	for _, strategy := range []BackoffStrategy{ExponentialBackoff{}, LinearBackoff{}} {
+		if got := strategy.NextDelay(math.MaxInt32, time.Second); got <= 0 {
+			t.Errorf("%T: expected a positive delay for a huge attempt count, got %v", strategy, got)
+		}
+	}
+}
+
+// recordingBackoff records the attempts it is asked about and never waits.
+type recordingBackoff struct {
+	attempts []int
+	bases    []time.Duration
+}
+
+func (b *recordingBackoff) NextDelay(attempt int, baseDelay time.Duration) time.Duration {
+	b.attempts = append(b.attempts, attempt)
+	b.bases = append(b.bases, baseDelay)
+	return 0
+}
+
+func TestNewClient_WithBackoff(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{
+		FakeResponses: []*http.Response{
+			errorResponse(http.StatusBadGateway),
+			errorResponse(http.StatusBadGateway),
+			okResponse(),
+		},
+	}
+	backoff := &recordingBackoff{}
+
+	rallyClient, err := NewClient(
+		WithHTTPClient(fakeClient),
+		WithRetries(3, 5*time.Second),
+		WithBackoff(backoff),
+	)
+	if err != nil {
+		t.Fatalf("NewClient failed unexpectedly: %v", err)
+	}
+
+	fakeOutput := new(fakes.FakeOutput)
+	if err := rallyClient.QueryRequest(context.Background(), map[string]string{}, "defect", &fakeOutput); err != nil {
+		t.Fatalf("QueryRequest should have succeeded after retries: %v", err)
+	}
+	if len(backoff.attempts) != 2 || backoff.attempts[0] != 0 || backoff.attempts[1] != 1 {
+		t.Errorf("expected the strategy to be asked for attempts [0 1], got %v", backoff.attempts)
+	}
+	for _, base := range backoff.bases {
+		if base != 5*time.Second {
+			t.Errorf("expected base delay 5s, got %v", base)
+		}
+	}
+}
+
+func TestNewClient_WithBackoffNil(t *testing.T) {
+	if _, err := NewClient(WithBackoff(nil)); err == nil {
+		t.Error("expected an error for a nil backoff strategy")
+	}
+}
+
+func TestDoWithRetry_MaxRetryDelayCapsBackoff(t *testing.T) {
+	const maxRetries = 12
+	responses := make([]*http.Response, 0, maxRetries+1)
+	for i := 0; i <= maxRetries; i++ {
+		responses = append(responses, errorResponse(http.StatusServiceUnavailable))
+	}
+	fakeClient := &fakes.FakeHTTPClient{FakeResponses: responses}
+	logger := &spyLogger{}
+
+	rallyClient, err := NewClient(WithHTTPClient(fakeClient), WithLogger(logger))
+	if err != nil {
+		t.Fatalf("NewClient failed unexpectedly: %v", err)
+	}
+	rallyClient.SetConfig(&Config{
+		MaxRetries:    maxRetries,
+		RetryDelay:    1,
+		MaxRetryDelay: 4,
+	})
+
+	fakeOutput := new(fakes.FakeOutput)
+	if err := rallyClient.QueryRequest(context.Background(), map[string]string{}, "defect", &fakeOutput); err == nil {
+		t.Fatal("QueryRequest should have failed after max retries")
+	}
+	if len(logger.lines) != maxRetries {
+		t.Fatalf("expected %d retry log lines, got %d", maxRetries, len(logger.lines))
+	}
+
+	// Uncapped, the last retry would wait 2^11 ms; capped it waits at most the
+	// 4ms cap plus 50% jitter.
+	for i, line := range logger.lines {
+		delay := loggedDelay(t, line)
+		if delay > 6*time.Millisecond {
+			t.Errorf("retry %d: delay %v exceeds the cap", i+1, delay)
+		}
+	}
+}
+
+// loggedDelay extracts the delay from a "rally: retrying ... in <delay> (...)" log line.
+func loggedDelay(t *testing.T, line string) time.Duration {
+	t.Helper()
+	_, rest, ok := strings.Cut(line, " in ")
+	if !ok {
+		t.Fatalf("unexpected log line: %s", line)
+	}
+	value, _, _ := strings.Cut(rest, " ")
+	delay, err := time.ParseDuration(value)
+	if err != nil {
+		t.Fatalf("failed to parse delay from %q: %v", line, err)
+	}
+	return delay
+}
+
+func TestNewClient_WithJitter(t *testing.T) {
+	base := 4 * time.Millisecond
+	tests := []struct {
+		name     string
+		mode     JitterMode
+		min, max time.Duration
+	}{
+		{"additive", JitterAdditive, base, base + base/2},
+		{"none", JitterNone, base, base},
+		{"equal", JitterEqual, base / 2, base},
+		{"full", JitterFull, 0, base},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			const maxRetries = 5
+			responses := make([]*http.Response, 0, maxRetries+1)
+			for i := 0; i <= maxRetries; i++ {
+				responses = append(responses, errorResponse(http.StatusServiceUnavailable))
+			}
+			fakeClient := &fakes.FakeHTTPClient{FakeResponses: responses}
+			logger := &spyLogger{}
+
+			rallyClient, err := NewClient(
+				WithHTTPClient(fakeClient),
+				WithLogger(logger),
+				WithRetries(maxRetries, base),
+				WithBackoff(ConstantBackoff{}),
+				WithJitter(tt.mode),
+			)
+			if err != nil {
+				t.Fatalf("NewClient failed unexpectedly: %v", err)
+			}
+
+			fakeOutput := new(fakes.FakeOutput)
+			if err := rallyClient.QueryRequest(context.Background(), map[string]string{}, "defect", &fakeOutput); err == nil {
+				t.Fatal("QueryRequest should have failed after max retries")
+			}
+			for i, line := range logger.lines {
+				delay := loggedDelay(t, line)
+				if delay < tt.min || delay > tt.max {
+					t.Errorf("retry %d: delay %v outside [%v, %v]", i+1, delay, tt.min, tt.max)
+				}
+			}
+		})
+	}
+}
+
+func TestNewClient_WithJitterUnknownMode(t *testing.T) {
+	if _, err := NewClient(WithJitter(JitterMode(42))); err == nil {
+		t.Error("expected an error for an unknown jitter mode")
+	}
+}
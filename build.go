@@ -29,12 +29,7 @@ type Build struct {
 }
 
 // QueryBuildResponse - struct to contain query response
-type QueryBuildResponse struct {
-	QueryResult struct {
-		Results          []models.Build
-		TotalResultCount int
-	}
-}
+type QueryBuildResponse = models.QueryResponse[models.Build]
 
 // GetBuildResponse - Struct to contain response
 type GetBuildResponse struct {
@@ -55,8 +50,16 @@ type buildResult struct {
 }
 
 // OperationResponse - struct to contain response
-type buildOperationResponse struct {
-	OperationalResult buildResult
+type buildOperationResponse = models.OperationResponse[models.Build]
+
+// validateBuildStatus - rejects a Status Rally would refuse. An empty Status is
+// left for Rally to handle so partial updates keep working.
+func validateBuildStatus(status string) error {
+	switch status {
+	case "", models.BuildStatusSuccess, models.BuildStatusFailure, models.BuildStatusIncomplete, models.BuildStatusUnknown:
+		return nil
+	}
+	return &InvalidBuildStatusError{Status: status}
 }
 
 // NewBuild - creates new Build
@@ -73,6 +76,26 @@ func (s *Build) QueryBuild(ctx context.Context, query map[string]string) (des []
 	return qdes.QueryResult.Results, err
 }
 
+// QueryBuildWithMeta - QueryRequest that also returns the envelope's paging metadata and Warnings
+func (s *Build) QueryBuildWithMeta(ctx context.Context, query map[string]string) ([]models.Build, QueryMeta, error) {
+	return Query[models.Build](ctx, s.client, "build", query, QueryOptions{})
+}
+
+// QueryBuildPage - abstraction for QueryRequestWithOptions that keeps the paging metadata
+func (s *Build) QueryBuildPage(ctx context.Context, query map[string]string, opts QueryOptions) (Page[models.Build], error) {
+	return queryPage[models.Build](ctx, s.client, query, "build", opts)
+}
+
+// QueryBuildRaw - abstraction for QueryRequestRaw, for query clauses written by hand
+func (s *Build) QueryBuildRaw(ctx context.Context, rawQuery string, opts QueryOptions) (Page[models.Build], error) {
+	return queryRawPage[models.Build](ctx, s.client, rawQuery, "build", opts)
+}
+
+// QueryBuildWith - abstraction for QueryRequestWith
+func (s *Build) QueryBuildWith(ctx context.Context, b *QueryBuilder) (Page[models.Build], error) {
+	return queryWithPage[models.Build](ctx, s.client, b, "build")
+}
+
 // GetBuild - abstraction for GetRequest
 func (s *Build) GetBuild(ctx context.Context, objectID string) (de models.Build, err error) {
 	gde := new(GetBuildResponse)
@@ -82,8 +105,12 @@ func (s *Build) GetBuild(ctx context.Context, objectID string) (de models.Build,
 
 // CreateBuild - abstraction for CreateRequest
 func (s *Build) CreateBuild(ctx context.Context, build models.Build) (der models.Build, err error) {
-	createRequest := CreateBuildRequest{
-		Build: build,
+	if err = validateBuildStatus(build.Status); err != nil {
+		return der, err
+	}
+	createRequest, err := writeRequest("Build", build)
+	if err != nil {
+		return der, err
 	}
 	ude := new(CreateBuildResponse)
 	err = s.client.CreateRequest(ctx, "build", createRequest, &ude)
@@ -93,8 +120,15 @@ func (s *Build) CreateBuild(ctx context.Context, build models.Build) (der models
 
 // UpdateBuild - abstraction for UpdateRequest
 func (s *Build) UpdateBuild(ctx context.Context, build models.Build) (buildr models.Build, err error) {
+	if err = validateBuildStatus(build.Status); err != nil {
+		return buildr, err
+	}
+	updateRequest, err := writeRequest("Build", build)
+	if err != nil {
+		return buildr, err
+	}
 	ude := new(buildOperationResponse)
-	err = s.client.UpdateRequest(ctx, strconv.Itoa(build.ObjectID), "build", build, &ude)
+	err = s.client.UpdateRequest(ctx, strconv.Itoa(build.ObjectID), "build", updateRequest, &ude)
 	buildr = ude.OperationalResult.Object
 	return buildr, err
 }
@@ -29,12 +29,7 @@ type BuildDefinition struct {
 }
 
 // QueryBuildDefinitionResponse - struct to contain query response
-type QueryBuildDefinitionResponse struct {
-	QueryResult struct {
-		Results          []models.BuildDefinition
-		TotalResultCount int
-	}
-}
+type QueryBuildDefinitionResponse = models.QueryResponse[models.BuildDefinition]
 
 // GetBuildDefinitionResponse - Struct to contain response
 type GetBuildDefinitionResponse struct {
@@ -55,9 +50,7 @@ type buildDefinitionResult struct {
 }
 
 // OperationResponse - struct to contain response
-type buildDefinitionOperationResponse struct {
-	OperationalResult buildDefinitionResult
-}
+type buildDefinitionOperationResponse = models.OperationResponse[models.BuildDefinition]
 
 // NewBuildDefinition - creates new BuildDefinition
 func NewBuildDefinition(client *RallyClient) (de *BuildDefinition) {
@@ -73,6 +66,26 @@ func (s *BuildDefinition) QueryBuildDefinition(ctx context.Context, query map[st
 	return qdes.QueryResult.Results, err
 }
 
+// QueryBuildDefinitionWithMeta - QueryRequest that also returns the envelope's paging metadata and Warnings
+func (s *BuildDefinition) QueryBuildDefinitionWithMeta(ctx context.Context, query map[string]string) ([]models.BuildDefinition, QueryMeta, error) {
+	return Query[models.BuildDefinition](ctx, s.client, "buildDefinition", query, QueryOptions{})
+}
+
+// QueryBuildDefinitionPage - abstraction for QueryRequestWithOptions that keeps the paging metadata
+func (s *BuildDefinition) QueryBuildDefinitionPage(ctx context.Context, query map[string]string, opts QueryOptions) (Page[models.BuildDefinition], error) {
+	return queryPage[models.BuildDefinition](ctx, s.client, query, "buildDefinition", opts)
+}
+
+// QueryBuildDefinitionRaw - abstraction for QueryRequestRaw, for query clauses written by hand
+func (s *BuildDefinition) QueryBuildDefinitionRaw(ctx context.Context, rawQuery string, opts QueryOptions) (Page[models.BuildDefinition], error) {
+	return queryRawPage[models.BuildDefinition](ctx, s.client, rawQuery, "buildDefinition", opts)
+}
+
+// QueryBuildDefinitionWith - abstraction for QueryRequestWith
+func (s *BuildDefinition) QueryBuildDefinitionWith(ctx context.Context, b *QueryBuilder) (Page[models.BuildDefinition], error) {
+	return queryWithPage[models.BuildDefinition](ctx, s.client, b, "buildDefinition")
+}
+
 // GetBuildDefinition - abstraction for GetRequest
 func (s *BuildDefinition) GetBuildDefinition(ctx context.Context, objectID string) (de models.BuildDefinition, err error) {
 	gde := new(GetBuildDefinitionResponse)
@@ -82,8 +95,9 @@ func (s *BuildDefinition) GetBuildDefinition(ctx context.Context, objectID strin
 
 // CreateBuildDefinition - abstraction for CreateRequest
 func (s *BuildDefinition) CreateBuildDefinition(ctx context.Context, buildDefinition models.BuildDefinition) (der models.BuildDefinition, err error) {
-	createRequest := CreateBuildDefinitionRequest{
-		BuildDefinition: buildDefinition,
+	createRequest, err := writeRequest("BuildDefinition", buildDefinition)
+	if err != nil {
+		return der, err
 	}
 	ude := new(CreateBuildDefinitionResponse)
 	err = s.client.CreateRequest(ctx, "buildDefinition", createRequest, &ude)
@@ -93,8 +107,12 @@ func (s *BuildDefinition) CreateBuildDefinition(ctx context.Context, buildDefini
 
 // UpdateBuildDefinition - abstraction for UpdateRequest
 func (s *BuildDefinition) UpdateBuildDefinition(ctx context.Context, buildDefinition models.BuildDefinition) (buildDefinitionr models.BuildDefinition, err error) {
+	updateRequest, err := writeRequest("BuildDefinition", buildDefinition)
+	if err != nil {
+		return buildDefinitionr, err
+	}
 	ude := new(buildDefinitionOperationResponse)
-	err = s.client.UpdateRequest(ctx, strconv.Itoa(buildDefinition.ObjectID), "buildDefinition", buildDefinition, &ude)
+	err = s.client.UpdateRequest(ctx, strconv.Itoa(buildDefinition.ObjectID), "buildDefinition", updateRequest, &ude)
 	buildDefinitionr = ude.OperationalResult.Object
 	return buildDefinitionr, err
 }
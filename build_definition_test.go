@@ -125,8 +125,8 @@ func TestUpdateBuildDefinition_ValidRequest(t *testing.T) {
 	ctx := context.Background()
 
 	updateBuildDef := models.BuildDefinition{
-		Name:     ctrlName,
-		ObjectID: 50137325678,
+		Name:              ctrlName,
+		PersistableObject: models.PersistableObject{ObjectID: 50137325678},
 	}
 	result, err := buildDefClient.UpdateBuildDefinition(ctx, updateBuildDef)
 	if err != nil {
@@ -157,3 +157,27 @@ func TestDeleteBuildDefinition_ValidObjectID(t *testing.T) {
 		t.Fatalf("DeleteBuildDefinition failed unexpectedly: %v", err)
 	}
 }
+
+func TestUpdateBuildDefinition_OperationResultEnvelope(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{
+		FakeResponse: &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       &fakes.FakeResponseBody{Reader: bytes.NewBufferString(`{"OperationResult": {"Object": {"Name": "UpdatedName", "ObjectID": 50137325678}, "Errors": [], "Warnings": []}}`)},
+		},
+	}
+
+	rallyClient := New("abcdef", "http://myRallyUrl", fakeClient)
+	buildDefinitionClient := NewBuildDefinition(rallyClient)
+
+	update := models.BuildDefinition{
+		Name:              "UpdatedName",
+		PersistableObject: models.PersistableObject{ObjectID: 50137325678},
+	}
+	result, err := buildDefinitionClient.UpdateBuildDefinition(context.Background(), update)
+	if err != nil {
+		t.Fatalf("UpdateBuildDefinition failed unexpectedly: %v", err)
+	}
+	if result.Name != "UpdatedName" || result.ObjectID != 50137325678 {
+		t.Errorf("expected the updated object from an OperationResult envelope, got %+v", result)
+	}
+}
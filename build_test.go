@@ -19,6 +19,8 @@ package rallyresttoolkit_test
 import (
 	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
 	"net/http"
 	"testing"
 
@@ -125,8 +127,8 @@ func TestUpdateBuild_ValidRequest(t *testing.T) {
 	ctx := context.Background()
 
 	updateBuild := models.Build{
-		Message:  ctrlName,
-		ObjectID: 50137325678,
+		Message:           ctrlName,
+		PersistableObject: models.PersistableObject{ObjectID: 50137325678},
 	}
 	result, err := buildClient.UpdateBuild(ctx, updateBuild)
 	if err != nil {
@@ -157,3 +159,90 @@ func TestDeleteBuild_ValidObjectID(t *testing.T) {
 		t.Fatalf("DeleteBuild failed unexpectedly: %v", err)
 	}
 }
+
+func TestCreateBuild_CITelemetryFields(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{
+		FakeResponse: &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       &fakes.FakeResponseBody{Reader: bytes.NewBufferString(`{"CreateResult": {"Object": {"ObjectID": 50137325678, "Number": "1.4.0-rc.2", "Status": "SUCCESS", "Duration": 312.75, "Changesets": {"_ref": "https://rally1.rallydev.com/slm/webservice/v2.0/Build/50137325678/Changesets", "Count": 2}}}}`)},
+		},
+	}
+
+	rallyClient := New("abcdef", "http://myRallyUrl", fakeClient)
+	buildClient := NewBuild(rallyClient)
+
+	newBuild := models.Build{
+		BuildDefinition: &models.Reference{Ref: "/builddefinition/1234"},
+		Number:          "1.4.0-rc.2",
+		Status:          models.BuildStatusSuccess,
+		Start:           "2016-01-21T21:47:08.551Z",
+		Duration:        312.75,
+		Message:         "concourse: pipeline main, job unit",
+		Uri:             "https://ci.example.com/builds/4242",
+		Changesets:      models.NewCollection("/changeset/1", "/changeset/2"),
+	}
+	result, err := buildClient.CreateBuild(context.Background(), newBuild)
+	if err != nil {
+		t.Fatalf("CreateBuild failed unexpectedly: %v", err)
+	}
+	if result.Number != "1.4.0-rc.2" || result.Duration != 312.75 {
+		t.Errorf("unexpected Number/Duration: %q/%v", result.Number, result.Duration)
+	}
+	if result.Changesets == nil || result.Changesets.Count != 2 {
+		t.Errorf("expected Changesets summary with Count=2, got %+v", result.Changesets)
+	}
+
+	var body struct {
+		Build map[string]json.RawMessage
+	}
+	if err := json.NewDecoder(fakeClient.SpyRequest.Body).Decode(&body); err != nil {
+		t.Fatalf("failed to decode request body: %v", err)
+	}
+	for _, name := range []string{"BuildDefinition", "Number", "Status", "Start", "Duration", "Message", "Uri", "Changesets"} {
+		if _, ok := body.Build[name]; !ok {
+			t.Errorf("expected %s to be sent", name)
+		}
+	}
+	if got := string(body.Build["Changesets"]); got != `[{"_ref":"/changeset/1"},{"_ref":"/changeset/2"}]` {
+		t.Errorf("unexpected Changesets: %s", got)
+	}
+}
+
+func TestCreateBuild_InvalidStatus(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{}
+
+	rallyClient := New("abcdef", "http://myRallyUrl", fakeClient)
+	buildClient := NewBuild(rallyClient)
+
+	_, err := buildClient.CreateBuild(context.Background(), models.Build{Number: "17", Status: "success"})
+	var statusErr *InvalidBuildStatusError
+	if !errors.As(err, &statusErr) {
+		t.Fatalf("expected InvalidBuildStatusError, got %v", err)
+	}
+	if statusErr.Status != "success" {
+		t.Errorf("expected Status=success, got %q", statusErr.Status)
+	}
+	if fakeClient.CallCount != 0 {
+		t.Errorf("expected no request to be sent, got %d", fakeClient.CallCount)
+	}
+}
+
+func TestUpdateBuild_InvalidStatus(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{}
+
+	rallyClient := New("abcdef", "http://myRallyUrl", fakeClient)
+	buildClient := NewBuild(rallyClient)
+
+	update := models.Build{
+		PersistableObject: models.PersistableObject{ObjectID: 50137325678},
+		Status:            "PASSED",
+	}
+	_, err := buildClient.UpdateBuild(context.Background(), update)
+	var statusErr *InvalidBuildStatusError
+	if !errors.As(err, &statusErr) {
+		t.Fatalf("expected InvalidBuildStatusError, got %v", err)
+	}
+	if fakeClient.CallCount != 0 {
+		t.Errorf("expected no request to be sent, got %d", fakeClient.CallCount)
+	}
+}
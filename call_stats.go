@@ -0,0 +1,65 @@
+/**
+* Copyright 2014 Comcast Cable Communications Management, LLC
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+* http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package rallyresttoolkit
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// CallStats describes how a request went through the retry loop, for metrics
+// such as "requests retried". Attach one to a context with WithCallStats.
+type CallStats struct {
+	// Attempts is how many times the request was sent; more than one means
+	// it was retried
+	Attempts int
+	// TotalDuration is the time from the first attempt to the final
+	// response or error, including waits between retries
+	TotalDuration time.Duration
+	// FinalStatus is the HTTP status of the last response, or 0 when the last
+	// attempt failed without one
+	FinalStatus int
+}
+
+type callStatsKey struct{}
+
+// WithCallStats returns a context that makes requests made with it record
+// their retry metadata into stats. stats is reset at the start of each
+// request, so when one context is shared by several requests it describes the
+// most recent one.
+func WithCallStats(ctx context.Context, stats *CallStats) context.Context {
+	return context.WithValue(ctx, callStatsKey{}, stats)
+}
+
+// callStatsFromContext returns the CallStats attached to ctx, or nil.
+func callStatsFromContext(ctx context.Context) *CallStats {
+	stats, _ := ctx.Value(callStatsKey{}).(*CallStats)
+	return stats
+}
+
+// record notes one attempt and its outcome. It is a no-op on a nil receiver.
+func (c *CallStats) record(resp *http.Response) {
+	if c == nil {
+		return
+	}
+	c.Attempts++
+	c.FinalStatus = 0
+	if resp != nil {
+		c.FinalStatus = resp.StatusCode
+	}
+}
@@ -0,0 +1,79 @@
+/**
+* Copyright 2014 Comcast Cable Communications Management, LLC
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+* http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package rallyresttoolkit_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	. "github.com/aleksofficial/go-rally-rest-toolkit"
+	"github.com/aleksofficial/go-rally-rest-toolkit/fakes"
+)
+
+func TestWithCallStats_RecordsRetries(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{
+		FakeResponses: []*http.Response{
+			{StatusCode: http.StatusServiceUnavailable, Body: &fakes.FakeResponseBody{Reader: bytes.NewBufferString(`{}`)}},
+			{StatusCode: http.StatusBadGateway, Body: &fakes.FakeResponseBody{Reader: bytes.NewBufferString(`{}`)}},
+			okResponse(),
+		},
+	}
+	clock := &fakes.FakeClock{Current: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	rallyClient, err := NewClient(
+		WithHTTPClient(fakeClient),
+		WithRetries(3, time.Second),
+		WithJitter(JitterNone),
+		WithClock(clock),
+	)
+	if err != nil {
+		t.Fatalf("NewClient failed unexpectedly: %v", err)
+	}
+
+	var stats CallStats
+	ctx := WithCallStats(context.Background(), &stats)
+	fakeOutput := new(fakes.FakeOutput)
+	if err := rallyClient.GetRequest(ctx, "12345", "defect", &fakeOutput); err != nil {
+		t.Fatalf("GetRequest failed unexpectedly: %v", err)
+	}
+
+	expected := CallStats{Attempts: 3, TotalDuration: 3 * time.Second, FinalStatus: http.StatusOK}
+	if stats != expected {
+		t.Errorf("expected %+v, got %+v", expected, stats)
+	}
+}
+
+func TestWithCallStats_TransportError(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{FakeError: errors.New("connection reset by peer")}
+	rallyClient, err := NewClient(WithHTTPClient(fakeClient), WithRetries(0, time.Second))
+	if err != nil {
+		t.Fatalf("NewClient failed unexpectedly: %v", err)
+	}
+
+	stats := CallStats{Attempts: 9, FinalStatus: http.StatusOK}
+	ctx := WithCallStats(context.Background(), &stats)
+	fakeOutput := new(fakes.FakeOutput)
+	if err := rallyClient.GetRequest(ctx, "12345", "defect", &fakeOutput); err == nil {
+		t.Fatal("expected GetRequest to fail")
+	}
+	if stats.Attempts != 1 || stats.FinalStatus != 0 {
+		t.Errorf("expected a single attempt with no status, got %+v", stats)
+	}
+}
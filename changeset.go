@@ -29,12 +29,7 @@ type Changeset struct {
 }
 
 // QueryChangesetResponse - struct to contain query response
-type QueryChangesetResponse struct {
-	QueryResult struct {
-		Results          []models.Changeset
-		TotalResultCount int
-	}
-}
+type QueryChangesetResponse = models.QueryResponse[models.Changeset]
 
 // GetChangesetResponse - Struct to contain response
 type GetChangesetResponse struct {
@@ -55,9 +50,7 @@ type changesetResult struct {
 }
 
 // OperationResponse - struct to contain response
-type changesetOperationResponse struct {
-	OperationalResult changesetResult
-}
+type changesetOperationResponse = models.OperationResponse[models.Changeset]
 
 // NewChangeset - creates new Changeset
 func NewChangeset(client *RallyClient) (cs *Changeset) {
@@ -73,6 +66,26 @@ func (s *Changeset) QueryChangeset(ctx context.Context, query map[string]string)
 	return qdes.QueryResult.Results, err
 }
 
+// QueryChangesetWithMeta - QueryRequest that also returns the envelope's paging metadata and Warnings
+func (s *Changeset) QueryChangesetWithMeta(ctx context.Context, query map[string]string) ([]models.Changeset, QueryMeta, error) {
+	return Query[models.Changeset](ctx, s.client, "changeset", query, QueryOptions{})
+}
+
+// QueryChangesetPage - abstraction for QueryRequestWithOptions that keeps the paging metadata
+func (s *Changeset) QueryChangesetPage(ctx context.Context, query map[string]string, opts QueryOptions) (Page[models.Changeset], error) {
+	return queryPage[models.Changeset](ctx, s.client, query, "changeset", opts)
+}
+
+// QueryChangesetRaw - abstraction for QueryRequestRaw, for query clauses written by hand
+func (s *Changeset) QueryChangesetRaw(ctx context.Context, rawQuery string, opts QueryOptions) (Page[models.Changeset], error) {
+	return queryRawPage[models.Changeset](ctx, s.client, rawQuery, "changeset", opts)
+}
+
+// QueryChangesetWith - abstraction for QueryRequestWith
+func (s *Changeset) QueryChangesetWith(ctx context.Context, b *QueryBuilder) (Page[models.Changeset], error) {
+	return queryWithPage[models.Changeset](ctx, s.client, b, "changeset")
+}
+
 // GetChangeset - abstraction for GetRequest
 func (s *Changeset) GetChangeset(ctx context.Context, objectID string) (de models.Changeset, err error) {
 	gde := new(GetChangesetResponse)
@@ -82,8 +95,9 @@ func (s *Changeset) GetChangeset(ctx context.Context, objectID string) (de model
 
 // CreateChangeset - abstraction for CreateRequest
 func (s *Changeset) CreateChangeset(ctx context.Context, changeset models.Changeset) (der models.Changeset, err error) {
-	createRequest := CreateChangesetRequest{
-		Changeset: changeset,
+	createRequest, err := writeRequest("Changeset", changeset)
+	if err != nil {
+		return der, err
 	}
 	ude := new(CreateChangesetResponse)
 	err = s.client.CreateRequest(ctx, "changeset", createRequest, &ude)
@@ -93,8 +107,12 @@ func (s *Changeset) CreateChangeset(ctx context.Context, changeset models.Change
 
 // UpdateChangeset - abstraction for UpdateRequest
 func (s *Changeset) UpdateChangeset(ctx context.Context, changeset models.Changeset) (changesetr models.Changeset, err error) {
+	updateRequest, err := writeRequest("Changeset", changeset)
+	if err != nil {
+		return changesetr, err
+	}
 	ude := new(changesetOperationResponse)
-	err = s.client.UpdateRequest(ctx, strconv.Itoa(changeset.ObjectID), "changeset", changeset, &ude)
+	err = s.client.UpdateRequest(ctx, strconv.Itoa(changeset.ObjectID), "changeset", updateRequest, &ude)
 	changesetr = ude.OperationalResult.Object
 	return changesetr, err
 }
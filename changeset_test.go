@@ -125,8 +125,8 @@ func TestUpdateChangeset_ValidRequest(t *testing.T) {
 	ctx := context.Background()
 
 	updateChangeset := models.Changeset{
-		Name:     ctrlName,
-		ObjectID: 50137325678,
+		Name:              ctrlName,
+		PersistableObject: models.PersistableObject{ObjectID: 50137325678},
 	}
 	result, err := changesetClient.UpdateChangeset(ctx, updateChangeset)
 	if err != nil {
@@ -157,3 +157,27 @@ func TestDeleteChangeset_ValidObjectID(t *testing.T) {
 		t.Fatalf("DeleteChangeset failed unexpectedly: %v", err)
 	}
 }
+
+func TestUpdateChangeset_OperationResultEnvelope(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{
+		FakeResponse: &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       &fakes.FakeResponseBody{Reader: bytes.NewBufferString(`{"OperationResult": {"Object": {"Message": "UpdatedMessage", "ObjectID": 50137325678}, "Errors": [], "Warnings": []}}`)},
+		},
+	}
+
+	rallyClient := New("abcdef", "http://myRallyUrl", fakeClient)
+	changesetClient := NewChangeset(rallyClient)
+
+	update := models.Changeset{
+		Message:           "UpdatedMessage",
+		PersistableObject: models.PersistableObject{ObjectID: 50137325678},
+	}
+	result, err := changesetClient.UpdateChangeset(context.Background(), update)
+	if err != nil {
+		t.Fatalf("UpdateChangeset failed unexpectedly: %v", err)
+	}
+	if result.Message != "UpdatedMessage" || result.ObjectID != 50137325678 {
+		t.Errorf("expected the updated object from an OperationResult envelope, got %+v", result)
+	}
+}
@@ -0,0 +1,53 @@
+/**
+* Copyright 2014 Comcast Cable Communications Management, LLC
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+* http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package rallyresttoolkit
+
+import "errors"
+
+// ErrClientClosed is returned by requests made on a RallyClient after Close.
+var ErrClientClosed = errors.New("rally client closed")
+
+// idleConnectionCloser is implemented by *http.Client and *http.Transport.
+type idleConnectionCloser interface {
+	CloseIdleConnections()
+}
+
+// Close releases the client's resources for services that create and dispose
+// of clients: requests waiting to retry give up, idle connections of the
+// underlying HTTP client are closed, and every later request, including the
+// next page fetched by QueryStream or QueryAllConcurrent, fails with
+// ErrClientClosed. Requests already in flight run to completion. Close is safe
+// to call more than once and always returns nil.
+func (s *RallyClient) Close() error {
+	s.closeOnce.Do(func() {
+		close(s.closed)
+		if c, ok := s.client.(idleConnectionCloser); ok {
+			c.CloseIdleConnections()
+		}
+	})
+	return nil
+}
+
+// isClosed reports whether Close has been called.
+func (s *RallyClient) isClosed() bool {
+	select {
+	case <-s.closed:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,92 @@
+/**
+* Copyright 2014 Comcast Cable Communications Management, LLC
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+* http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package rallyresttoolkit_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	. "github.com/aleksofficial/go-rally-rest-toolkit"
+	"github.com/aleksofficial/go-rally-rest-toolkit/fakes"
+)
+
+// idleDoer counts CloseIdleConnections calls.
+type idleDoer struct {
+	fakes.FakeHTTPClient
+	idleCloses int
+}
+
+func (d *idleDoer) CloseIdleConnections() {
+	d.idleCloses++
+}
+
+func TestClose_RejectsLaterRequests(t *testing.T) {
+	doer := &idleDoer{FakeHTTPClient: fakes.FakeHTTPClient{FakeResponse: okResponse()}}
+	rallyClient := New("abcdef", "http://myRallyUrl", doer)
+
+	if err := rallyClient.Close(); err != nil {
+		t.Fatalf("Close failed unexpectedly: %v", err)
+	}
+	if err := rallyClient.Close(); err != nil {
+		t.Fatalf("second Close failed unexpectedly: %v", err)
+	}
+	if doer.idleCloses != 1 {
+		t.Errorf("expected idle connections to be closed once, got %d", doer.idleCloses)
+	}
+
+	fakeOutput := new(fakes.FakeOutput)
+	err := rallyClient.GetRequest(context.Background(), "12345", "defect", &fakeOutput)
+	if !errors.Is(err, ErrClientClosed) {
+		t.Fatalf("expected ErrClientClosed, got %v", err)
+	}
+	if doer.CallCount != 0 {
+		t.Errorf("expected no request to be sent, got %d", doer.CallCount)
+	}
+}
+
+// closingDoer closes the client after answering with a retryable status.
+type closingDoer struct {
+	client *RallyClient
+	calls  int
+}
+
+func (d *closingDoer) Do(req *http.Request) (*http.Response, error) {
+	d.calls++
+	d.client.Close()
+	return errorResponse(http.StatusServiceUnavailable), nil
+}
+
+func TestClose_StopsRetryWait(t *testing.T) {
+	doer := &closingDoer{}
+	rallyClient, err := NewClient(WithHTTPClient(doer), WithRetries(3, time.Hour))
+	if err != nil {
+		t.Fatalf("NewClient failed unexpectedly: %v", err)
+	}
+	doer.client = rallyClient
+
+	fakeOutput := new(fakes.FakeOutput)
+	err = rallyClient.GetRequest(context.Background(), "12345", "defect", &fakeOutput)
+	if !errors.Is(err, ErrClientClosed) {
+		t.Fatalf("expected ErrClientClosed, got %v", err)
+	}
+	if doer.calls != 1 {
+		t.Errorf("expected a single attempt, got %d", doer.calls)
+	}
+}
@@ -0,0 +1,184 @@
+/**
+* Copyright 2014 Comcast Cable Communications Management, LLC
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+* http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package rallyresttoolkit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+
+	"github.com/aleksofficial/go-rally-rest-toolkit/models"
+)
+
+// collectionPageSize is the page size GetCollection requests.
+const collectionPageSize = 200
+
+// collectionRequest is the body Rally expects when adding to or removing from
+// a collection.
+type collectionRequest struct {
+	CollectionItems []models.Reference
+}
+
+// GetCollectionRequest - fetches the members of a collection of an object, e.g.
+// the Milestones of a story. Rally answers with a QueryResult envelope.
+func (s *RallyClient) GetCollectionRequest(ctx context.Context, queryType string, objectID string, collection string, output interface{}) error {
+	baseURL, err := s.endpoint(queryType, objectID, collection)
+	if err != nil {
+		return err
+	}
+
+	params := url.Values{}
+	params.Add("fetch", "true")
+	baseURL.RawQuery = params.Encode()
+
+	return s.execute(ctx, "GET", baseURL, nil, output)
+}
+
+// GetCollectionCount - returns how many members the collection a ref points
+// at has, e.g. the Tasks ref of a story, without fetching them. Only a single
+// member is requested; the count is read from TotalResultCount.
+func (s *RallyClient) GetCollectionCount(ctx context.Context, collectionRef string) (int, error) {
+	baseURL, err := s.collectionEndpoint(collectionRef)
+	if err != nil {
+		return 0, err
+	}
+
+	params := url.Values{}
+	params.Add("fetch", "ObjectID")
+	params.Add("pagesize", "1")
+	baseURL.RawQuery = params.Encode()
+
+	var resp models.QueryResponse[json.RawMessage]
+	if err := s.execute(ctx, "GET", baseURL, nil, &resp); err != nil {
+		return 0, err
+	}
+	return resp.QueryResult.TotalResultCount, nil
+}
+
+// collectionEndpoint returns the URL of the collection a ref such as
+// ".../hierarchicalrequirement/123/Tasks" points at, on the client's base URL.
+func (s *RallyClient) collectionEndpoint(collectionRef string) (*url.URL, error) {
+	segments, err := refSegments(collectionRef)
+	if err != nil {
+		return nil, err
+	}
+	if len(segments) < 3 || segments[0] == "" || segments[len(segments)-2] == "" || segments[len(segments)-1] == "" {
+		return nil, fmt.Errorf("%w %q: expected <type>/<id>/<collection>", ErrInvalidRef, collectionRef)
+	}
+	return s.endpoint(segments...)
+}
+
+// GetCollection fetches every member of the collection a ref points at into
+// typed models, e.g. GetCollection[models.Defect](ctx, client, story.Defects.Ref)
+// for the defects of a story, following the paging metadata.
+func GetCollection[T any](ctx context.Context, client *RallyClient, collectionRef string) ([]T, error) {
+	baseURL, err := client.collectionEndpoint(collectionRef)
+	if err != nil {
+		return nil, err
+	}
+
+	var all []T
+	for start := 1; ; {
+		params := url.Values{}
+		params.Add("fetch", "true")
+		params.Add("start", strconv.Itoa(start))
+		params.Add("pagesize", strconv.Itoa(collectionPageSize))
+		baseURL.RawQuery = params.Encode()
+
+		var resp models.QueryResponse[T]
+		if err := client.execute(ctx, "GET", baseURL, nil, &resp); err != nil {
+			return all, err
+		}
+		page := Page[T]{resp.QueryResult}
+		all = append(all, page.Results...)
+		if !page.HasMore() {
+			return all, nil
+		}
+		start = page.NextStart()
+	}
+}
+
+// AddToCollection - adds objects by ref to a collection of an object, e.g. the
+// Milestones of a story, leaving existing members in place. output may be nil.
+func (s *RallyClient) AddToCollection(ctx context.Context, queryType string, objectID string, collection string, refs []string, output interface{}) error {
+	return s.modifyCollection(ctx, queryType, objectID, collection, "add", refs, output)
+}
+
+// RemoveFromCollection - removes objects by ref from a collection of an object.
+// output may be nil.
+func (s *RallyClient) RemoveFromCollection(ctx context.Context, queryType string, objectID string, collection string, refs []string, output interface{}) error {
+	return s.modifyCollection(ctx, queryType, objectID, collection, "remove", refs, output)
+}
+
+// CollectionOp adds and removes members, by ref, of one collection of an
+// object, e.g. {Collection: "Tags", Add: []string{"/tag/42"}}.
+type CollectionOp struct {
+	// Collection is the collection field name, e.g. "Tags" or "Milestones"
+	Collection string
+	// Add lists the refs to add
+	Add []string
+	// Remove lists the refs to remove
+	Remove []string
+}
+
+// UpdateRequestWithCollections - UpdateRequest that changes collections
+// incrementally instead of replacing them. fields, if not nil, is sent as an
+// ordinary update into output; then each op's removals and additions are sent
+// to the collection's remove and add endpoints, so members not named are left
+// in place. The requests are not atomic: on error, the update and any earlier
+// ops have been applied.
+func (s *RallyClient) UpdateRequestWithCollections(ctx context.Context, objectID string, queryType string, fields interface{}, collectionOps []CollectionOp, output interface{}) error {
+	if fields != nil {
+		if err := s.UpdateRequest(ctx, objectID, queryType, fields, output); err != nil {
+			return err
+		}
+	}
+
+	for _, op := range collectionOps {
+		if len(op.Remove) > 0 {
+			if err := s.RemoveFromCollection(ctx, queryType, objectID, op.Collection, op.Remove, nil); err != nil {
+				return fmt.Errorf("failed to remove from %s: %w", op.Collection, err)
+			}
+		}
+		if len(op.Add) > 0 {
+			if err := s.AddToCollection(ctx, queryType, objectID, op.Collection, op.Add, nil); err != nil {
+				return fmt.Errorf("failed to add to %s: %w", op.Collection, err)
+			}
+		}
+	}
+	return nil
+}
+
+func (s *RallyClient) modifyCollection(ctx context.Context, queryType string, objectID string, collection string, action string, refs []string, output interface{}) error {
+	baseURL, err := s.endpoint(queryType, objectID, collection, action)
+	if err != nil {
+		return err
+	}
+
+	request := collectionRequest{CollectionItems: make([]models.Reference, 0, len(refs))}
+	for _, ref := range refs {
+		request.CollectionItems = append(request.CollectionItems, models.Reference{Ref: ref})
+	}
+	inputByteArray, err := json.Marshal(request)
+	if err != nil {
+		return fmt.Errorf("failed to marshal request body: %w", err)
+	}
+
+	return s.execute(ctx, "POST", baseURL, inputByteArray, output)
+}
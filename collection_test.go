@@ -0,0 +1,205 @@
+/**
+* Copyright 2014 Comcast Cable Communications Management, LLC
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+* http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package rallyresttoolkit_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	. "github.com/aleksofficial/go-rally-rest-toolkit"
+	"github.com/aleksofficial/go-rally-rest-toolkit/fakes"
+)
+
+func collectionResponse() *http.Response {
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Body:       &fakes.FakeResponseBody{Reader: bytes.NewBufferString(`{"OperationResult": {"Errors": [], "Warnings": [], "Results": [{"_ref": "https://rally1.rallydev.com/slm/webservice/v2.0/milestone/777"}]}}`)},
+	}
+}
+
+func TestAddToCollection_URLAndBody(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{FakeResponse: collectionResponse()}
+	rallyClient := New("abcdef", "http://myRallyUrl", fakeClient)
+
+	refs := []string{"/milestone/777", "/milestone/778"}
+	err := rallyClient.AddToCollection(context.Background(), "hierarchicalrequirement", "123", "Milestones", refs, nil)
+	if err != nil {
+		t.Fatalf("AddToCollection failed unexpectedly: %v", err)
+	}
+
+	if fakeClient.SpyRequest.Method != "POST" {
+		t.Errorf("expected POST, got %s", fakeClient.SpyRequest.Method)
+	}
+	if got := fakeClient.SpyRequest.URL.String(); got != "http://myRallyUrl/hierarchicalrequirement/123/Milestones/add" {
+		t.Errorf("unexpected URL: %s", got)
+	}
+
+	var body struct {
+		CollectionItems []map[string]string
+	}
+	if err := json.NewDecoder(fakeClient.SpyRequest.Body).Decode(&body); err != nil {
+		t.Fatalf("failed to decode request body: %v", err)
+	}
+	if len(body.CollectionItems) != 2 || body.CollectionItems[0]["_ref"] != "/milestone/777" || body.CollectionItems[1]["_ref"] != "/milestone/778" {
+		t.Errorf("unexpected CollectionItems: %v", body.CollectionItems)
+	}
+}
+
+func TestRemoveFromCollection_URL(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{FakeResponse: collectionResponse()}
+	rallyClient := New("abcdef", "http://myRallyUrl", fakeClient)
+
+	output := map[string]interface{}{}
+	err := rallyClient.RemoveFromCollection(context.Background(), "defect", "456", "Milestones", []string{"/milestone/777"}, &output)
+	if err != nil {
+		t.Fatalf("RemoveFromCollection failed unexpectedly: %v", err)
+	}
+	if got := fakeClient.SpyRequest.URL.String(); got != "http://myRallyUrl/defect/456/Milestones/remove" {
+		t.Errorf("unexpected URL: %s", got)
+	}
+	if _, ok := output["OperationResult"]; !ok {
+		t.Errorf("expected response to be unmarshalled into output, got %v", output)
+	}
+}
+
+func TestAddToCollection_RallyError(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{
+		FakeResponse: &http.Response{
+			StatusCode: http.StatusBadRequest,
+			Body:       &fakes.FakeResponseBody{Reader: bytes.NewBufferString(`{"OperationResult": {"Errors": ["Could not read: Milestone 999 does not exist"]}}`)},
+		},
+	}
+	rallyClient := New("abcdef", "http://myRallyUrl", fakeClient)
+
+	err := rallyClient.AddToCollection(context.Background(), "defect", "456", "Milestones", []string{"/milestone/999"}, nil)
+	if err == nil || !strings.Contains(err.Error(), "does not exist") {
+		t.Errorf("expected Rally API error, got %v", err)
+	}
+}
+
+func TestGetCollectionCount(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{
+		FakeResponse: &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       &fakes.FakeResponseBody{Reader: bytes.NewBufferString(`{"QueryResult": {"TotalResultCount": 7, "StartIndex": 1, "PageSize": 1, "Results": [{"ObjectID": 1}]}}`)},
+		},
+	}
+	rallyClient := New("abcdef", "http://myRallyUrl", fakeClient)
+
+	count, err := rallyClient.GetCollectionCount(context.Background(), "https://rally1.rallydev.com/slm/webservice/v2.0/HierarchicalRequirement/123/Tasks")
+	if err != nil {
+		t.Fatalf("GetCollectionCount failed unexpectedly: %v", err)
+	}
+	if count != 7 {
+		t.Errorf("expected count 7, got %d", count)
+	}
+	if got := fakeClient.SpyRequest.URL.String(); got != "http://myRallyUrl/HierarchicalRequirement/123/Tasks?fetch=ObjectID&pagesize=1" {
+		t.Errorf("unexpected URL: %s", got)
+	}
+}
+
+func TestGetCollectionCount_InvalidRef(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{}
+	rallyClient := New("abcdef", "http://myRallyUrl", fakeClient)
+
+	_, err := rallyClient.GetCollectionCount(context.Background(), "/hierarchicalrequirement/123")
+	if !errors.Is(err, ErrInvalidRef) {
+		t.Errorf("expected ErrInvalidRef, got %v", err)
+	}
+	if fakeClient.CallCount != 0 {
+		t.Errorf("expected no request for an invalid ref, got %d", fakeClient.CallCount)
+	}
+}
+
+func TestUpdateRequestWithCollections(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{
+		FakeResponses: []*http.Response{
+			jsonResponse(`{"OperationResult": {"Errors": [], "Object": {"ObjectID": 123, "Name": "Renamed"}}}`),
+			collectionResponse(),
+			collectionResponse(),
+			collectionResponse(),
+		},
+	}
+	var urls []string
+	server := &recordingDoer{next: fakeClient, urls: &urls}
+	rallyClient := New("abcdef", "http://myRallyUrl", server)
+
+	fields := map[string]interface{}{"HierarchicalRequirement": map[string]string{"Name": "Renamed"}}
+	ops := []CollectionOp{
+		{Collection: "Tags", Add: []string{"/tag/42"}, Remove: []string{"/tag/7"}},
+		{Collection: "Milestones", Add: []string{"/milestone/777"}},
+	}
+	output := map[string]interface{}{}
+	if err := rallyClient.UpdateRequestWithCollections(context.Background(), "123", "hierarchicalrequirement", fields, ops, &output); err != nil {
+		t.Fatalf("UpdateRequestWithCollections failed unexpectedly: %v", err)
+	}
+
+	expected := []string{
+		"http://myRallyUrl/hierarchicalrequirement/123",
+		"http://myRallyUrl/hierarchicalrequirement/123/Tags/remove",
+		"http://myRallyUrl/hierarchicalrequirement/123/Tags/add",
+		"http://myRallyUrl/hierarchicalrequirement/123/Milestones/add",
+	}
+	if !reflect.DeepEqual(urls, expected) {
+		t.Errorf("expected requests %v, got %v", expected, urls)
+	}
+	if _, ok := output["OperationResult"]; !ok {
+		t.Errorf("expected the update response in output, got %v", output)
+	}
+}
+
+func TestUpdateRequestWithCollections_StopsOnError(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{
+		FakeResponses: []*http.Response{
+			{
+				StatusCode: http.StatusBadRequest,
+				Body:       &fakes.FakeResponseBody{Reader: bytes.NewBufferString(`{"OperationResult": {"Errors": ["Could not read: Tag 99 does not exist"]}}`)},
+			},
+			collectionResponse(),
+		},
+	}
+	rallyClient := New("abcdef", "http://myRallyUrl", fakeClient)
+
+	ops := []CollectionOp{
+		{Collection: "Tags", Add: []string{"/tag/99"}},
+		{Collection: "Milestones", Add: []string{"/milestone/777"}},
+	}
+	err := rallyClient.UpdateRequestWithCollections(context.Background(), "123", "defect", nil, ops, nil)
+	if err == nil || !strings.Contains(err.Error(), "Tags") || !errors.Is(err, ErrRallyAPI) {
+		t.Errorf("expected a wrapped Rally error naming Tags, got %v", err)
+	}
+	if fakeClient.CallCount != 1 {
+		t.Errorf("expected no requests after the failure, got %d", fakeClient.CallCount)
+	}
+}
+
+// recordingDoer records the URL of every request before passing it on.
+type recordingDoer struct {
+	next ClientDoer
+	urls *[]string
+}
+
+func (d *recordingDoer) Do(req *http.Request) (*http.Response, error) {
+	*d.urls = append(*d.urls, req.URL.String())
+	return d.next.Do(req)
+}
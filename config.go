@@ -17,19 +17,26 @@
 package rallyresttoolkit
 
 import (
+	"crypto/tls"
 	"errors"
+	"fmt"
+	"io"
 	"net/http"
+	"net/url"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 )
 
 // Default configuration values
 const (
-	DefaultBaseURL    = "https://rally1.rallydev.com/slm/webservice/v2.0"
-	DefaultTimeout    = 30
-	DefaultMaxRetries = 3
-	DefaultRetryDelay = 1000
+	DefaultBaseURL       = "https://rally1.rallydev.com/slm/webservice/v2.0"
+	DefaultTimeout       = 30
+	DefaultMaxRetries    = 3
+	DefaultRetryDelay    = 1000
+	DefaultMaxRetryDelay = 30000
+	DefaultMaxRetryAfter = 60000
 )
 
 // Config holds all configuration for the Rally client
@@ -44,12 +51,119 @@ type Config struct {
 	MaxRetries int
 	// RetryDelay is the initial retry delay in milliseconds (optional, defaults to 1000)
 	RetryDelay int
+	// MaxRetryDelay caps the delay computed for a retry, before jitter, in
+	// milliseconds (optional, defaults to 30000)
+	MaxRetryDelay int
+	// MaxRetryAfter caps how long a retry waits when a 429 or 5xx response
+	// asks for a delay with a Retry-After header, in milliseconds (optional,
+	// defaults to 60000)
+	MaxRetryAfter int
+	// BackoffStrategy computes the delay before each retry from RetryDelay
+	// (optional, defaults to ExponentialBackoff)
+	BackoffStrategy BackoffStrategy
+	// JitterMode controls how randomness is applied to retry delays (optional,
+	// defaults to JitterAdditive)
+	JitterMode JitterMode
+	// RetryCreatesOnTransportError allows a create to be retried after a
+	// transport error such as a timeout or connection reset (optional, defaults
+	// to false). The create may have been applied before the response was lost,
+	// so retrying gives at-least-once semantics and can create duplicates; the
+	// default is at-most-once. Creates are always retried on 5xx responses.
+	RetryCreatesOnTransportError bool
+	// RetryBudget caps retries across all requests of the client, or of every
+	// client sharing it (optional, defaults to no cap beyond MaxRetries). Once
+	// it is spent, failing requests return without retrying until it refills.
+	RetryBudget *RetryBudget
+	// RequestsPerSecond limits how fast the client sends requests, counting
+	// every attempt including retries (optional, defaults to 0, unlimited). It
+	// applies through NewClient, NewWithConfig and SetConfig, unless a limiter
+	// is set with WithRateLimit or WithRateLimiter
+	RequestsPerSecond float64
+	// Burst is how many requests may be sent at once before RequestsPerSecond
+	// applies (optional, defaults to 1)
+	Burst int
+	// RetryableErrorMessages lists transient Rally errors, such as
+	// "Concurrency conflict", that are retried like a 5xx whatever the response
+	// status (optional, defaults to none). Each is matched case-insensitively
+	// as a substring of the RallyAPIError's Errors; updates re-read the object
+	// before each such retry and resend with its current VersionId.
+	RetryableErrorMessages []string
+	// MaxIdleConns is the maximum number of idle connections across all hosts
+	// (optional, defaults to the net/http default)
+	MaxIdleConns int
+	// MaxIdleConnsPerHost is the maximum number of idle connections kept per host
+	// (optional, defaults to the net/http default). Bulk jobs against a single
+	// Rally instance usually want this raised.
+	MaxIdleConnsPerHost int
+	// IdleConnTimeout is how long an idle connection stays in the pool, in seconds
+	// (optional, defaults to the net/http default)
+	IdleConnTimeout int
+	// TLSInsecureSkipVerify disables TLS certificate verification. This is UNSAFE:
+	// it exposes the API key to anyone able to intercept traffic. Only use it for
+	// on-prem Rally instances with self-signed certificates on trusted networks.
+	TLSInsecureSkipVerify bool
+	// ProxyURL is the HTTP proxy all Rally traffic is sent through (optional,
+	// defaults to the HTTP_PROXY/HTTPS_PROXY/NO_PROXY environment variables)
+	ProxyURL string
+	// StrictFields validates create and update bodies with ValidateCreate before
+	// sending them, rejecting fields the model for the type does not define
+	// (optional, defaults to false)
+	StrictFields bool
+	// ValidateEntityTypes rejects requests whose queryType is not a known WSAPI
+	// type with ErrUnknownEntityType before they are sent, so a typo fails
+	// clearly instead of as a 404 (optional, defaults to false so custom types
+	// still work)
+	ValidateEntityTypes bool
+	// FetchAfterCreate makes CreateRequest, and the typed Create methods, GET
+	// the new object after a successful create and return it in place of the
+	// object Rally echoed, which can lack computed fields (optional, defaults
+	// to false to avoid the extra round trip)
+	FetchAfterCreate bool
+	// Decoder decodes successful response bodies into the caller's output
+	// (optional, defaults to json.Unmarshal). It can swap in a faster JSON
+	// library or a json.Decoder with custom settings. The body has already been
+	// read in full, so that errors reported in it can be detected first.
+	// QueryAll and the other paging helpers call it once per result.
+	Decoder func(io.Reader, interface{}) error
+	// DisallowUnknownFields makes decoding fail on response fields the output
+	// type does not model, to catch drift between Rally's schema and the models
+	// in CI (optional, defaults to false). It is ignored when Decoder is set.
+	// Types with their own UnmarshalJSON, such as OperationResponse, decode
+	// their contents leniently.
+	DisallowUnknownFields bool
+	// DefaultProject is the ref of the project queries are scoped to when their
+	// QueryOptions do not name one (optional, defaults to the user's default
+	// project)
+	DefaultProject string
+	// DefaultWorkspace is the ref of the workspace queries run in when their
+	// QueryOptions do not name one (optional, defaults to the user's default
+	// workspace)
+	DefaultWorkspace string
+	// Headers are added to every request, e.g. for an API gateway in front of
+	// Rally (optional)
+	Headers map[string]string
+	// DefaultPageSize is the page size of queries whose QueryOptions set none,
+	// from 1 to MaxPageSize, or 0 for unset (optional, defaults to Rally's 20
+	// for single pages and 200 for iterators)
+	DefaultPageSize int
 }
 
 // ErrAPIKeyRequired is returned when RALLY_API_KEY environment variable is not set
 var ErrAPIKeyRequired = errors.New("RALLY_API_KEY environment variable is required")
 
-// LoadConfigFromEnv loads configuration from environment variables
+// ErrInvalidProxyURL is returned when Config.ProxyURL cannot be used as a proxy
+var ErrInvalidProxyURL = errors.New("invalid proxy URL")
+
+// ErrInvalidBaseURL is returned by NewClient when the WSAPI base URL is empty,
+// lacks a scheme or host, or has a query, fragment or empty path segment
+var ErrInvalidBaseURL = errors.New("invalid base URL")
+
+// ErrInvalidConfig is returned by NewWithConfig for a nil config or one with
+// negative timeouts, retries, delays or rate limits
+var ErrInvalidConfig = errors.New("invalid config")
+
+// LoadConfigFromEnv loads configuration from environment variables. An
+// invalid RALLY_PAGE_SIZE fails with ErrInvalidPageSize.
 func LoadConfigFromEnv() (*Config, error) {
 	apiKey := os.Getenv("RALLY_API_KEY")
 	if apiKey == "" {
@@ -57,11 +171,13 @@ func LoadConfigFromEnv() (*Config, error) {
 	}
 
 	config := &Config{
-		APIKey:     apiKey,
-		BaseURL:    DefaultBaseURL,
-		Timeout:    DefaultTimeout,
-		MaxRetries: DefaultMaxRetries,
-		RetryDelay: DefaultRetryDelay,
+		APIKey:        apiKey,
+		BaseURL:       DefaultBaseURL,
+		Timeout:       DefaultTimeout,
+		MaxRetries:    DefaultMaxRetries,
+		RetryDelay:    DefaultRetryDelay,
+		MaxRetryDelay: DefaultMaxRetryDelay,
+		MaxRetryAfter: DefaultMaxRetryAfter,
 	}
 
 	if baseURL := os.Getenv("RALLY_BASE_URL"); baseURL != "" {
@@ -86,9 +202,158 @@ func LoadConfigFromEnv() (*Config, error) {
 		}
 	}
 
+	if maxRetryDelay := os.Getenv("RALLY_MAX_RETRY_DELAY"); maxRetryDelay != "" {
+		if d, err := strconv.Atoi(maxRetryDelay); err == nil && d > 0 {
+			config.MaxRetryDelay = d
+		}
+	}
+
+	if maxRetryAfter := os.Getenv("RALLY_MAX_RETRY_AFTER"); maxRetryAfter != "" {
+		if d, err := strconv.Atoi(maxRetryAfter); err == nil && d > 0 {
+			config.MaxRetryAfter = d
+		}
+	}
+
+	if maxIdle := os.Getenv("RALLY_MAX_IDLE_CONNS"); maxIdle != "" {
+		if n, err := strconv.Atoi(maxIdle); err == nil && n >= 0 {
+			config.MaxIdleConns = n
+		}
+	}
+
+	if maxIdlePerHost := os.Getenv("RALLY_MAX_IDLE_CONNS_PER_HOST"); maxIdlePerHost != "" {
+		if n, err := strconv.Atoi(maxIdlePerHost); err == nil && n >= 0 {
+			config.MaxIdleConnsPerHost = n
+		}
+	}
+
+	if idleTimeout := os.Getenv("RALLY_IDLE_CONN_TIMEOUT"); idleTimeout != "" {
+		if t, err := strconv.Atoi(idleTimeout); err == nil && t >= 0 {
+			config.IdleConnTimeout = t
+		}
+	}
+
+	if insecure := os.Getenv("RALLY_TLS_INSECURE_SKIP_VERIFY"); insecure != "" {
+		if b, err := strconv.ParseBool(insecure); err == nil {
+			config.TLSInsecureSkipVerify = b
+		}
+	}
+
+	if retryCreates := os.Getenv("RALLY_RETRY_CREATES_ON_TRANSPORT_ERROR"); retryCreates != "" {
+		if b, err := strconv.ParseBool(retryCreates); err == nil {
+			config.RetryCreatesOnTransportError = b
+		}
+	}
+
+	if disallow := os.Getenv("RALLY_DISALLOW_UNKNOWN_FIELDS"); disallow != "" {
+		if b, err := strconv.ParseBool(disallow); err == nil {
+			config.DisallowUnknownFields = b
+		}
+	}
+
+	if rps := os.Getenv("RALLY_REQUESTS_PER_SECOND"); rps != "" {
+		if r, err := strconv.ParseFloat(rps, 64); err == nil && r > 0 {
+			config.RequestsPerSecond = r
+		}
+	}
+
+	if burst := os.Getenv("RALLY_BURST"); burst != "" {
+		if b, err := strconv.Atoi(burst); err == nil && b > 0 {
+			config.Burst = b
+		}
+	}
+
+	if pageSize := os.Getenv("RALLY_PAGE_SIZE"); pageSize != "" {
+		n, err := strconv.Atoi(pageSize)
+		if err != nil {
+			return nil, fmt.Errorf("%w: RALLY_PAGE_SIZE=%q is not a number", ErrInvalidPageSize, pageSize)
+		}
+		if err := checkDefaultPageSize(n); err != nil {
+			return nil, err
+		}
+		config.DefaultPageSize = n
+	}
+
+	if proxyURL := os.Getenv("RALLY_PROXY_URL"); proxyURL != "" {
+		config.ProxyURL = proxyURL
+	}
+
 	return config, nil
 }
 
+// parseProxyURL validates a proxy URL, requiring a supported scheme and a host.
+func parseProxyURL(proxyURL string) (*url.URL, error) {
+	u, err := url.Parse(proxyURL)
+	if err != nil {
+		return nil, fmt.Errorf("%w %q: %v", ErrInvalidProxyURL, proxyURL, err)
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5":
+	default:
+		return nil, fmt.Errorf("%w %q: scheme must be http, https or socks5", ErrInvalidProxyURL, proxyURL)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("%w %q: missing host", ErrInvalidProxyURL, proxyURL)
+	}
+	return u, nil
+}
+
+// validateBaseURL checks that apiurl is an absolute URL requests can be built
+// from, so a typo fails once at construction instead of in every request.
+func validateBaseURL(apiurl string) error {
+	if apiurl == "" {
+		return fmt.Errorf("%w: empty", ErrInvalidBaseURL)
+	}
+	u, err := url.Parse(apiurl)
+	if err != nil {
+		return fmt.Errorf("%w %q: %v", ErrInvalidBaseURL, apiurl, err)
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return fmt.Errorf("%w %q: missing scheme or host", ErrInvalidBaseURL, apiurl)
+	}
+	if u.RawQuery != "" || u.Fragment != "" {
+		return fmt.Errorf("%w %q: must not have a query or fragment", ErrInvalidBaseURL, apiurl)
+	}
+	if strings.Contains(u.Path, "//") {
+		return fmt.Errorf("%w %q: empty path segment", ErrInvalidBaseURL, apiurl)
+	}
+	return nil
+}
+
+// newHTTPClient builds an http.Client with a transport tuned from the config.
+// A zero Timeout means DefaultTimeout; other zero values keep the net/http
+// defaults.
+func newHTTPClient(config *Config) (*http.Client, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if config.ProxyURL != "" {
+		proxy, err := parseProxyURL(config.ProxyURL)
+		if err != nil {
+			return nil, err
+		}
+		transport.Proxy = http.ProxyURL(proxy)
+	}
+	if config.MaxIdleConns > 0 {
+		transport.MaxIdleConns = config.MaxIdleConns
+	}
+	if config.MaxIdleConnsPerHost > 0 {
+		transport.MaxIdleConnsPerHost = config.MaxIdleConnsPerHost
+	}
+	if config.IdleConnTimeout > 0 {
+		transport.IdleConnTimeout = time.Duration(config.IdleConnTimeout) * time.Second
+	}
+	if config.TLSInsecureSkipVerify {
+		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+	}
+
+	timeout := config.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	return &http.Client{
+		Timeout:   time.Duration(timeout) * time.Second,
+		Transport: transport,
+	}, nil
+}
+
 // NewClientFromEnv creates a new RallyClient using configuration from environment variables
 func NewClientFromEnv() (*RallyClient, error) {
 	config, err := LoadConfigFromEnv()
@@ -96,12 +361,50 @@ func NewClientFromEnv() (*RallyClient, error) {
 		return nil, err
 	}
 
-	httpClient := &http.Client{
-		Timeout: time.Duration(config.Timeout) * time.Second,
+	return NewWithConfig(config)
+}
+
+// NewWithConfig creates a new RallyClient from a Config built in code, e.g.
+// from a secrets store rather than the environment. The config is validated
+// and copied; unless opts inject one with WithHTTPClient, the client gets an
+// http.Client built from its Timeout and transport settings. opts apply after
+// the config and override it.
+func NewWithConfig(config *Config, opts ...Option) (*RallyClient, error) {
+	if err := validateConfig(config); err != nil {
+		return nil, err
 	}
+	return NewClient(append([]Option{WithConfig(config)}, opts...)...)
+}
 
-	client := New(config.APIKey, config.BaseURL, httpClient)
-	client.config = config
+// validateConfig checks the settings NewWithConfig cannot fall back from.
+// Zero values are left to their defaults.
+func validateConfig(config *Config) error {
+	if config == nil {
+		return fmt.Errorf("%w: nil", ErrInvalidConfig)
+	}
+	if config.Timeout < 0 {
+		return fmt.Errorf("%w: negative timeout %d", ErrInvalidConfig, config.Timeout)
+	}
+	if config.MaxRetries < 0 {
+		return fmt.Errorf("%w: negative max retries %d", ErrInvalidConfig, config.MaxRetries)
+	}
+	if config.RetryDelay < 0 || config.MaxRetryDelay < 0 || config.MaxRetryAfter < 0 {
+		return fmt.Errorf("%w: negative retry delay", ErrInvalidConfig)
+	}
+	if config.RequestsPerSecond < 0 || config.Burst < 0 {
+		return fmt.Errorf("%w: negative rate limit", ErrInvalidConfig)
+	}
+	if config.DefaultPageSize != 0 {
+		return checkDefaultPageSize(config.DefaultPageSize)
+	}
+	return nil
+}
 
-	return client, nil
+// checkDefaultPageSize checks a page size set as the client's default, which
+// must be from 1 to MaxPageSize; 0 is only valid as "unset" in a Config.
+func checkDefaultPageSize(pageSize int) error {
+	if pageSize < 1 || pageSize > MaxPageSize {
+		return fmt.Errorf("%w: %d (must be between 1 and %d)", ErrInvalidPageSize, pageSize, MaxPageSize)
+	}
+	return nil
 }
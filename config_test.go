@@ -0,0 +1,301 @@
+/**
+* Copyright 2014 Comcast Cable Communications Management, LLC
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+* http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package rallyresttoolkit_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/aleksofficial/go-rally-rest-toolkit"
+	"github.com/aleksofficial/go-rally-rest-toolkit/fakes"
+)
+
+func TestNewClientFromEnv_DefaultTransport(t *testing.T) {
+	t.Setenv("RALLY_API_KEY", "abcdef")
+
+	client, err := NewClientFromEnv()
+	if err != nil {
+		t.Fatalf("NewClientFromEnv failed unexpectedly: %v", err)
+	}
+
+	httpClient, ok := client.HTTPClient().(*http.Client)
+	if !ok {
+		t.Fatalf("expected *http.Client, got %T", client.HTTPClient())
+	}
+	if httpClient.Timeout != DefaultTimeout*time.Second {
+		t.Errorf("expected Timeout=%v, got %v", DefaultTimeout*time.Second, httpClient.Timeout)
+	}
+	transport, ok := httpClient.Transport.(*http.Transport)
+	if !ok {
+		t.Fatalf("expected *http.Transport, got %T", httpClient.Transport)
+	}
+	defaults := http.DefaultTransport.(*http.Transport)
+	if transport.MaxIdleConns != defaults.MaxIdleConns {
+		t.Errorf("expected MaxIdleConns=%d, got %d", defaults.MaxIdleConns, transport.MaxIdleConns)
+	}
+	if transport.TLSClientConfig != nil && transport.TLSClientConfig.InsecureSkipVerify {
+		t.Error("expected TLS verification to be enabled by default")
+	}
+}
+
+func TestNewClientFromEnv_TunedTransport(t *testing.T) {
+	t.Setenv("RALLY_API_KEY", "abcdef")
+	t.Setenv("RALLY_MAX_IDLE_CONNS", "200")
+	t.Setenv("RALLY_MAX_IDLE_CONNS_PER_HOST", "50")
+	t.Setenv("RALLY_IDLE_CONN_TIMEOUT", "120")
+	t.Setenv("RALLY_TLS_INSECURE_SKIP_VERIFY", "true")
+
+	config, err := LoadConfigFromEnv()
+	if err != nil {
+		t.Fatalf("LoadConfigFromEnv failed unexpectedly: %v", err)
+	}
+	if config.MaxIdleConns != 200 || config.MaxIdleConnsPerHost != 50 || config.IdleConnTimeout != 120 || !config.TLSInsecureSkipVerify {
+		t.Errorf("unexpected transport config: %+v", config)
+	}
+
+	client, err := NewClientFromEnv()
+	if err != nil {
+		t.Fatalf("NewClientFromEnv failed unexpectedly: %v", err)
+	}
+	transport := client.HTTPClient().(*http.Client).Transport.(*http.Transport)
+	if transport.MaxIdleConns != 200 {
+		t.Errorf("expected MaxIdleConns=200, got %d", transport.MaxIdleConns)
+	}
+	if transport.MaxIdleConnsPerHost != 50 {
+		t.Errorf("expected MaxIdleConnsPerHost=50, got %d", transport.MaxIdleConnsPerHost)
+	}
+	if transport.IdleConnTimeout != 120*time.Second {
+		t.Errorf("expected IdleConnTimeout=120s, got %v", transport.IdleConnTimeout)
+	}
+	if transport.TLSClientConfig == nil || !transport.TLSClientConfig.InsecureSkipVerify {
+		t.Error("expected InsecureSkipVerify to be set")
+	}
+}
+
+func TestNewClientFromEnv_ProxyURL(t *testing.T) {
+	t.Setenv("RALLY_API_KEY", "abcdef")
+	t.Setenv("RALLY_PROXY_URL", "http://proxy.corp.example:3128")
+
+	client, err := NewClientFromEnv()
+	if err != nil {
+		t.Fatalf("NewClientFromEnv failed unexpectedly: %v", err)
+	}
+
+	transport := client.HTTPClient().(*http.Client).Transport.(*http.Transport)
+	req, _ := http.NewRequest("GET", DefaultBaseURL, nil)
+	proxy, err := transport.Proxy(req)
+	if err != nil {
+		t.Fatalf("Proxy func failed unexpectedly: %v", err)
+	}
+	if proxy == nil || proxy.String() != "http://proxy.corp.example:3128" {
+		t.Errorf("expected proxy http://proxy.corp.example:3128, got %v", proxy)
+	}
+}
+
+func TestNewClientFromEnv_ProxyFallsBackToEnvironment(t *testing.T) {
+	t.Setenv("RALLY_API_KEY", "abcdef")
+
+	client, err := NewClientFromEnv()
+	if err != nil {
+		t.Fatalf("NewClientFromEnv failed unexpectedly: %v", err)
+	}
+
+	transport := client.HTTPClient().(*http.Client).Transport.(*http.Transport)
+	if transport.Proxy == nil {
+		t.Error("expected transport to fall back to http.ProxyFromEnvironment")
+	}
+}
+
+func TestNewClientFromEnv_InvalidProxyURL(t *testing.T) {
+	t.Setenv("RALLY_API_KEY", "abcdef")
+
+	for _, proxyURL := range []string{"://missing-scheme", "ftp://proxy.corp.example", "http://"} {
+		t.Setenv("RALLY_PROXY_URL", proxyURL)
+
+		_, err := NewClientFromEnv()
+		if !errors.Is(err, ErrInvalidProxyURL) {
+			t.Errorf("expected ErrInvalidProxyURL for %q, got %v", proxyURL, err)
+		}
+	}
+}
+
+func TestLoadConfigFromEnv_MaxRetryDelay(t *testing.T) {
+	t.Setenv("RALLY_API_KEY", "abcdef")
+
+	config, err := LoadConfigFromEnv()
+	if err != nil {
+		t.Fatalf("LoadConfigFromEnv failed unexpectedly: %v", err)
+	}
+	if config.MaxRetryDelay != DefaultMaxRetryDelay {
+		t.Errorf("expected MaxRetryDelay=%d, got %d", DefaultMaxRetryDelay, config.MaxRetryDelay)
+	}
+
+	t.Setenv("RALLY_MAX_RETRY_DELAY", "5000")
+	config, err = LoadConfigFromEnv()
+	if err != nil {
+		t.Fatalf("LoadConfigFromEnv failed unexpectedly: %v", err)
+	}
+	if config.MaxRetryDelay != 5000 {
+		t.Errorf("expected MaxRetryDelay=5000, got %d", config.MaxRetryDelay)
+	}
+}
+
+func TestLoadConfigFromEnv_DisallowUnknownFields(t *testing.T) {
+	t.Setenv("RALLY_API_KEY", "abcdef")
+
+	config, err := LoadConfigFromEnv()
+	if err != nil {
+		t.Fatalf("LoadConfigFromEnv failed unexpectedly: %v", err)
+	}
+	if config.DisallowUnknownFields {
+		t.Error("expected DisallowUnknownFields to default to false")
+	}
+
+	t.Setenv("RALLY_DISALLOW_UNKNOWN_FIELDS", "true")
+	config, err = LoadConfigFromEnv()
+	if err != nil {
+		t.Fatalf("LoadConfigFromEnv failed unexpectedly: %v", err)
+	}
+	if !config.DisallowUnknownFields {
+		t.Error("expected DisallowUnknownFields=true")
+	}
+}
+
+func TestLoadConfigFromEnv_PageSize(t *testing.T) {
+	t.Setenv("RALLY_API_KEY", "abcdef")
+
+	tests := map[string]int{
+		"":     0,
+		"500":  500,
+		"1":    1,
+		"2000": 2000,
+	}
+	for value, expected := range tests {
+		t.Setenv("RALLY_PAGE_SIZE", value)
+		config, err := LoadConfigFromEnv()
+		if err != nil {
+			t.Fatalf("LoadConfigFromEnv failed unexpectedly: %v", err)
+		}
+		if config.DefaultPageSize != expected {
+			t.Errorf("RALLY_PAGE_SIZE=%q: expected DefaultPageSize=%d, got %d", value, expected, config.DefaultPageSize)
+		}
+	}
+
+	for _, value := range []string{"0", "-5", "2001", "5000", "abc"} {
+		t.Setenv("RALLY_PAGE_SIZE", value)
+		if _, err := LoadConfigFromEnv(); !errors.Is(err, ErrInvalidPageSize) {
+			t.Errorf("RALLY_PAGE_SIZE=%q: expected ErrInvalidPageSize, got %v", value, err)
+		}
+	}
+}
+
+func TestNewWithConfig_BuildsHTTPClient(t *testing.T) {
+	rallyClient, err := NewWithConfig(&Config{APIKey: "abcdef", Timeout: 45, MaxIdleConnsPerHost: 10})
+	if err != nil {
+		t.Fatalf("NewWithConfig failed unexpectedly: %v", err)
+	}
+
+	httpClient, ok := rallyClient.HTTPClient().(*http.Client)
+	if !ok {
+		t.Fatalf("expected *http.Client, got %T", rallyClient.HTTPClient())
+	}
+	if httpClient.Timeout != 45*time.Second {
+		t.Errorf("expected Timeout=45s, got %v", httpClient.Timeout)
+	}
+	if got := httpClient.Transport.(*http.Transport).MaxIdleConnsPerHost; got != 10 {
+		t.Errorf("expected MaxIdleConnsPerHost=10, got %d", got)
+	}
+}
+
+func TestNewWithConfig_InjectedHTTPClient(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{FakeResponse: &http.Response{
+		StatusCode: http.StatusServiceUnavailable,
+		Body:       &fakes.FakeResponseBody{Reader: strings.NewReader(`{}`)},
+	}}
+	config := &Config{APIKey: "abcdef", BaseURL: "http://myRallyUrl", MaxRetries: 1, RetryDelay: 1}
+
+	rallyClient, err := NewWithConfig(config, WithHTTPClient(fakeClient))
+	if err != nil {
+		t.Fatalf("NewWithConfig failed unexpectedly: %v", err)
+	}
+	if rallyClient.HTTPClient() != fakeClient {
+		t.Fatalf("expected the injected client, got %T", rallyClient.HTTPClient())
+	}
+
+	if err := rallyClient.GetRequest(context.Background(), "12345", "defect", nil); err == nil {
+		t.Fatal("expected GetRequest to fail")
+	}
+	if fakeClient.CallCount != 2 {
+		t.Errorf("expected the config's single retry, got %d calls", fakeClient.CallCount)
+	}
+	if got := fakeClient.SpyRequest.URL.String(); !strings.HasPrefix(got, "http://myRallyUrl/defect/12345") {
+		t.Errorf("expected request to http://myRallyUrl/defect/12345, got %s", got)
+	}
+	if got := fakeClient.SpyRequest.Header.Get("ZSESSIONID"); got != "abcdef" {
+		t.Errorf("expected ZSESSIONID=abcdef, got %q", got)
+	}
+}
+
+func TestNewWithConfig_Invalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   *Config
+		expected error
+	}{
+		{"nil config", nil, ErrInvalidConfig},
+		{"negative timeout", &Config{Timeout: -1}, ErrInvalidConfig},
+		{"negative retries", &Config{MaxRetries: -1}, ErrInvalidConfig},
+		{"negative delay", &Config{RetryDelay: -1}, ErrInvalidConfig},
+		{"negative rate limit", &Config{RequestsPerSecond: -1}, ErrInvalidConfig},
+		{"page size", &Config{DefaultPageSize: MaxPageSize + 1}, ErrInvalidPageSize},
+		{"base URL", &Config{BaseURL: "rally1.rallydev.com"}, ErrInvalidBaseURL},
+	}
+
+	for _, tt := range tests {
+		if _, err := NewWithConfig(tt.config); !errors.Is(err, tt.expected) {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.expected, err)
+		}
+	}
+}
+
+func TestLoadConfigFromEnv_RateLimit(t *testing.T) {
+	t.Setenv("RALLY_API_KEY", "abcdef")
+	t.Setenv("RALLY_REQUESTS_PER_SECOND", "2.5")
+	t.Setenv("RALLY_BURST", "4")
+
+	config, err := LoadConfigFromEnv()
+	if err != nil {
+		t.Fatalf("LoadConfigFromEnv failed unexpectedly: %v", err)
+	}
+	if config.RequestsPerSecond != 2.5 || config.Burst != 4 {
+		t.Errorf("expected 2.5 requests/second with bursts of 4, got %v and %d", config.RequestsPerSecond, config.Burst)
+	}
+
+	t.Setenv("RALLY_REQUESTS_PER_SECOND", "-1")
+	t.Setenv("RALLY_BURST", "none")
+	config, err = LoadConfigFromEnv()
+	if err != nil {
+		t.Fatalf("LoadConfigFromEnv failed unexpectedly: %v", err)
+	}
+	if config.RequestsPerSecond != 0 || config.Burst != 0 {
+		t.Errorf("expected invalid values to leave the client unlimited, got %v and %d", config.RequestsPerSecond, config.Burst)
+	}
+}
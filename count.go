@@ -0,0 +1,101 @@
+/**
+* Copyright 2014 Comcast Cable Communications Management, LLC
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+* http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package rallyresttoolkit
+
+import (
+	"context"
+
+	"github.com/aleksofficial/go-rally-rest-toolkit/models"
+)
+
+// Count returns how many objects of queryType match query without fetching
+// them: it asks for a single result carrying only its ObjectID and reads
+// TotalResultCount off the envelope.
+func (s *RallyClient) Count(ctx context.Context, query map[string]string, queryType string) (int, error) {
+	return s.CountWithOptions(ctx, query, queryType, QueryOptions{})
+}
+
+// CountWithOptions - Count scoped by the project options in opts. Ordering,
+// paging and fetch options are ignored.
+func (s *RallyClient) CountWithOptions(ctx context.Context, query map[string]string, queryType string, opts QueryOptions) (int, error) {
+	result, err := s.queryObjectIDs(ctx, query, queryType, opts, 1)
+	if err != nil {
+		return 0, err
+	}
+	return result.TotalResultCount, nil
+}
+
+// queryObjectIDs runs query for the first pageSize matches, fetching only their
+// ObjectIDs, for callers that need the envelope's counts more than the objects.
+func (s *RallyClient) queryObjectIDs(ctx context.Context, query map[string]string, queryType string, opts QueryOptions, pageSize int) (models.QueryResult[models.PersistableObject], error) {
+	var resp models.QueryResponse[models.PersistableObject]
+	opts.Order, opts.OrderBy, opts.Start, opts.PageSize = "", OrderBy{}, 0, pageSize
+	opts.Fetch, opts.FetchMode = []string{"ObjectID"}, FetchFull
+
+	err := s.QueryRequestWithOptions(ctx, query, queryType, opts, &resp)
+	return resp.QueryResult, err
+}
+
+// CountBuilds - abstraction for Count
+func (s *Build) CountBuilds(ctx context.Context, query map[string]string) (int, error) {
+	return s.client.Count(ctx, query, "build")
+}
+
+// CountBuildDefinitions - abstraction for Count
+func (s *BuildDefinition) CountBuildDefinitions(ctx context.Context, query map[string]string) (int, error) {
+	return s.client.Count(ctx, query, "buildDefinition")
+}
+
+// CountChangesets - abstraction for Count
+func (s *Changeset) CountChangesets(ctx context.Context, query map[string]string) (int, error) {
+	return s.client.Count(ctx, query, "changeset")
+}
+
+// CountDefects - abstraction for Count
+func (s *Defect) CountDefects(ctx context.Context, query map[string]string) (int, error) {
+	return s.client.Count(ctx, query, "defect")
+}
+
+// CountHierarchicalRequirements - abstraction for Count
+func (s *HierarchicalRequirement) CountHierarchicalRequirements(ctx context.Context, query map[string]string) (int, error) {
+	return s.client.Count(ctx, query, "HierarchicalRequirement")
+}
+
+// CountIterations - abstraction for Count
+func (s *Iteration) CountIterations(ctx context.Context, query map[string]string) (int, error) {
+	return s.client.Count(ctx, query, "iteration")
+}
+
+// CountPortfolioItems - abstraction for Count
+func (s *PortfolioItem) CountPortfolioItems(ctx context.Context, query map[string]string) (int, error) {
+	return s.client.Count(ctx, query, s.queryType)
+}
+
+// CountReleases - abstraction for Count
+func (s *Release) CountReleases(ctx context.Context, query map[string]string) (int, error) {
+	return s.client.Count(ctx, query, "release")
+}
+
+// CountTasks - abstraction for Count
+func (s *Task) CountTasks(ctx context.Context, query map[string]string) (int, error) {
+	return s.client.Count(ctx, query, "task")
+}
+
+// CountUsers - abstraction for Count
+func (s *User) CountUsers(ctx context.Context, query map[string]string) (int, error) {
+	return s.client.Count(ctx, query, "user")
+}
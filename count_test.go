@@ -0,0 +1,94 @@
+/**
+* Copyright 2014 Comcast Cable Communications Management, LLC
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+* http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package rallyresttoolkit_test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"testing"
+
+	. "github.com/aleksofficial/go-rally-rest-toolkit"
+	"github.com/aleksofficial/go-rally-rest-toolkit/fakes"
+)
+
+func TestCount_MinimalQuery(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{FakeResponse: queryPageResponse(1, 1, 137)}
+	rallyClient := New("abcdef", "http://myRallyUrl", fakeClient)
+
+	count, err := rallyClient.Count(context.Background(), map[string]string{"State": "Open"}, "defect")
+	if err != nil {
+		t.Fatalf("Count failed unexpectedly: %v", err)
+	}
+	if count != 137 {
+		t.Errorf("expected count 137, got %d", count)
+	}
+	expected := "fetch=ObjectID&pagesize=1&query=%28+State+%3D+Open+%29"
+	if got := fakeClient.SpyRequest.URL.RawQuery; got != expected {
+		t.Errorf("expected query %q, got %q", expected, got)
+	}
+}
+
+func TestCountWithOptions_KeepsScopingDropsPaging(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{FakeResponse: queryPageResponse(1, 0, 0)}
+	rallyClient := New("abcdef", "http://myRallyUrl", fakeClient)
+
+	opts := QueryOptions{Project: "/project/12345", Order: "Rank", Start: 41, PageSize: 20}
+	count, err := rallyClient.CountWithOptions(context.Background(), nil, "defect", opts)
+	if err != nil {
+		t.Fatalf("CountWithOptions failed unexpectedly: %v", err)
+	}
+	if count != 0 {
+		t.Errorf("expected count 0, got %d", count)
+	}
+	expected := "fetch=ObjectID&pagesize=1&project=%2Fproject%2F12345"
+	if got := fakeClient.SpyRequest.URL.RawQuery; got != expected {
+		t.Errorf("expected query %q, got %q", expected, got)
+	}
+}
+
+func TestCountWithOptions_IgnoresFetchMode(t *testing.T) {
+	for _, mode := range []FetchMode{FetchFields, FetchNone} {
+		fakeClient := &fakes.FakeHTTPClient{FakeResponse: queryPageResponse(1, 1, 7)}
+		rallyClient := New("abcdef", "http://myRallyUrl", fakeClient)
+
+		count, err := rallyClient.CountWithOptions(context.Background(), nil, "defect", QueryOptions{FetchMode: mode})
+		if err != nil {
+			t.Fatalf("FetchMode %v: CountWithOptions failed unexpectedly: %v", mode, err)
+		}
+		if count != 7 {
+			t.Errorf("FetchMode %v: expected count 7, got %d", mode, count)
+		}
+		if got := fakeClient.SpyRequest.URL.Query().Get("fetch"); got != "ObjectID" {
+			t.Errorf("FetchMode %v: expected fetch=ObjectID, got %q", mode, got)
+		}
+	}
+}
+
+func TestCountDefects_Error(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{FakeResponse: &http.Response{
+		StatusCode: http.StatusUnauthorized,
+		Body:       &fakes.FakeResponseBody{Reader: bytes.NewBufferString(`{}`)},
+	}}
+	count, err := NewDefect(New("abcdef", "http://myRallyUrl", fakeClient)).CountDefects(context.Background(), nil)
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	if count != 0 {
+		t.Errorf("expected count 0 on error, got %d", count)
+	}
+}
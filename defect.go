@@ -18,6 +18,7 @@ package rallyresttoolkit
 
 import (
 	"context"
+	"slices"
 	"strconv"
 
 	"github.com/aleksofficial/go-rally-rest-toolkit/models"
@@ -29,12 +30,7 @@ type Defect struct {
 }
 
 // QueryDefectResponse - struct to contain query response
-type QueryDefectResponse struct {
-	QueryResult struct {
-		Results          []models.Defect
-		TotalResultCount int
-	}
-}
+type QueryDefectResponse = models.QueryResponse[models.Defect]
 
 // GetDefectResponse - Struct to contain response
 type GetDefectResponse struct {
@@ -55,8 +51,36 @@ type deResult struct {
 }
 
 // OperationResponse - struct to contain response
-type deOperationResponse struct {
-	OperationalResult deResult
+type deOperationResponse = models.OperationResponse[models.Defect]
+
+// DuplicateOption - configures MarkDuplicate
+type DuplicateOption func(*duplicateOptions)
+
+type duplicateOptions struct {
+	state string
+}
+
+// WithDuplicateState - makes MarkDuplicate also set the defect's State, for
+// workspaces whose State list has a value such as "Duplicate"
+func WithDuplicateState(state string) DuplicateOption {
+	return func(o *duplicateOptions) {
+		o.state = state
+	}
+}
+
+// TransitionOption - configures TransitionDefect
+type TransitionOption func(*transitionOptions)
+
+type transitionOptions struct {
+	allowed []string
+}
+
+// WithAllowedDefectStates - replaces the default Submitted, Open, Fixed and
+// Closed states TransitionDefect accepts, for workspaces with a custom State list
+func WithAllowedDefectStates(states ...string) TransitionOption {
+	return func(o *transitionOptions) {
+		o.allowed = states
+	}
 }
 
 // NewDefect - creates new Defect
@@ -73,6 +97,46 @@ func (s *Defect) QueryDefect(ctx context.Context, query map[string]string) (des
 	return qdes.QueryResult.Results, err
 }
 
+// QueryDefectWithMeta - QueryRequest that also returns the envelope's paging metadata and Warnings
+func (s *Defect) QueryDefectWithMeta(ctx context.Context, query map[string]string) ([]models.Defect, QueryMeta, error) {
+	return Query[models.Defect](ctx, s.client, "defect", query, QueryOptions{})
+}
+
+// QueryDefectPage - abstraction for QueryRequestWithOptions that keeps the paging metadata
+func (s *Defect) QueryDefectPage(ctx context.Context, query map[string]string, opts QueryOptions) (Page[models.Defect], error) {
+	return queryPage[models.Defect](ctx, s.client, query, "defect", opts)
+}
+
+// QueryDefectRaw - abstraction for QueryRequestRaw, for query clauses written by hand
+func (s *Defect) QueryDefectRaw(ctx context.Context, rawQuery string, opts QueryOptions) (Page[models.Defect], error) {
+	return queryRawPage[models.Defect](ctx, s.client, rawQuery, "defect", opts)
+}
+
+// QueryDefectWith - abstraction for QueryRequestWith
+func (s *Defect) QueryDefectWith(ctx context.Context, b *QueryBuilder) (Page[models.Defect], error) {
+	return queryWithPage[models.Defect](ctx, s.client, b, "defect")
+}
+
+// QueryDefectByState - lists every defect in the given state, page by page
+func (s *Defect) QueryDefectByState(ctx context.Context, state string) ([]models.Defect, error) {
+	return queryAllExprResults[models.Defect](ctx, s.client, Eq("State", state), "defect")
+}
+
+// QueryDefectByOwner - lists every defect owned by the user a ref points at
+func (s *Defect) QueryDefectByOwner(ctx context.Context, userRef string) ([]models.Defect, error) {
+	return queryAllExprResults[models.Defect](ctx, s.client, RefEq("Owner", RefValue(userRef)), "defect")
+}
+
+// QueryDefectByIteration - lists every defect scheduled in the iteration a ref points at
+func (s *Defect) QueryDefectByIteration(ctx context.Context, iterationRef string) ([]models.Defect, error) {
+	return queryAllExprResults[models.Defect](ctx, s.client, RefEq("Iteration", RefValue(iterationRef)), "defect")
+}
+
+// FindDefectsByName - lists every defect whose Name contains name, ignoring case
+func (s *Defect) FindDefectsByName(ctx context.Context, name string) ([]models.Defect, error) {
+	return queryAllExprResults[models.Defect](ctx, s.client, NameContainsAnyCase("Name", name), "defect")
+}
+
 // GetDefect - abstraction for GetRequest
 func (s *Defect) GetDefect(ctx context.Context, objectID string) (de models.Defect, err error) {
 	gde := new(GetDefectResponse)
@@ -82,8 +146,9 @@ func (s *Defect) GetDefect(ctx context.Context, objectID string) (de models.Defe
 
 // CreateDefect - abstraction for CreateRequest
 func (s *Defect) CreateDefect(ctx context.Context, de models.Defect) (der models.Defect, err error) {
-	createRequest := CreateDefectRequest{
-		Defect: de,
+	createRequest, err := writeRequest("Defect", de)
+	if err != nil {
+		return der, err
 	}
 	ude := new(CreateDefectResponse)
 	err = s.client.CreateRequest(ctx, "defect", createRequest, &ude)
@@ -91,10 +156,22 @@ func (s *Defect) CreateDefect(ctx context.Context, de models.Defect) (der models
 	return der, err
 }
 
+// FindOrCreateDefect - returns the first defect matching matchQuery, e.g. on a unique
+// external ID, or else creates de; the bool reports whether it was created
+func (s *Defect) FindOrCreateDefect(ctx context.Context, matchQuery map[string]string, de models.Defect) (models.Defect, bool, error) {
+	return findOrCreate[models.Defect](ctx, s.client, "defect", matchQuery, func(ctx context.Context) (models.Defect, error) {
+		return s.CreateDefect(ctx, de)
+	})
+}
+
 // UpdateDefect - abstraction for UpdateRequest
 func (s *Defect) UpdateDefect(ctx context.Context, de models.Defect) (der models.Defect, err error) {
+	updateRequest, err := writeRequest("Defect", de)
+	if err != nil {
+		return der, err
+	}
 	ude := new(deOperationResponse)
-	err = s.client.UpdateRequest(ctx, strconv.Itoa(de.ObjectID), "Defect", de, &ude)
+	err = s.client.UpdateRequest(ctx, strconv.Itoa(de.ObjectID), "Defect", updateRequest, &ude)
 	der = ude.OperationalResult.Object
 	return der, err
 }
@@ -105,3 +182,65 @@ func (s *Defect) DeleteDefect(ctx context.Context, objectID string) (err error)
 	err = s.client.DeleteRequest(ctx, objectID, "defect", &ude)
 	return err
 }
+
+// AddMilestone - adds a milestone, by ref, to the Milestones collection of a defect
+func (s *Defect) AddMilestone(ctx context.Context, objectID string, milestoneRef string) error {
+	return s.client.AddToCollection(ctx, "defect", objectID, "Milestones", []string{milestoneRef}, nil)
+}
+
+// RemoveMilestone - removes a milestone, by ref, from the Milestones collection of a defect
+func (s *Defect) RemoveMilestone(ctx context.Context, objectID string, milestoneRef string) error {
+	return s.client.RemoveFromCollection(ctx, "defect", objectID, "Milestones", []string{milestoneRef}, nil)
+}
+
+// MarkDuplicate - links a defect to the defect it duplicates, by ref, through
+// the Duplicates collection, and optionally sets its State (see WithDuplicateState)
+func (s *Defect) MarkDuplicate(ctx context.Context, defectID string, duplicateOfRef string, opts ...DuplicateOption) error {
+	var o duplicateOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	if err := s.client.AddToCollection(ctx, "defect", defectID, "Duplicates", []string{duplicateOfRef}, nil); err != nil {
+		return err
+	}
+	if o.state == "" {
+		return nil
+	}
+
+	updateRequest, err := writeRequest("Defect", models.Defect{State: o.state})
+	if err != nil {
+		return err
+	}
+	return s.client.UpdateRequest(ctx, defectID, "defect", updateRequest, nil)
+}
+
+// TransitionDefect - moves a defect to another State, sending only State so no
+// other field is overwritten, and returns the updated defect. The state must be
+// one of Rally's defaults unless WithAllowedDefectStates says otherwise.
+func (s *Defect) TransitionDefect(ctx context.Context, objectID string, newState string, opts ...TransitionOption) (der models.Defect, err error) {
+	o := transitionOptions{
+		allowed: []string{models.DefectStateSubmitted, models.DefectStateOpen, models.DefectStateFixed, models.DefectStateClosed},
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if !slices.Contains(o.allowed, newState) {
+		return der, &InvalidDefectStateError{State: newState, Allowed: o.allowed}
+	}
+
+	updateRequest, err := writeRequest("Defect", models.Defect{State: newState})
+	if err != nil {
+		return der, err
+	}
+	ude := new(deOperationResponse)
+	err = s.client.UpdateRequest(ctx, objectID, "defect", updateRequest, &ude)
+	der = ude.OperationalResult.Object
+	return der, err
+}
+
+// GetDuplicates - lists every defect in the Duplicates collection of a defect,
+// following the paging metadata
+func (s *Defect) GetDuplicates(ctx context.Context, defectID string) (des []models.Defect, err error) {
+	return GetCollection[models.Defect](ctx, s.client, "/defect/"+defectID+"/Duplicates")
+}
@@ -19,7 +19,12 @@ package rallyresttoolkit_test
 import (
 	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
+	"io"
 	"net/http"
+	"reflect"
+	"sort"
 	"testing"
 
 	. "github.com/aleksofficial/go-rally-rest-toolkit"
@@ -125,8 +130,8 @@ func TestUpdateDefect_ValidRequest(t *testing.T) {
 	ctx := context.Background()
 
 	updateDefect := models.Defect{
-		Name:     ctrlName,
-		ObjectID: 50137325678,
+		Name:              ctrlName,
+		PersistableObject: models.PersistableObject{ObjectID: 50137325678},
 	}
 	result, err := defectClient.UpdateDefect(ctx, updateDefect)
 	if err != nil {
@@ -157,3 +162,705 @@ func TestDeleteDefect_ValidObjectID(t *testing.T) {
 		t.Fatalf("DeleteDefect failed unexpectedly: %v", err)
 	}
 }
+
+func TestQueryDefect_DiscussionAndAttachmentCounts(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{
+		FakeResponse: &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       &fakes.FakeResponseBody{Reader: bytes.NewBufferString(`{"QueryResult": { "TotalResultCount": 1, "Results": [{"ObjectID": 50137325678, "FormattedID": "DE624340", "Discussion": {"_ref": "https://rally1.rallydev.com/slm/webservice/v2.0/Defect/50137325678/Discussion", "_type": "ConversationPost", "Count": 3}, "Attachments": {"_ref": "https://rally1.rallydev.com/slm/webservice/v2.0/Defect/50137325678/Attachments", "_type": "Attachment", "Count": 1}}]}}`)},
+		},
+	}
+
+	rallyClient := New("abcdef", "http://myRallyUrl", fakeClient)
+	defectClient := NewDefect(rallyClient)
+
+	results, err := defectClient.QueryDefect(context.Background(), map[string]string{"FormattedID": "DE624340"})
+	if err != nil {
+		t.Fatalf("QueryDefect failed unexpectedly: %v", err)
+	}
+	if len(results) != 1 {
+		t.Fatalf("expected 1 result, got %d", len(results))
+	}
+	if results[0].Discussion == nil || results[0].Discussion.Count != 3 {
+		t.Errorf("expected Discussion.Count=3, got %+v", results[0].Discussion)
+	}
+	if results[0].Attachments == nil || results[0].Attachments.Count != 1 {
+		t.Errorf("expected Attachments.Count=1, got %+v", results[0].Attachments)
+	}
+}
+
+func TestCreateDefect_OmitsReadOnlySummaries(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{
+		FakeResponse: &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       &fakes.FakeResponseBody{Reader: bytes.NewBufferString(`{"CreateResult": {"Object": {"Name": "NewDefect", "ObjectID": 50137325678}}}`)},
+		},
+	}
+
+	rallyClient := New("abcdef", "http://myRallyUrl", fakeClient)
+	defectClient := NewDefect(rallyClient)
+
+	newDefect := models.Defect{
+		Name:        "NewDefect",
+		Discussion:  &models.Reference{Count: 3},
+		Attachments: &models.Reference{Count: 1},
+	}
+	if _, err := defectClient.CreateDefect(context.Background(), newDefect); err != nil {
+		t.Fatalf("CreateDefect failed unexpectedly: %v", err)
+	}
+
+	var body map[string]map[string]interface{}
+	if err := json.NewDecoder(fakeClient.SpyRequest.Body).Decode(&body); err != nil {
+		t.Fatalf("failed to decode request body: %v", err)
+	}
+	defect, ok := body["Defect"]
+	if !ok {
+		t.Fatalf("expected request body wrapped in a Defect envelope, got %v", body)
+	}
+	if defect["Name"] != "NewDefect" {
+		t.Errorf("expected Name='NewDefect', got %v", defect["Name"])
+	}
+	for _, field := range []string{"Discussion", "Attachments"} {
+		if _, ok := defect[field]; ok {
+			t.Errorf("expected read-only %s to be omitted from create body, got %v", field, defect[field])
+		}
+	}
+}
+
+func TestQueryDefectPage_PagingMetadata(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{
+		FakeResponse: &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       &fakes.FakeResponseBody{Reader: bytes.NewBufferString(`{"QueryResult": { "TotalResultCount": 45, "StartIndex": 21, "PageSize": 20, "Results": [{"ObjectID": 1}, {"ObjectID": 2}]}}`)},
+		},
+	}
+
+	rallyClient := New("abcdef", "http://myRallyUrl", fakeClient)
+	defectClient := NewDefect(rallyClient)
+
+	page, err := defectClient.QueryDefectPage(context.Background(), map[string]string{"State": "Open"}, QueryOptions{Start: 21, PageSize: 20})
+	if err != nil {
+		t.Fatalf("QueryDefectPage failed unexpectedly: %v", err)
+	}
+	if got := fakeClient.SpyRequest.URL.Query(); got.Get("start") != "21" || got.Get("pagesize") != "20" {
+		t.Errorf("expected start=21 and pagesize=20, got %q", fakeClient.SpyRequest.URL.RawQuery)
+	}
+	if page.TotalResultCount != 45 || page.StartIndex != 21 || page.PageSize != 20 {
+		t.Errorf("unexpected paging metadata: %+v", page)
+	}
+	if len(page.Results) != 2 || page.Results[1].ObjectID != 2 {
+		t.Errorf("unexpected results: %+v", page.Results)
+	}
+	if !page.HasMore() {
+		t.Error("expected HasMore to be true")
+	}
+}
+
+func TestQueryDefectPage_WarningsOnSuccess(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{
+		FakeResponse: &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       &fakes.FakeResponseBody{Reader: bytes.NewBufferString(`{"QueryResult": { "TotalResultCount": 1, "StartIndex": 1, "PageSize": 20, "Errors": [], "Warnings": ["Please update your client to use the latest version of the API."], "Results": [{"ObjectID": 1}]}}`)},
+		},
+	}
+
+	rallyClient := New("abcdef", "http://myRallyUrl", fakeClient)
+	defectClient := NewDefect(rallyClient)
+
+	page, err := defectClient.QueryDefectPage(context.Background(), map[string]string{}, QueryOptions{})
+	if err != nil {
+		t.Fatalf("QueryDefectPage failed unexpectedly: %v", err)
+	}
+	if len(page.Warnings) != 1 || page.Warnings[0] != "Please update your client to use the latest version of the API." {
+		t.Errorf("expected the warning to be surfaced, got %v", page.Warnings)
+	}
+	if len(page.Errors) != 0 {
+		t.Errorf("expected no errors, got %v", page.Errors)
+	}
+	if page.QueryResult.PageSize != 20 {
+		t.Errorf("expected PageSize=20, got %d", page.PageSize)
+	}
+}
+
+func TestMarkDuplicate_AddsDuplicateRef(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{
+		FakeResponse: &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       &fakes.FakeResponseBody{Reader: bytes.NewBufferString(`{"OperationResult": {"Errors": [], "Warnings": []}}`)},
+		},
+	}
+
+	rallyClient := New("abcdef", "http://myRallyUrl", fakeClient)
+	defectClient := NewDefect(rallyClient)
+
+	err := defectClient.MarkDuplicate(context.Background(), "50137325678", "/defect/50137325000")
+	if err != nil {
+		t.Fatalf("MarkDuplicate failed unexpectedly: %v", err)
+	}
+	if fakeClient.CallCount != 1 {
+		t.Errorf("expected only the collection add without WithDuplicateState, got %d calls", fakeClient.CallCount)
+	}
+	if got := fakeClient.SpyRequest.URL.Path; got != "/defect/50137325678/Duplicates/add" {
+		t.Errorf("unexpected URL path: %s", got)
+	}
+
+	var body struct {
+		CollectionItems []models.Reference
+	}
+	if err := json.NewDecoder(fakeClient.SpyRequest.Body).Decode(&body); err != nil {
+		t.Fatalf("failed to decode request body: %v", err)
+	}
+	if len(body.CollectionItems) != 1 || body.CollectionItems[0].Ref != "/defect/50137325000" {
+		t.Errorf("unexpected CollectionItems: %+v", body.CollectionItems)
+	}
+}
+
+func TestMarkDuplicate_WithDuplicateState(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{
+		FakeResponses: []*http.Response{
+			{
+				StatusCode: http.StatusOK,
+				Body:       &fakes.FakeResponseBody{Reader: bytes.NewBufferString(`{"OperationResult": {"Errors": [], "Warnings": []}}`)},
+			},
+			{
+				StatusCode: http.StatusOK,
+				Body:       &fakes.FakeResponseBody{Reader: bytes.NewBufferString(`{"OperationResult": {"Object": {"ObjectID": 50137325678, "State": "Duplicate"}}}`)},
+			},
+		},
+	}
+
+	rallyClient := New("abcdef", "http://myRallyUrl", fakeClient)
+	defectClient := NewDefect(rallyClient)
+
+	err := defectClient.MarkDuplicate(context.Background(), "50137325678", "/defect/50137325000", WithDuplicateState("Duplicate"))
+	if err != nil {
+		t.Fatalf("MarkDuplicate failed unexpectedly: %v", err)
+	}
+	if fakeClient.CallCount != 2 {
+		t.Fatalf("expected collection add and state update, got %d calls", fakeClient.CallCount)
+	}
+	if got := fakeClient.SpyRequest.URL.Path; got != "/defect/50137325678" {
+		t.Errorf("unexpected update URL path: %s", got)
+	}
+
+	var body struct {
+		Defect map[string]interface{}
+	}
+	if err := json.NewDecoder(fakeClient.SpyRequest.Body).Decode(&body); err != nil {
+		t.Fatalf("failed to decode request body: %v", err)
+	}
+	if len(body.Defect) != 1 || body.Defect["State"] != "Duplicate" {
+		t.Errorf("expected only State=Duplicate to be written, got %v", body.Defect)
+	}
+}
+
+func TestGetDuplicates_EmptyCollection(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{
+		FakeResponse: &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       &fakes.FakeResponseBody{Reader: bytes.NewBufferString(`{"QueryResult": {"TotalResultCount": 0, "StartIndex": 1, "PageSize": 20, "Results": [], "Errors": [], "Warnings": []}}`)},
+		},
+	}
+
+	rallyClient := New("abcdef", "http://myRallyUrl", fakeClient)
+	defectClient := NewDefect(rallyClient)
+
+	duplicates, err := defectClient.GetDuplicates(context.Background(), "50137325678")
+	if err != nil {
+		t.Fatalf("GetDuplicates failed unexpectedly: %v", err)
+	}
+	if len(duplicates) != 0 {
+		t.Errorf("expected no duplicates, got %d", len(duplicates))
+	}
+	if fakeClient.SpyRequest.Method != "GET" {
+		t.Errorf("expected GET, got %s", fakeClient.SpyRequest.Method)
+	}
+	if got := fakeClient.SpyRequest.URL.Path; got != "/defect/50137325678/Duplicates" {
+		t.Errorf("unexpected URL path: %s", got)
+	}
+}
+
+func TestQueryDefect_DuplicatesSummary(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{
+		FakeResponse: &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       &fakes.FakeResponseBody{Reader: bytes.NewBufferString(`{"QueryResult": {"TotalResultCount": 1, "Results": [{"ObjectID": 50137325678, "Duplicates": {"_ref": "https://rally1.rallydev.com/slm/webservice/v2.0/Defect/50137325678/Duplicates", "Count": 2}}]}}`)},
+		},
+	}
+
+	rallyClient := New("abcdef", "http://myRallyUrl", fakeClient)
+	defectClient := NewDefect(rallyClient)
+
+	defects, err := defectClient.QueryDefect(context.Background(), map[string]string{"ObjectID": "50137325678"})
+	if err != nil {
+		t.Fatalf("QueryDefect failed unexpectedly: %v", err)
+	}
+	if len(defects) != 1 || defects[0].Duplicates == nil || defects[0].Duplicates.Count != 2 {
+		t.Errorf("expected Duplicates summary with Count=2, got %+v", defects)
+	}
+}
+
+func TestUpdateDefect_OmitsSystemMetadata(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{
+		FakeResponses: []*http.Response{
+			{
+				StatusCode: http.StatusOK,
+				Body:       &fakes.FakeResponseBody{Reader: bytes.NewBufferString(`{"Defect": {"_ref": "https://rally1.rallydev.com/slm/webservice/v2.0/defect/50137325678", "_refObjectUUID": "0b9a2c1e-6f7a-4a5e-9d8c-3c2b1a0f9e8d", "_objectVersion": "12", "_CreatedAt": "Jan 21", "VersionId": "12", "ObjectID": 50137325678, "State": "Open"}}`)},
+			},
+			{
+				StatusCode: http.StatusOK,
+				Body:       &fakes.FakeResponseBody{Reader: bytes.NewBufferString(`{"OperationalResult": {"Object": {"_objectVersion": "13", "ObjectID": 50137325678, "State": "Fixed"}}}`)},
+			},
+		},
+	}
+
+	rallyClient := New("abcdef", "http://myRallyUrl", fakeClient)
+	defectClient := NewDefect(rallyClient)
+	ctx := context.Background()
+
+	defect, err := defectClient.GetDefect(ctx, "50137325678")
+	if err != nil {
+		t.Fatalf("GetDefect failed unexpectedly: %v", err)
+	}
+	if defect.GetObjectVersion() != "12" || defect.VersionId != "12" || defect.CreatedAt != "Jan 21" {
+		t.Errorf("unexpected version metadata: %q/%q/%q", defect.ObjectVersion, defect.VersionId, defect.CreatedAt)
+	}
+	if defect.RefObjectUUID != "0b9a2c1e-6f7a-4a5e-9d8c-3c2b1a0f9e8d" {
+		t.Errorf("unexpected _refObjectUUID: %q", defect.RefObjectUUID)
+	}
+
+	defect.State = "Fixed"
+	result, err := defectClient.UpdateDefect(ctx, defect)
+	if err != nil {
+		t.Fatalf("UpdateDefect failed unexpectedly: %v", err)
+	}
+	if result.GetObjectVersion() != "13" {
+		t.Errorf("expected _objectVersion=13 after update, got %q", result.ObjectVersion)
+	}
+
+	var body struct {
+		Defect map[string]json.RawMessage
+	}
+	if err := json.NewDecoder(fakeClient.SpyRequest.Body).Decode(&body); err != nil {
+		t.Fatalf("failed to decode request body: %v", err)
+	}
+	for _, name := range []string{"_ref", "_refObjectUUID", "_objectVersion", "_CreatedAt", "VersionId"} {
+		if _, ok := body.Defect[name]; ok {
+			t.Errorf("expected read-only %s not to be written", name)
+		}
+	}
+	if _, ok := body.Defect["State"]; !ok {
+		t.Error("expected State to be written")
+	}
+}
+
+func TestCreateDefect_OnlyWritableFieldsFullyPopulated(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{
+		FakeResponse: &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       &fakes.FakeResponseBody{Reader: bytes.NewBufferString(`{"CreateResult": {"Object": {"ObjectID": 50137325678}}}`)},
+		},
+	}
+
+	rallyClient := New("abcdef", "http://myRallyUrl", fakeClient)
+	defectClient := NewDefect(rallyClient)
+
+	ref := &models.Reference{Ref: "/ref/1"}
+	newDefect := models.Defect{
+		PersistableObject: models.PersistableObject{
+			Ref:           "https://rally1.rallydev.com/slm/webservice/v2.0/defect/50137325678",
+			RefObjectUUID: "0b9a2c1e-6f7a-4a5e-9d8c-3c2b1a0f9e8d",
+			ObjectVersion: "12",
+			CreatedAt:     "Jan 21",
+			VersionId:     "12",
+			Type:          "Defect",
+			CreationDate:  "2016-01-21T21:47:08.551Z",
+			ObjectID:      50137325678,
+			ObjectUUID:    "0b9a2c1e-6f7a-4a5e-9d8c-3c2b1a0f9e8d",
+			Errors:        []string{"error"},
+			Warnings:      []string{"warning"},
+		},
+		Subscription:        ref,
+		Workspace:           ref,
+		Changesets:          ref,
+		Requirement:         ref,
+		Description:         "Description",
+		FormattedID:         "DE624340",
+		Name:                "Name",
+		Notes:               "Notes",
+		Owner:               ref,
+		Project:             ref,
+		LastBuild:           "LastBuild",
+		LastRun:             "LastRun",
+		ScheduleState:       "Defined",
+		ScheduleStatePrefix: "D",
+		Iteration:           ref,
+		State:               "Open",
+		Priority:            "High",
+		Severity:            "Major Problem",
+		Tasks:               ref,
+		Resolution:          "Code Change",
+		DragAndDropRank:     "P!",
+		Milestones:          &models.Collection{Count: 1, Ref: "/defect/50137325678/Milestones"},
+		Duplicates:          &models.Collection{Count: 1, Ref: "/defect/50137325678/Duplicates"},
+		Discussion:          &models.Reference{Count: 3},
+		Attachments:         &models.Reference{Count: 1},
+		Blocked:             models.Bool(true),
+		BlockedReason:       models.String("Waiting on vendor"),
+		Ready:               models.Bool(false),
+	}
+	if _, err := defectClient.CreateDefect(context.Background(), newDefect); err != nil {
+		t.Fatalf("CreateDefect failed unexpectedly: %v", err)
+	}
+
+	var body struct {
+		Defect map[string]json.RawMessage
+	}
+	if err := json.NewDecoder(fakeClient.SpyRequest.Body).Decode(&body); err != nil {
+		t.Fatalf("failed to decode request body: %v", err)
+	}
+	var sent []string
+	for name := range body.Defect {
+		sent = append(sent, name)
+	}
+	sort.Strings(sent)
+
+	expected := []string{
+		"Blocked", "BlockedReason", "Changesets", "Description", "Iteration", "LastBuild", "LastRun",
+		"Name", "Notes", "Owner", "Priority", "Project", "Ready", "Requirement", "Resolution",
+		"ScheduleState", "ScheduleStatePrefix", "Severity", "State", "Subscription", "Tasks", "Workspace",
+	}
+	if !reflect.DeepEqual(sent, expected) {
+		t.Errorf("unexpected create body fields:\n got: %v\nwant: %v", sent, expected)
+	}
+}
+
+func TestTransitionDefect_SendsOnlyState(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{
+		FakeResponse: &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       &fakes.FakeResponseBody{Reader: bytes.NewBufferString(`{"OperationalResult": {"Object": {"ObjectID": 12345, "Name": "Crash on login", "State": "Fixed"}}}`)},
+		},
+	}
+	defectClient := NewDefect(New("abcdef", "http://myRallyUrl", fakeClient))
+
+	defect, err := defectClient.TransitionDefect(context.Background(), "12345", models.DefectStateFixed)
+	if err != nil {
+		t.Fatalf("TransitionDefect failed unexpectedly: %v", err)
+	}
+	if defect.State != models.DefectStateFixed || defect.Name != "Crash on login" {
+		t.Errorf("expected the updated defect to be returned, got %+v", defect)
+	}
+
+	body, err := io.ReadAll(fakeClient.SpyRequest.Body)
+	if err != nil {
+		t.Fatalf("failed to read request body: %v", err)
+	}
+	if string(body) != `{"Defect":{"State":"Fixed"}}` {
+		t.Errorf("expected a State-only body, got %s", body)
+	}
+}
+
+func TestTransitionDefect_RejectsUnknownState(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{}
+	defectClient := NewDefect(New("abcdef", "http://myRallyUrl", fakeClient))
+
+	_, err := defectClient.TransitionDefect(context.Background(), "12345", "Verified")
+
+	var stateErr *InvalidDefectStateError
+	if !errors.As(err, &stateErr) || stateErr.State != "Verified" {
+		t.Fatalf("expected InvalidDefectStateError for %q, got %v", "Verified", err)
+	}
+	if fakeClient.CallCount != 0 {
+		t.Errorf("expected no HTTP call, got %d", fakeClient.CallCount)
+	}
+}
+
+func TestTransitionDefect_CustomStates(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{
+		FakeResponse: &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       &fakes.FakeResponseBody{Reader: bytes.NewBufferString(`{"OperationalResult": {"Object": {"ObjectID": 12345, "State": "Verified"}}}`)},
+		},
+	}
+	defectClient := NewDefect(New("abcdef", "http://myRallyUrl", fakeClient))
+	allowed := WithAllowedDefectStates("Open", "Verified")
+
+	if _, err := defectClient.TransitionDefect(context.Background(), "12345", "Verified", allowed); err != nil {
+		t.Fatalf("TransitionDefect failed unexpectedly: %v", err)
+	}
+
+	_, err := defectClient.TransitionDefect(context.Background(), "12345", models.DefectStateClosed, allowed)
+	var stateErr *InvalidDefectStateError
+	if !errors.As(err, &stateErr) {
+		t.Errorf("expected Closed to be rejected by the override list, got %v", err)
+	}
+}
+
+func TestQueryDefectByState(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{
+		FakeResponse: &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       &fakes.FakeResponseBody{Reader: bytes.NewBufferString(`{"QueryResult": { "TotalResultCount": 1, "Results": [{"ObjectID": 1, "State": "Open"}]}}`)},
+		},
+	}
+	defectClient := NewDefect(New("abcdef", "http://myRallyUrl", fakeClient))
+
+	defects, err := defectClient.QueryDefectByState(context.Background(), models.DefectStateOpen)
+	if err != nil {
+		t.Fatalf("QueryDefectByState failed unexpectedly: %v", err)
+	}
+	if len(defects) != 1 || defects[0].State != "Open" {
+		t.Errorf("unexpected defects: %+v", defects)
+	}
+	if got := fakeClient.SpyRequest.URL.Query().Get("query"); got != "( State = Open )" {
+		t.Errorf("unexpected query %s", got)
+	}
+}
+
+func TestQueryDefectByState_AllPages(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{
+		FakeResponses: []*http.Response{
+			queryPageResponse(1, 20, 25),
+			queryPageResponse(21, 5, 25),
+		},
+	}
+	defectClient := NewDefect(New("abcdef", "http://myRallyUrl", fakeClient))
+
+	defects, err := defectClient.QueryDefectByState(context.Background(), models.DefectStateOpen)
+	if err != nil {
+		t.Fatalf("QueryDefectByState failed unexpectedly: %v", err)
+	}
+	if len(defects) != 25 {
+		t.Errorf("expected all 25 defects, got %d", len(defects))
+	}
+	if got := fakeClient.SpyRequest.URL.Query().Get("start"); got != "21" {
+		t.Errorf("expected the last request to start at 21, got %q", got)
+	}
+	if got := fakeClient.SpyRequest.URL.Query().Get("query"); got != "( State = Open )" {
+		t.Errorf("expected every page to keep the query, got %s", got)
+	}
+}
+
+func TestFindDefectsByName(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{
+		FakeResponse: &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       &fakes.FakeResponseBody{Reader: bytes.NewBufferString(`{"QueryResult": { "TotalResultCount": 1, "Results": [{"ObjectID": 1, "Name": "Login fails"}]}}`)},
+		},
+	}
+	defectClient := NewDefect(New("abcdef", "http://myRallyUrl", fakeClient))
+
+	defects, err := defectClient.FindDefectsByName(context.Background(), "login")
+	if err != nil {
+		t.Fatalf("FindDefectsByName failed unexpectedly: %v", err)
+	}
+	if len(defects) != 1 || defects[0].Name != "Login fails" {
+		t.Errorf("unexpected defects: %+v", defects)
+	}
+	expected := "((( Name contains login ) OR ( Name contains LOGIN )) OR ( Name contains Login ))"
+	if got := fakeClient.SpyRequest.URL.Query().Get("query"); got != expected {
+		t.Errorf("unexpected query %s", got)
+	}
+}
+
+func TestFindDefectsByName_AllPages(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{
+		FakeResponses: []*http.Response{
+			queryPageResponse(1, 20, 45),
+			queryPageResponse(21, 20, 45),
+			queryPageResponse(41, 5, 45),
+		},
+	}
+	defectClient := NewDefect(New("abcdef", "http://myRallyUrl", fakeClient))
+
+	defects, err := defectClient.FindDefectsByName(context.Background(), "login")
+	if err != nil {
+		t.Fatalf("FindDefectsByName failed unexpectedly: %v", err)
+	}
+	if len(defects) != 45 {
+		t.Errorf("expected all 45 defects, got %d", len(defects))
+	}
+	if got := fakeClient.SpyRequest.URL.Query().Get("start"); got != "41" {
+		t.Errorf("expected the last request to start at 41, got %q", got)
+	}
+}
+
+func TestQueryDefectByOwnerAndIteration_UseUnquotedRefPaths(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    func(*Defect) ([]models.Defect, error)
+		expected string
+	}{
+		{
+			"owner from full ref",
+			func(d *Defect) ([]models.Defect, error) {
+				return d.QueryDefectByOwner(context.Background(), "https://rally1.rallydev.com/slm/webservice/v2.0/user/999")
+			},
+			"( Owner = /user/999 )",
+		},
+		{
+			"iteration from path ref",
+			func(d *Defect) ([]models.Defect, error) {
+				return d.QueryDefectByIteration(context.Background(), "/iteration/12345")
+			},
+			"( Iteration = /iteration/12345 )",
+		},
+	}
+
+	for _, tt := range tests {
+		fakeClient := &fakes.FakeHTTPClient{
+			FakeResponse: &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       &fakes.FakeResponseBody{Reader: bytes.NewBufferString(`{"QueryResult": { "TotalResultCount": 0, "Results": []}}`)},
+			},
+		}
+		defectClient := NewDefect(New("abcdef", "http://myRallyUrl", fakeClient))
+
+		if _, err := tt.query(defectClient); err != nil {
+			t.Fatalf("%s: query failed unexpectedly: %v", tt.name, err)
+		}
+		if got := fakeClient.SpyRequest.URL.Query().Get("query"); got != tt.expected {
+			t.Errorf("%s: expected query %s, got %s", tt.name, tt.expected, got)
+		}
+	}
+}
+
+func TestQueryDefectByOwner_InvalidRef(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{}
+	defectClient := NewDefect(New("abcdef", "http://myRallyUrl", fakeClient))
+
+	if _, err := defectClient.QueryDefectByOwner(context.Background(), "jane"); !errors.Is(err, ErrInvalidRef) {
+		t.Errorf("expected ErrInvalidRef, got %v", err)
+	}
+	if fakeClient.CallCount != 0 {
+		t.Errorf("expected no HTTP call, got %d", fakeClient.CallCount)
+	}
+}
+
+func TestQueryDefectWithMeta_SurfacesWarnings(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{
+		FakeResponse: &http.Response{
+			StatusCode: http.StatusOK,
+			Body: &fakes.FakeResponseBody{Reader: bytes.NewBufferString(`{"QueryResult": {"TotalResultCount": 45, "StartIndex": 1, "PageSize": 20,
+				"Warnings": ["Please update your client to use the latest version of the API."],
+				"Results": [{"ObjectID": 1, "FormattedID": "DE1"}]}}`)},
+		},
+	}
+	defectClient := NewDefect(New("abcdef", "http://myRallyUrl", fakeClient))
+
+	defects, meta, err := defectClient.QueryDefectWithMeta(context.Background(), map[string]string{"State": "Open"})
+	if err != nil {
+		t.Fatalf("QueryDefectWithMeta failed unexpectedly: %v", err)
+	}
+	if len(defects) != 1 || defects[0].FormattedID != "DE1" {
+		t.Errorf("unexpected defects: %+v", defects)
+	}
+	expected := QueryMeta{
+		TotalResultCount: 45,
+		StartIndex:       1,
+		PageSize:         20,
+		Warnings:         []string{"Please update your client to use the latest version of the API."},
+	}
+	if !reflect.DeepEqual(meta, expected) {
+		t.Errorf("expected meta %+v, got %+v", expected, meta)
+	}
+}
+
+func TestFindOrCreateDefect_Existing(t *testing.T) {
+	doer := &scriptedDoer{
+		statuses: []int{http.StatusOK},
+		bodies:   []string{`{"QueryResult": {"TotalResultCount": 2, "Results": [{"ObjectID": 1, "Name": "Imported"}]}}`},
+	}
+	defectClient := NewDefect(New("abcdef", "http://myRallyUrl", doer))
+
+	defect, created, err := defectClient.FindOrCreateDefect(context.Background(), map[string]string{"c_ExternalID": "JIRA-1"}, models.Defect{Name: "Imported"})
+	if err != nil {
+		t.Fatalf("FindOrCreateDefect failed unexpectedly: %v", err)
+	}
+	if created || defect.ObjectID != 1 {
+		t.Errorf("expected the existing defect 1, got %+v (created=%v)", defect, created)
+	}
+	if len(doer.calls) != 1 || doer.calls[0] != "GET /defect" {
+		t.Errorf("expected only the query, got %v", doer.calls)
+	}
+}
+
+func TestFindOrCreateDefect_Creates(t *testing.T) {
+	doer := &scriptedDoer{
+		statuses: []int{http.StatusOK, http.StatusOK},
+		bodies: []string{
+			`{"QueryResult": {"TotalResultCount": 0, "Results": []}}`,
+			`{"CreateResult": {"Object": {"ObjectID": 2, "Name": "Imported"}}}`,
+		},
+	}
+	defectClient := NewDefect(New("abcdef", "http://myRallyUrl", doer))
+
+	defect, created, err := defectClient.FindOrCreateDefect(context.Background(), map[string]string{"c_ExternalID": "JIRA-1"}, models.Defect{Name: "Imported"})
+	if err != nil {
+		t.Fatalf("FindOrCreateDefect failed unexpectedly: %v", err)
+	}
+	if !created || defect.ObjectID != 2 {
+		t.Errorf("expected the created defect 2, got %+v (created=%v)", defect, created)
+	}
+	expected := []string{"GET /defect", "POST /defect/create"}
+	if !reflect.DeepEqual(doer.calls, expected) {
+		t.Errorf("expected calls %v, got %v", expected, doer.calls)
+	}
+}
+
+func TestFindOrCreateDefect_RequiresMatchQuery(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{}
+	defectClient := NewDefect(New("abcdef", "http://myRallyUrl", fakeClient))
+
+	_, created, err := defectClient.FindOrCreateDefect(context.Background(), nil, models.Defect{Name: "Imported"})
+	if !errors.Is(err, ErrInvalidQuery) || created {
+		t.Errorf("expected ErrInvalidQuery, got %v (created=%v)", err, created)
+	}
+	if fakeClient.CallCount != 0 {
+		t.Errorf("expected no requests, got %d", fakeClient.CallCount)
+	}
+}
+
+func TestFindOrCreateDefect_QueryError(t *testing.T) {
+	doer := &scriptedDoer{statuses: []int{http.StatusBadRequest}, bodies: []string{`{"QueryResult": {"Errors": ["Could not parse"]}}`}}
+	defectClient := NewDefect(New("abcdef", "http://myRallyUrl", doer))
+
+	_, created, err := defectClient.FindOrCreateDefect(context.Background(), map[string]string{"c_ExternalID": "JIRA-1"}, models.Defect{Name: "Imported"})
+	if !errors.Is(err, ErrRallyAPI) || created {
+		t.Errorf("expected the query's RallyAPIError, got %v (created=%v)", err, created)
+	}
+	if len(doer.calls) != 1 {
+		t.Errorf("expected no create after a failed query, got %v", doer.calls)
+	}
+}
+
+func TestGetDuplicates_FollowsPages(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{
+		FakeResponses: []*http.Response{
+			{
+				StatusCode: http.StatusOK,
+				Body:       &fakes.FakeResponseBody{Reader: bytes.NewBufferString(`{"QueryResult": {"TotalResultCount": 3, "StartIndex": 1, "PageSize": 2, "Results": [{"ObjectID": 1}, {"ObjectID": 2}]}}`)},
+			},
+			{
+				StatusCode: http.StatusOK,
+				Body:       &fakes.FakeResponseBody{Reader: bytes.NewBufferString(`{"QueryResult": {"TotalResultCount": 3, "StartIndex": 3, "PageSize": 2, "Results": [{"ObjectID": 3}]}}`)},
+			},
+		},
+	}
+	defectClient := NewDefect(New("abcdef", "http://myRallyUrl", fakeClient))
+
+	duplicates, err := defectClient.GetDuplicates(context.Background(), "50137325678")
+	if err != nil {
+		t.Fatalf("GetDuplicates failed unexpectedly: %v", err)
+	}
+	if len(duplicates) != 3 || duplicates[2].ObjectID != 3 {
+		t.Errorf("expected all 3 duplicates across both pages, got %+v", duplicates)
+	}
+	if fakeClient.CallCount != 2 {
+		t.Errorf("expected 2 requests, got %d", fakeClient.CallCount)
+	}
+	if got := fakeClient.SpyRequest.URL.Query().Get("start"); got != "3" {
+		t.Errorf("expected the second page to start at 3, got %q", got)
+	}
+}
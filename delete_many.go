@@ -0,0 +1,65 @@
+/**
+* Copyright 2014 Comcast Cable Communications Management, LLC
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+* http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package rallyresttoolkit
+
+import (
+	"context"
+	"sync"
+)
+
+// DeleteMany - deletes the objects of a type with the given ObjectIDs using up
+// to concurrency parallel workers (at least one), sharing the client's rate
+// limit. A failed delete does not stop the others. The result has an entry for
+// every ID: nil once Rally confirmed the delete, otherwise the error, or the
+// context's error for IDs not attempted before ctx was done.
+func (s *RallyClient) DeleteMany(ctx context.Context, queryType string, objectIDs []string, concurrency int) map[string]error {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	results := make(map[string]error, len(objectIDs))
+	var mu sync.Mutex
+	record := func(objectID string, err error) {
+		mu.Lock()
+		results[objectID] = err
+		mu.Unlock()
+	}
+
+	ids := make(chan string)
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for objectID := range ids {
+				if err := ctx.Err(); err != nil {
+					record(objectID, err)
+					continue
+				}
+				record(objectID, s.DeleteRequest(ctx, objectID, queryType, nil))
+			}
+		}()
+	}
+
+	for _, objectID := range objectIDs {
+		ids <- objectID
+	}
+	close(ids)
+	wg.Wait()
+
+	return results
+}
@@ -0,0 +1,124 @@
+/**
+* Copyright 2014 Comcast Cable Communications Management, LLC
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+* http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package rallyresttoolkit_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/aleksofficial/go-rally-rest-toolkit"
+)
+
+// deleteServer answers deletes concurrently, failing the IDs in missing with
+// a 404, and records how many requests were in flight at once.
+type deleteServer struct {
+	missing map[string]bool
+
+	mu       sync.Mutex
+	calls    int
+	inFlight int
+	maxSeen  int
+}
+
+func (s *deleteServer) Do(req *http.Request) (*http.Response, error) {
+	s.mu.Lock()
+	s.calls++
+	s.inFlight++
+	if s.inFlight > s.maxSeen {
+		s.maxSeen = s.inFlight
+	}
+	s.mu.Unlock()
+
+	time.Sleep(5 * time.Millisecond)
+
+	s.mu.Lock()
+	s.inFlight--
+	s.mu.Unlock()
+
+	if s.missing[path.Base(req.URL.Path)] {
+		return &http.Response{
+			StatusCode: http.StatusNotFound,
+			Body:       io.NopCloser(bytes.NewBufferString(`{"OperationResult": {"Errors": ["Object not found"]}}`)),
+		}, nil
+	}
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Body:       io.NopCloser(bytes.NewBufferString(`{"OperationResult": {"Errors": []}}`)),
+	}, nil
+}
+
+func TestDeleteMany_ContinuesPastFailures(t *testing.T) {
+	server := &deleteServer{missing: map[string]bool{"3": true, "7": true}}
+	rallyClient := New("abcdef", "http://myRallyUrl", server)
+
+	ids := make([]string, 10)
+	for i := range ids {
+		ids[i] = fmt.Sprint(i)
+	}
+
+	results := rallyClient.DeleteMany(context.Background(), "defect", ids, 4)
+
+	if len(results) != 10 {
+		t.Fatalf("expected a result for all 10 IDs, got %d", len(results))
+	}
+	for _, id := range ids {
+		err := results[id]
+		if server.missing[id] {
+			if !errors.Is(err, ErrRallyAPI) {
+				t.Errorf("expected a RallyAPIError for %s, got %v", id, err)
+			}
+		} else if err != nil {
+			t.Errorf("expected %s to be deleted, got %v", id, err)
+		}
+	}
+	if server.calls != 10 {
+		t.Errorf("expected 10 requests, got %d", server.calls)
+	}
+	if server.maxSeen > 4 {
+		t.Errorf("expected at most 4 concurrent requests, saw %d", server.maxSeen)
+	}
+	if server.maxSeen < 2 {
+		t.Errorf("expected deletes to run in parallel, saw %d at once", server.maxSeen)
+	}
+}
+
+func TestDeleteMany_CancelledContext(t *testing.T) {
+	server := &deleteServer{}
+	rallyClient := New("abcdef", "http://myRallyUrl", server)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	results := rallyClient.DeleteMany(ctx, "defect", []string{"1", "2", "3"}, 2)
+
+	for _, id := range []string{"1", "2", "3"} {
+		if !errors.Is(results[id], context.Canceled) {
+			t.Errorf("expected context.Canceled for %s, got %v", id, results[id])
+		}
+	}
+	if server.calls != 0 {
+		t.Errorf("expected no requests, got %d", server.calls)
+	}
+}
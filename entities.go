@@ -0,0 +1,120 @@
+/**
+* Copyright 2014 Comcast Cable Communications Management, LLC
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+* http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package rallyresttoolkit
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/aleksofficial/go-rally-rest-toolkit/models"
+)
+
+// entity describes a WSAPI type the package has a model for.
+type entity struct {
+	// name is the canonical type name used in request and response envelopes.
+	name string
+	// model is the model struct for the type.
+	model reflect.Type
+}
+
+// entities maps lower-cased WSAPI type paths to their entity.
+var entities = map[string]entity{
+	"defect":                   {"Defect", reflect.TypeOf(models.Defect{})},
+	"hierarchicalrequirement":  {"HierarchicalRequirement", reflect.TypeOf(models.HierarchicalRequirement{})},
+	"task":                     {"Task", reflect.TypeOf(models.Task{})},
+	"build":                    {"Build", reflect.TypeOf(models.Build{})},
+	"builddefinition":          {"BuildDefinition", reflect.TypeOf(models.BuildDefinition{})},
+	"changeset":                {"Changeset", reflect.TypeOf(models.Changeset{})},
+	"portfolioitem":            {"PortfolioItem", reflect.TypeOf(models.PortfolioItem{})},
+	"portfolioitem/feature":    {"Feature", reflect.TypeOf(models.PortfolioItem{})},
+	"portfolioitem/initiative": {"Initiative", reflect.TypeOf(models.PortfolioItem{})},
+	"attachment":               {"Attachment", reflect.TypeOf(models.Attachment{})},
+	"testcase":                 {"TestCase", reflect.TypeOf(models.TestCase{})},
+	"iteration":                {"Iteration", reflect.TypeOf(models.Iteration{})},
+	"release":                  {"Release", reflect.TypeOf(models.Release{})},
+	"user":                     {"User", reflect.TypeOf(models.User{})},
+}
+
+// knownEntityTypes lists, lower-cased, the WSAPI types Config.ValidateEntityTypes
+// accepts besides those in entities. Any "portfolioitem/<type>" is accepted too,
+// since portfolio item types are defined per workspace.
+var knownEntityTypes = map[string]bool{
+	"allowedattributevalue": true,
+	"artifact":              true,
+	"attributedefinition":   true,
+	"blocker":               true,
+	"change":                true,
+	"conversationpost":      true,
+	"defectsuite":           true,
+	"flowstate":             true,
+	"milestone":             true,
+	"preliminaryestimate":   true,
+	"project":               true,
+	"revision":              true,
+	"revisionhistory":       true,
+	"search":                true,
+	"scmrepository":         true,
+	"state":                 true,
+	"subscription":          true,
+	"tag":                   true,
+	"testcaseresult":        true,
+	"testfolder":            true,
+	"testset":               true,
+	"typedefinition":        true,
+	"workspace":             true,
+}
+
+// isKnownEntityType reports whether queryType names a WSAPI type, ignoring case.
+func isKnownEntityType(queryType string) bool {
+	queryType = strings.ToLower(strings.Trim(queryType, "/"))
+	if _, ok := entities[queryType]; ok || knownEntityTypes[queryType] {
+		return true
+	}
+	subtype, ok := strings.CutPrefix(queryType, "portfolioitem/")
+	return ok && subtype != "" && !strings.Contains(subtype, "/")
+}
+
+// lookupEntity returns the entity for a WSAPI type path, ignoring case.
+func lookupEntity(queryType string) (entity, bool) {
+	e, ok := entities[strings.ToLower(strings.Trim(queryType, "/"))]
+	return e, ok
+}
+
+// fieldNames returns the JSON names of all fields of a model, including those
+// promoted from embedded structs.
+func fieldNames(t reflect.Type) []string {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	var names []string
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Anonymous {
+			names = append(names, fieldNames(field.Type)...)
+			continue
+		}
+		if !field.IsExported() || field.Tag.Get("json") == "-" {
+			continue
+		}
+		names = append(names, jsonFieldName(field))
+	}
+	return names
+}
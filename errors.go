@@ -17,9 +17,13 @@
 package rallyresttoolkit
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
 	"strings"
+	"time"
 )
 
 // RallyAPIError represents an error response from the Rally API.
@@ -34,10 +38,21 @@ type RallyAPIError struct {
 	Errors []string
 	// Warnings contains the list of warning messages from the Rally API response
 	Warnings []string
+	// RetryAfter is how long a throttled or unavailable server asked the client
+	// to wait, from the Retry-After header of the last response, if any
+	RetryAfter time.Duration
 }
 
 // Error implements the error interface for RallyAPIError.
 func (e *RallyAPIError) Error() string {
+	if e.RetryAfter > 0 {
+		return fmt.Sprintf("%s (retry after %v)", e.message(), e.RetryAfter)
+	}
+	return e.message()
+}
+
+// message is the error text without the Retry-After hint.
+func (e *RallyAPIError) message() string {
 	if len(e.Errors) > 0 {
 		return fmt.Sprintf("Rally API error (status %d): %s", e.StatusCode, strings.Join(e.Errors, "; "))
 	}
@@ -50,7 +65,11 @@ func (e *RallyAPIError) Error() string {
 // Is implements errors.Is support for RallyAPIError.
 // It returns true if the target is a *RallyAPIError with the same StatusCode,
 // or if comparing against a sentinel error with StatusCode 0, it matches any RallyAPIError.
+// It also matches ErrConcurrentModification when Rally reports a conflict.
 func (e *RallyAPIError) Is(target error) bool {
+	if target == ErrConcurrentModification {
+		return e.isConcurrencyConflict()
+	}
 	t, ok := target.(*RallyAPIError)
 	if !ok {
 		return false
@@ -66,12 +85,39 @@ func (e *RallyAPIError) Is(target error) bool {
 // if an error is any RallyAPIError.
 var ErrRallyAPI = &RallyAPIError{}
 
+// ErrThrottled matches, with errors.Is, a *RallyAPIError for a 429 response
+// that was still throttled after the retries ran out. Its RetryAfter says how
+// long the server asked to wait, when it did.
+var ErrThrottled = &RallyAPIError{StatusCode: http.StatusTooManyRequests}
+
+// ErrConcurrentModification matches, with errors.Is, a *RallyAPIError for an
+// update Rally rejected because the object changed since it was read.
+var ErrConcurrentModification = errors.New("concurrent modification")
+
+// concurrencyConflictMessages are the fragments Rally uses, in any case, to
+// report a conflicting update.
+var concurrencyConflictMessages = []string{"concurrency conflict", "has been modified since"}
+
+// isConcurrencyConflict reports whether any of the errors is a conflict.
+func (e *RallyAPIError) isConcurrencyConflict() bool {
+	for _, message := range e.Errors {
+		message = strings.ToLower(message)
+		for _, conflict := range concurrencyConflictMessages {
+			if strings.Contains(message, conflict) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // rallyErrorResponse represents the structure of a Rally API error response.
 // Rally API wraps operation results in a key like "CreateResult", "QueryResult", etc.
 type rallyErrorResponse struct {
-	OperationResult *operationResult `json:"OperationResult,omitempty"`
-	CreateResult    *operationResult `json:"CreateResult,omitempty"`
-	QueryResult     *operationResult `json:"QueryResult,omitempty"`
+	OperationResult   *operationResult `json:"OperationResult,omitempty"`
+	OperationalResult *operationResult `json:"OperationalResult,omitempty"`
+	CreateResult      *operationResult `json:"CreateResult,omitempty"`
+	QueryResult       *operationResult `json:"QueryResult,omitempty"`
 }
 
 // operationResult represents the common structure for Rally API operation results.
@@ -89,23 +135,7 @@ func parseRallyError(statusCode int, body []byte) *RallyAPIError {
 		Message:    string(body),
 	}
 
-	// Try to parse as Rally API error response
-	var resp rallyErrorResponse
-	if err := json.Unmarshal(body, &resp); err != nil {
-		return apiErr
-	}
-
-	// Check which result type is present
-	var result *operationResult
-	if resp.OperationResult != nil {
-		result = resp.OperationResult
-	} else if resp.CreateResult != nil {
-		result = resp.CreateResult
-	} else if resp.QueryResult != nil {
-		result = resp.QueryResult
-	}
-
-	if result != nil {
+	if result := parseOperationResult(body); result != nil {
 		apiErr.Errors = result.Errors
 		apiErr.Warnings = result.Warnings
 		if len(result.Errors) > 0 {
@@ -115,3 +145,170 @@ func parseRallyError(statusCode int, body []byte) *RallyAPIError {
 
 	return apiErr
 }
+
+// parseSoftError returns a *RallyAPIError when a successful response still
+// carries errors in its result envelope, as Rally does for some rejected
+// creates and updates. It returns nil when there are none.
+func parseSoftError(statusCode int, body []byte) *RallyAPIError {
+	result := parseOperationResult(body)
+	if result == nil || len(result.Errors) == 0 {
+		return nil
+	}
+	return &RallyAPIError{
+		StatusCode: statusCode,
+		Message:    strings.Join(result.Errors, "; "),
+		Errors:     result.Errors,
+		Warnings:   result.Warnings,
+	}
+}
+
+// parseOperationResult returns whichever result envelope body contains, or
+// nil if it is not a Rally response.
+func parseOperationResult(body []byte) *operationResult {
+	var resp rallyErrorResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil
+	}
+
+	switch {
+	case resp.OperationResult != nil:
+		return resp.OperationResult
+	case resp.OperationalResult != nil:
+		return resp.OperationalResult
+	case resp.CreateResult != nil:
+		return resp.CreateResult
+	case resp.QueryResult != nil:
+		return resp.QueryResult
+	}
+	return nil
+}
+
+// ErrNonJSONResponse is returned when a successful response is not JSON, most
+// often an HTML login page served in place of the API after an authentication
+// redirect.
+var ErrNonJSONResponse = errors.New("expected JSON response")
+
+// checkJSONResponse rejects a body that is not valid JSON and is declared or
+// sniffed as something else, such as HTML, before it reaches json.Unmarshal.
+func checkJSONResponse(contentType string, body []byte) error {
+	trimmed := bytes.TrimSpace(body)
+	if len(trimmed) == 0 || json.Valid(trimmed) {
+		return nil
+	}
+
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	mediaType = strings.ToLower(strings.TrimSpace(mediaType))
+	declaredOther := mediaType != "" && !strings.Contains(mediaType, "json") && !strings.Contains(mediaType, "javascript")
+	if !declaredOther && trimmed[0] != '<' {
+		return nil
+	}
+
+	if mediaType == "" {
+		mediaType = "markup"
+	}
+	return fmt.Errorf("%w but got %s; likely an authentication redirect, check your API key", ErrNonJSONResponse, mediaType)
+}
+
+// ErrUnknownEntityType is returned, when Config.ValidateEntityTypes is set, for
+// a queryType that is not a known WSAPI type, e.g. a typo such as "defct".
+var ErrUnknownEntityType = errors.New("unknown entity type")
+
+// ErrNotFound is returned when a lookup such as GetDefectByFormattedID matches
+// no object.
+var ErrNotFound = errors.New("not found")
+
+// AmbiguousFormattedIDError is returned when a FormattedID lookup matches more
+// than one object, e.g. across projects that reuse a prefix.
+type AmbiguousFormattedIDError struct {
+	// QueryType is the WSAPI type that was searched
+	QueryType string
+	// FormattedID is the ID that was looked up
+	FormattedID string
+	// Matches is how many objects matched
+	Matches int
+}
+
+// Error implements the error interface for AmbiguousFormattedIDError.
+func (e *AmbiguousFormattedIDError) Error() string {
+	return fmt.Sprintf("%s %s is ambiguous: %d matches", e.QueryType, e.FormattedID, e.Matches)
+}
+
+// UnknownFieldsError is returned by ValidateCreate when a request body contains
+// fields the model for its type does not define.
+type UnknownFieldsError struct {
+	// QueryType is the WSAPI type the body was validated against
+	QueryType string
+	// Fields lists the unknown field names, sorted
+	Fields []string
+}
+
+// Error implements the error interface for UnknownFieldsError.
+func (e *UnknownFieldsError) Error() string {
+	return fmt.Sprintf("unknown %s fields: %s", e.QueryType, strings.Join(e.Fields, ", "))
+}
+
+// InvalidBuildStatusError is returned when a build's Status is not one Rally
+// accepts: SUCCESS, FAILURE, INCOMPLETE or UNKNOWN.
+type InvalidBuildStatusError struct {
+	// Status is the rejected value
+	Status string
+}
+
+// Error implements the error interface for InvalidBuildStatusError.
+func (e *InvalidBuildStatusError) Error() string {
+	return fmt.Sprintf("invalid build status %q: must be one of SUCCESS, FAILURE, INCOMPLETE, UNKNOWN", e.Status)
+}
+
+// InvalidScheduleStateError is returned when a ScheduleState is not one of
+// Defined, In-Progress, Completed or Accepted.
+type InvalidScheduleStateError struct {
+	// State is the rejected value
+	State string
+}
+
+// Error implements the error interface for InvalidScheduleStateError.
+func (e *InvalidScheduleStateError) Error() string {
+	return fmt.Sprintf("invalid schedule state %q: must be one of Defined, In-Progress, Completed, Accepted", e.State)
+}
+
+// InvalidDefectStateError is returned when a defect State is not in the list
+// of states TransitionDefect allows.
+type InvalidDefectStateError struct {
+	// State is the rejected value
+	State string
+	// Allowed lists the states that would have been accepted
+	Allowed []string
+}
+
+// Error implements the error interface for InvalidDefectStateError.
+func (e *InvalidDefectStateError) Error() string {
+	return fmt.Sprintf("invalid defect state %q: must be one of %s", e.State, strings.Join(e.Allowed, ", "))
+}
+
+// PaginationError is returned by QueryAll and QueryAllResults when paging
+// stops before every result has been delivered. Each page is retried on its
+// own like any request, so it is only returned once that page has used up
+// its retries.
+type PaginationError struct {
+	// Start is the 1-based start index of the page that failed. Pass it as
+	// QueryOptions.Start to QueryAllWithOptions or QueryStream to resume from
+	// that page instead of starting over.
+	Start int
+	// Delivered is how many results were delivered before the failure
+	Delivered int
+	// Duplicates is how many results were dropped before the failure because
+	// an earlier page had already returned them
+	Duplicates int
+	// Err is the underlying error
+	Err error
+}
+
+// Error implements the error interface for PaginationError.
+func (e *PaginationError) Error() string {
+	return fmt.Sprintf("query failed at start %d after %d results: %v", e.Start, e.Delivered, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *PaginationError) Unwrap() error {
+	return e.Err
+}
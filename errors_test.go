@@ -127,6 +127,12 @@ func TestParseRallyError(t *testing.T) {
 			body:           `{"OperationResult": {"Errors": ["Invalid field", "Missing value"], "Warnings": []}}`,
 			expectedErrors: []string{"Invalid field", "Missing value"},
 		},
+		{
+			name:           "OperationalResult with errors",
+			statusCode:     400,
+			body:           `{"OperationalResult": {"Errors": ["Update failed"], "Warnings": []}}`,
+			expectedErrors: []string{"Update failed"},
+		},
 		{
 			name:           "CreateResult with errors",
 			statusCode:     400,
@@ -171,3 +177,47 @@ func TestParseRallyError(t *testing.T) {
 		})
 	}
 }
+
+func TestCheckJSONResponse(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		wantErr     bool
+	}{
+		{"json", "application/json", `{"QueryResult": {}}`, false},
+		{"json without content type", "", `{"QueryResult": {}}`, false},
+		{"json with text/plain", "text/plain", `{"QueryResult": {}}`, false},
+		{"empty body", "text/html", "", false},
+		{"html", "text/html; charset=utf-8", "<html></html>", true},
+		{"html without content type", "", "  <html></html>", true},
+		{"text", "text/plain", "Service Unavailable", true},
+		{"truncated json", "application/json", `{"QueryResult": `, false},
+	}
+
+	for _, tt := range tests {
+		err := checkJSONResponse(tt.contentType, []byte(tt.body))
+		if tt.wantErr != errors.Is(err, ErrNonJSONResponse) {
+			t.Errorf("%s: expected error=%v, got %v", tt.name, tt.wantErr, err)
+		}
+	}
+}
+
+func TestRallyAPIError_IsConcurrentModification(t *testing.T) {
+	tests := []struct {
+		errors   []string
+		expected bool
+	}{
+		{[]string{"Concurrency conflict: [Object has been modified since being read for update in this context]"}, true},
+		{[]string{"Object has been modified since being read for update"}, true},
+		{[]string{"Could not read: Cannot find object"}, false},
+		{nil, false},
+	}
+
+	for _, tt := range tests {
+		err := &RallyAPIError{StatusCode: 200, Errors: tt.errors}
+		if got := errors.Is(err, ErrConcurrentModification); got != tt.expected {
+			t.Errorf("%v: expected %v, got %v", tt.errors, tt.expected, got)
+		}
+	}
+}
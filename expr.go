@@ -0,0 +1,298 @@
+/**
+* Copyright 2014 Comcast Cable Communications Management, LLC
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+* http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package rallyresttoolkit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/aleksofficial/go-rally-rest-toolkit/models"
+)
+
+// ErrInvalidQuery is returned when a query expression cannot be rendered,
+// e.g. because a condition has an empty field name.
+var ErrInvalidQuery = errors.New("invalid query")
+
+// Expr is a query expression in Rally's fully parenthesized syntax, built from
+// conditions such as Eq, Ge or Contains and combined with And and Or, e.g.
+//
+//	Eq("ScheduleState", "Accepted").Or(Eq("ScheduleState", "Completed")).And(Eq("Blocked", "false"))
+//
+// renders ((( ScheduleState = Accepted ) OR ( ScheduleState = Completed )) AND ( Blocked = false )).
+// Values are quoted as in QueryRequest. The zero Expr matches everything.
+type Expr struct {
+	text string
+	err  error
+}
+
+// Eq matches objects whose field equals value. Use Null for fields with no value.
+func Eq(field string, value string) Expr {
+	return condition(field, "=", value)
+}
+
+// Ne matches objects whose field does not equal value.
+func Ne(field string, value string) Expr {
+	return condition(field, "!=", value)
+}
+
+// Gt matches objects whose field is greater than value, e.g. a later date.
+func Gt(field string, value string) Expr {
+	return condition(field, ">", value)
+}
+
+// Ge matches objects whose field is greater than or equal to value.
+func Ge(field string, value string) Expr {
+	return condition(field, ">=", value)
+}
+
+// Lt matches objects whose field is less than value.
+func Lt(field string, value string) Expr {
+	return condition(field, "<", value)
+}
+
+// Le matches objects whose field is less than or equal to value.
+func Le(field string, value string) Expr {
+	return condition(field, "<=", value)
+}
+
+// Contains matches objects whose text field contains value.
+func Contains(field string, value string) Expr {
+	return condition(field, "contains", value)
+}
+
+// NameContainsAnyCase matches objects whose text field contains value in any
+// of its common capitalizations, for searches such as "login" that should
+// also find "Login" or "LOGIN". Rally's contains is case-sensitive for some
+// fields, so the value is expanded into an OR of Contains conditions for the
+// value as given and its lower case, upper case, title case ("Login Page")
+// and sentence case ("Login page") forms, without duplicates.
+func NameContainsAnyCase(field string, value string) Expr {
+	var expr Expr
+	for _, variant := range caseVariants(value) {
+		expr = expr.Or(Contains(field, variant))
+	}
+	if expr.IsZero() {
+		return Contains(field, value)
+	}
+	return expr
+}
+
+// caseVariants returns value followed by its lower, upper, title and sentence
+// case forms, in that order and without duplicates.
+func caseVariants(value string) []string {
+	lower := strings.ToLower(value)
+	candidates := []string{value, lower, strings.ToUpper(value), titleCase(lower), sentenceCase(lower)}
+
+	variants := make([]string, 0, len(candidates))
+	seen := make(map[string]bool, len(candidates))
+	for _, candidate := range candidates {
+		if !seen[candidate] {
+			seen[candidate] = true
+			variants = append(variants, candidate)
+		}
+	}
+	return variants
+}
+
+// titleCase upper-cases the first letter of every space-separated word of s.
+func titleCase(s string) string {
+	runes := []rune(s)
+	for i, r := range runes {
+		if i == 0 || unicode.IsSpace(runes[i-1]) {
+			runes[i] = unicode.ToUpper(r)
+		}
+	}
+	return string(runes)
+}
+
+// sentenceCase upper-cases the first letter of s.
+func sentenceCase(s string) string {
+	runes := []rune(s)
+	if len(runes) > 0 {
+		runes[0] = unicode.ToUpper(runes[0])
+	}
+	return string(runes)
+}
+
+// IsNull matches objects whose field has no value, e.g. IsNull("Owner") for
+// unassigned work. The null keyword is never quoted; to match the literal
+// string "null" instead, pass it quoted: Eq("Name", `"null"`).
+func IsNull(field string) Expr {
+	return rawCondition(field, "=", "null")
+}
+
+// IsNotNull matches objects whose field has a value, e.g. IsNotNull("Iteration")
+// for scheduled work.
+func IsNotNull(field string) Expr {
+	return rawCondition(field, "!=", "null")
+}
+
+// After matches objects whose date field is later than t, e.g.
+// After("LastUpdateDate", since).
+func After(field string, t time.Time) Expr {
+	return rawCondition(field, ">", queryTime(t))
+}
+
+// Before matches objects whose date field is earlier than t.
+func Before(field string, t time.Time) Expr {
+	return rawCondition(field, "<", queryTime(t))
+}
+
+// Between matches objects whose date field is from from to to, both
+// inclusive. It records an error if to is before from.
+func Between(field string, from time.Time, to time.Time) Expr {
+	if to.Before(from) {
+		return Expr{err: fmt.Errorf("%w: %s range ends before it starts", ErrInvalidQuery, field)}
+	}
+	return rawCondition(field, ">=", queryTime(from)).And(rawCondition(field, "<=", queryTime(to)))
+}
+
+// Rally's relative date tokens, compared like dates, e.g.
+// Ge("LastUpdateDate", Today). Rally resolves them in the workspace's time
+// zone when the query runs. Like Null they are never quoted.
+const (
+	Today     = "today"
+	Yesterday = "yesterday"
+	Tomorrow  = "tomorrow"
+)
+
+// DaysAgo returns the relative date token for n days before today, e.g.
+// Ge("LastUpdateDate", DaysAgo(7)) renders ( LastUpdateDate >= today-7 ).
+func DaysAgo(n int) string {
+	return relativeDay(-n)
+}
+
+// DaysFromNow returns the relative date token for n days after today, e.g.
+// Le("TargetDate", DaysFromNow(14)).
+func DaysFromNow(n int) string {
+	return relativeDay(n)
+}
+
+// relativeDay renders today offset by days.
+func relativeDay(days int) string {
+	switch {
+	case days > 0:
+		return fmt.Sprintf("%s+%d", Today, days)
+	case days < 0:
+		return fmt.Sprintf("%s-%d", Today, -days)
+	default:
+		return Today
+	}
+}
+
+// queryTime renders t for a query the way dates are written: in UTC, in
+// models.TimeFormat, truncated to the millisecond and unquoted.
+func queryTime(t time.Time) string {
+	return t.UTC().Format(models.TimeFormat)
+}
+
+// RefValue is a reference to another object used as a query value, such as
+// the Iteration or Owner of an artifact. It may be a short "/iteration/12345"
+// ref or a full _ref URL; either renders as the unquoted /type/id path Rally
+// expects.
+type RefValue string
+
+// path returns the /type/id form of the ref.
+func (r RefValue) path() (string, error) {
+	queryType, objectID, err := parseRef(string(r))
+	if err != nil {
+		return "", err
+	}
+	return "/" + queryType + "/" + objectID, nil
+}
+
+// RefEq matches objects whose field references the object ref points at, e.g.
+// RefEq("Iteration", RefValue(iteration.Ref)).
+func RefEq(field string, ref RefValue) Expr {
+	path, err := ref.path()
+	if err != nil {
+		return Expr{err: err}
+	}
+	return rawCondition(field, "=", path)
+}
+
+// condition renders a single ( field op value ) comparison, quoting value as
+// needed.
+func condition(field string, op string, value string) Expr {
+	return rawCondition(field, op, quoteQueryValue(value))
+}
+
+// rawCondition renders a single ( field op value ) comparison with value as is.
+func rawCondition(field string, op string, value string) Expr {
+	field = strings.TrimSpace(field)
+	if field == "" {
+		return Expr{err: fmt.Errorf("%w: empty field name in %s condition", ErrInvalidQuery, op)}
+	}
+	return Expr{text: fmt.Sprintf("( %s %s %s )", field, op, value)}
+}
+
+// And matches objects matching both e and other.
+func (e Expr) And(other Expr) Expr {
+	return e.join("AND", other)
+}
+
+// Or matches objects matching either e or other.
+func (e Expr) Or(other Expr) Expr {
+	return e.join("OR", other)
+}
+
+// join combines two expressions into one parenthesized group. Rally's AND and
+// OR are binary, so chains nest to the left.
+func (e Expr) join(op string, other Expr) Expr {
+	if e.err != nil {
+		return e
+	}
+	if other.err != nil {
+		return other
+	}
+	if e.IsZero() {
+		return other
+	}
+	if other.IsZero() {
+		return e
+	}
+	return Expr{text: fmt.Sprintf("(%s %s %s)", e.text, op, other.text)}
+}
+
+// IsZero reports whether e has no conditions.
+func (e Expr) IsZero() bool {
+	return e.text == "" && e.err == nil
+}
+
+// Validate returns the first error recorded while building e.
+func (e Expr) Validate() error {
+	return e.err
+}
+
+// String returns the rendered expression. Call Validate first; String
+// returns "" for an invalid expression.
+func (e Expr) String() string {
+	return e.text
+}
+
+// QueryRequestExpr - QueryRequestWithOptions for a query expression, for
+// queries such as ORs and grouped conditions that a map cannot express.
+func (s *RallyClient) QueryRequestExpr(ctx context.Context, expr Expr, queryType string, opts QueryOptions, output interface{}) error {
+	if err := expr.Validate(); err != nil {
+		return err
+	}
+	return s.QueryRequestRaw(ctx, expr.String(), queryType, opts, output)
+}
@@ -0,0 +1,280 @@
+/**
+* Copyright 2014 Comcast Cable Communications Management, LLC
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+* http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package rallyresttoolkit_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	. "github.com/aleksofficial/go-rally-rest-toolkit"
+	"github.com/aleksofficial/go-rally-rest-toolkit/fakes"
+)
+
+func TestExpr_String(t *testing.T) {
+	accepted := Eq("ScheduleState", "Accepted")
+	completed := Eq("ScheduleState", "Completed")
+
+	tests := []struct {
+		name     string
+		expr     Expr
+		expected string
+	}{
+		{"single condition", accepted, "( ScheduleState = Accepted )"},
+		{"or", accepted.Or(completed), "(( ScheduleState = Accepted ) OR ( ScheduleState = Completed ))"},
+		{"and", accepted.And(Eq("Blocked", "false")), "(( ScheduleState = Accepted ) AND ( Blocked = false ))"},
+		{
+			"or then and",
+			accepted.Or(completed).And(Eq("Blocked", "false")),
+			"((( ScheduleState = Accepted ) OR ( ScheduleState = Completed )) AND ( Blocked = false ))",
+		},
+		{
+			"and of two groups",
+			accepted.Or(completed).And(Eq("Owner", Null).Or(Eq("Blocked", "true"))),
+			"((( ScheduleState = Accepted ) OR ( ScheduleState = Completed )) AND (( Owner = null ) OR ( Blocked = true )))",
+		},
+		{
+			"three ors nest left",
+			Eq("Priority", "High").Or(Eq("Priority", "Urgent")).Or(Eq("Severity", "Crash/Data Loss")),
+			`((( Priority = High ) OR ( Priority = Urgent )) OR ( Severity = "Crash/Data Loss" ))`,
+		},
+		{"zero and condition", Expr{}.And(accepted), "( ScheduleState = Accepted )"},
+		{"zero", Expr{}, ""},
+	}
+
+	for _, tt := range tests {
+		if err := tt.expr.Validate(); err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.name, err)
+		}
+		if got := tt.expr.String(); got != tt.expected {
+			t.Errorf("%s: expected %s, got %s", tt.name, tt.expected, got)
+		}
+	}
+}
+
+func TestExpr_Operators(t *testing.T) {
+	tests := []struct {
+		name     string
+		expr     Expr
+		expected string
+	}{
+		{"equal", Eq("State", "Open"), "( State = Open )"},
+		{"not equal", Ne("State", "Closed"), "( State != Closed )"},
+		{"greater than", Gt("CreationDate", "2024-01-01"), "( CreationDate > 2024-01-01 )"},
+		{"greater or equal", Ge("PlanEstimate", "5"), "( PlanEstimate >= 5 )"},
+		{"less than", Lt("PlanEstimate", "8"), "( PlanEstimate < 8 )"},
+		{"less or equal", Le("TaskRemainingTotal", "0.5"), "( TaskRemainingTotal <= 0.5 )"},
+		{"contains", Contains("Name", "login"), "( Name contains login )"},
+		{"contains multi-word", Contains("Name", "login page"), `( Name contains "login page" )`},
+		{"not equal multi-word", Ne("Iteration.Name", "Sprint 3"), `( Iteration.Name != "Sprint 3" )`},
+	}
+
+	for _, tt := range tests {
+		if err := tt.expr.Validate(); err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.name, err)
+		}
+		if got := tt.expr.String(); got != tt.expected {
+			t.Errorf("%s: expected %s, got %s", tt.name, tt.expected, got)
+		}
+	}
+}
+
+func TestExpr_NullConditions(t *testing.T) {
+	tests := []struct {
+		name     string
+		expr     Expr
+		expected string
+	}{
+		{"is null", IsNull("Owner"), "( Owner = null )"},
+		{"is not null", IsNotNull("Iteration"), "( Iteration != null )"},
+		{"combined", IsNull("Owner").And(IsNotNull("Iteration")), "(( Owner = null ) AND ( Iteration != null ))"},
+		{"literal null string", Eq("Name", `"null"`), `( Name = "null" )`},
+	}
+
+	for _, tt := range tests {
+		if err := tt.expr.Validate(); err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.name, err)
+		}
+		if got := tt.expr.String(); got != tt.expected {
+			t.Errorf("%s: expected %s, got %s", tt.name, tt.expected, got)
+		}
+	}
+
+	if !errors.Is(IsNull("").Validate(), ErrInvalidQuery) {
+		t.Errorf("expected IsNull with an empty field name to be invalid")
+	}
+}
+
+func TestExpr_EmptyFieldName(t *testing.T) {
+	expr := Eq("ScheduleState", "Accepted").Or(Eq(" ", "Completed"))
+	if !errors.Is(expr.Validate(), ErrInvalidQuery) {
+		t.Errorf("expected ErrInvalidQuery, got %v", expr.Validate())
+	}
+}
+
+func TestQueryRequestExpr_SendsExpression(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{
+		FakeResponse: &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       &fakes.FakeResponseBody{Reader: bytes.NewBufferString(`{"QueryResult": { "TotalResultCount": 0, "Results": []}}`)},
+		},
+	}
+	rallyClient := New("abcdef", "http://myRallyUrl", fakeClient)
+
+	expr := Eq("ScheduleState", "Accepted").Or(Eq("ScheduleState", "Completed"))
+	fakeOutput := new(fakes.FakeOutput)
+	if err := rallyClient.QueryRequestExpr(context.Background(), expr, "hierarchicalrequirement", QueryOptions{PageSize: 100}, &fakeOutput); err != nil {
+		t.Fatalf("QueryRequestExpr failed unexpectedly: %v", err)
+	}
+
+	values := fakeClient.SpyRequest.URL.Query()
+	if got := values.Get("query"); got != expr.String() {
+		t.Errorf("expected query %s, got %s", expr, got)
+	}
+	if got := values.Get("pagesize"); got != "100" {
+		t.Errorf("expected pagesize=100, got %q", got)
+	}
+}
+
+func TestQueryRequestExpr_InvalidExpression(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{}
+	rallyClient := New("abcdef", "http://myRallyUrl", fakeClient)
+
+	fakeOutput := new(fakes.FakeOutput)
+	err := rallyClient.QueryRequestExpr(context.Background(), Eq("", "Accepted"), "defect", QueryOptions{}, &fakeOutput)
+	if !errors.Is(err, ErrInvalidQuery) {
+		t.Errorf("expected ErrInvalidQuery, got %v", err)
+	}
+	if fakeClient.CallCount != 0 {
+		t.Errorf("expected no HTTP call, got %d", fakeClient.CallCount)
+	}
+}
+
+func TestExpr_DateConditions(t *testing.T) {
+	// 2024-06-01T02:30:15.123456789+02:00 is 00:30:15.123 UTC.
+	cest := time.FixedZone("CEST", 2*60*60)
+	from := time.Date(2024, 6, 1, 2, 30, 15, 123456789, cest)
+	to := time.Date(2024, 6, 30, 23, 59, 59, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		expr     Expr
+		expected string
+	}{
+		{"after", After("LastUpdateDate", from), `( LastUpdateDate > 2024-06-01T00:30:15.123Z )`},
+		{"before", Before("CreationDate", to), `( CreationDate < 2024-06-30T23:59:59.000Z )`},
+		{"between", Between("LastUpdateDate", from, to), `(( LastUpdateDate >= 2024-06-01T00:30:15.123Z ) AND ( LastUpdateDate <= 2024-06-30T23:59:59.000Z ))`},
+	}
+	for _, tt := range tests {
+		if err := tt.expr.Validate(); err != nil {
+			t.Fatalf("%s: unexpected error %v", tt.name, err)
+		}
+		if got := tt.expr.String(); got != tt.expected {
+			t.Errorf("%s: expected %s, got %s", tt.name, tt.expected, got)
+		}
+	}
+}
+
+func TestExpr_BetweenReversedRange(t *testing.T) {
+	from := time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	if err := Between("LastUpdateDate", from, to).Validate(); !errors.Is(err, ErrInvalidQuery) {
+		t.Errorf("expected ErrInvalidQuery, got %v", err)
+	}
+}
+
+func TestRefEq(t *testing.T) {
+	tests := []struct {
+		name     string
+		ref      RefValue
+		expected string
+	}{
+		{"short", "/iteration/12345", `( Iteration = /iteration/12345 )`},
+		{"full", "https://rally1.rallydev.com/slm/webservice/v2.0/iteration/12345", `( Iteration = /iteration/12345 )`},
+		{"portfolio item", "https://rally1.rallydev.com/slm/webservice/v2.0/portfolioitem/feature/678.js", `( Iteration = /portfolioitem/feature/678 )`},
+	}
+	for _, tt := range tests {
+		expr := RefEq("Iteration", tt.ref)
+		if err := expr.Validate(); err != nil {
+			t.Fatalf("%s: unexpected error %v", tt.name, err)
+		}
+		if got := expr.String(); got != tt.expected {
+			t.Errorf("%s: expected %s, got %s", tt.name, tt.expected, got)
+		}
+	}
+
+	if err := RefEq("Owner", "999").Validate(); !errors.Is(err, ErrInvalidRef) {
+		t.Errorf("expected ErrInvalidRef for a bare ID, got %v", err)
+	}
+}
+
+func TestNameContainsAnyCase(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		expected string
+	}{
+		{
+			"mixed case",
+			"loGin page",
+			`((((( Name contains "loGin page" ) OR ( Name contains "login page" )) OR ( Name contains "LOGIN PAGE" )) OR ( Name contains "Login Page" )) OR ( Name contains "Login page" ))`,
+		},
+		{
+			"duplicates dropped",
+			"login",
+			"((( Name contains login ) OR ( Name contains LOGIN )) OR ( Name contains Login ))",
+		},
+		{"no letters", "42", "( Name contains 42 )"},
+		{"empty", "", `( Name contains "" )`},
+	}
+
+	for _, tt := range tests {
+		expr := NameContainsAnyCase("Name", tt.value)
+		if err := expr.Validate(); err != nil {
+			t.Fatalf("%s: unexpected error %v", tt.name, err)
+		}
+		if got := expr.String(); got != tt.expected {
+			t.Errorf("%s: expected %s, got %s", tt.name, tt.expected, got)
+		}
+	}
+}
+
+func TestExpr_RelativeDates(t *testing.T) {
+	tests := []struct {
+		name     string
+		expr     Expr
+		expected string
+	}{
+		{"today", Ge("LastUpdateDate", Today), "( LastUpdateDate >= today )"},
+		{"yesterday", Eq("CreationDate", Yesterday), "( CreationDate = yesterday )"},
+		{"tomorrow", Lt("TargetDate", Tomorrow), "( TargetDate < tomorrow )"},
+		{"days ago", Ge("LastUpdateDate", DaysAgo(7)), "( LastUpdateDate >= today-7 )"},
+		{"days from now", Le("TargetDate", DaysFromNow(14)), "( TargetDate <= today+14 )"},
+		{"zero days", Ge("LastUpdateDate", DaysAgo(0)), "( LastUpdateDate >= today )"},
+		{"negative days ago", Le("TargetDate", DaysAgo(-3)), "( TargetDate <= today+3 )"},
+		{"iso timestamp quoted", Ge("LastUpdateDate", "2024-06-01T00:00:00.000Z"), `( LastUpdateDate >= "2024-06-01T00:00:00.000Z" )`},
+	}
+
+	for _, tt := range tests {
+		if got := tt.expr.String(); got != tt.expected {
+			t.Errorf("%s: expected %s, got %s", tt.name, tt.expected, got)
+		}
+	}
+}
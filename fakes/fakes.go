@@ -19,6 +19,8 @@ package fakes
 import (
 	"io"
 	"net/http"
+	"sync"
+	"time"
 )
 
 type FakeOutput struct {
@@ -55,6 +57,8 @@ type FakeResult struct {
 //FakeResponseBody - a fake response body object
 type FakeResponseBody struct {
 	Reader io.Reader
+	// CloseCount tracks how many times Close was called (for leak testing)
+	CloseCount int
 }
 
 // Read implements io.Reader
@@ -63,7 +67,10 @@ func (f *FakeResponseBody) Read(p []byte) (n int, err error) {
 }
 
 //Close - close fake body
-func (FakeResponseBody) Close() error { return nil }
+func (f *FakeResponseBody) Close() error {
+	f.CloseCount++
+	return nil
+}
 
 //FakeRequestBody - a fake response body object
 type FakeRequestBody struct {
@@ -110,3 +117,30 @@ func (s *FakeHTTPClient) Do(fakeRequest *http.Request) (*http.Response, error) {
 
 	return s.FakeResponse, s.FakeError
 }
+
+//FakeClock - a fake clock whose After fires immediately, advancing Now by the
+//requested duration, so retry waits take no real time
+type FakeClock struct {
+	mu      sync.Mutex
+	Current time.Time
+	// Sleeps records every duration passed to After, in order
+	Sleeps []time.Duration
+}
+
+// Now - returns the fake current time
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.Current
+}
+
+// After - records d, advances the clock by it and returns a fired channel
+func (c *FakeClock) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.Sleeps = append(c.Sleeps, d)
+	c.Current = c.Current.Add(d)
+	ch := make(chan time.Time, 1)
+	ch <- c.Current
+	return ch
+}
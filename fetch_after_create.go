@@ -0,0 +1,74 @@
+/**
+* Copyright 2014 Comcast Cable Communications Management, LLC
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+* http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package rallyresttoolkit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+)
+
+// createAndFetch sends a create, then fetches the created object by ObjectID
+// and decodes the create response into output with the fetched object in
+// place of the echoed one. If the fetch fails, output holds the create
+// response as Rally returned it and the error says the object was created.
+func (s *RallyClient) createAndFetch(ctx context.Context, queryType string, baseURL *url.URL, body []byte, output interface{}) error {
+	var created json.RawMessage
+	if err := s.execute(ctx, "POST", baseURL, body, &created); err != nil {
+		return err
+	}
+
+	var envelope map[string]json.RawMessage
+	var result map[string]json.RawMessage
+	var object struct{ ObjectID int64 }
+	if err := json.Unmarshal(created, &envelope); err != nil {
+		return fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	if err := json.Unmarshal(envelope["CreateResult"], &result); err != nil {
+		return fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	if err := json.Unmarshal(result["Object"], &object); err != nil || object.ObjectID == 0 {
+		return s.decodeCreated(created, output, fmt.Errorf("created %s has no ObjectID to fetch", queryType))
+	}
+
+	objectID := strconv.FormatInt(object.ObjectID, 10)
+	fetched, err := Get[json.RawMessage](ctx, s, queryType, "", objectID)
+	if err != nil {
+		return s.decodeCreated(created, output, fmt.Errorf("created %s %s but failed to fetch it: %w", queryType, objectID, err))
+	}
+
+	result["Object"] = fetched
+	if envelope["CreateResult"], err = json.Marshal(result); err != nil {
+		return fmt.Errorf("failed to marshal response: %w", err)
+	}
+	hydrated, err := json.Marshal(envelope)
+	if err != nil {
+		return fmt.Errorf("failed to marshal response: %w", err)
+	}
+	return s.decodeCreated(hydrated, output, nil)
+}
+
+// decodeCreated decodes a create response into output and returns fetchErr,
+// unless decoding fails.
+func (s *RallyClient) decodeCreated(content []byte, output interface{}, fetchErr error) error {
+	if err := s.decode(content, output); err != nil {
+		return fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	return fetchErr
+}
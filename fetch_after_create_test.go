@@ -0,0 +1,96 @@
+/**
+* Copyright 2014 Comcast Cable Communications Management, LLC
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+* http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package rallyresttoolkit_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	. "github.com/aleksofficial/go-rally-rest-toolkit"
+	"github.com/aleksofficial/go-rally-rest-toolkit/fakes"
+	"github.com/aleksofficial/go-rally-rest-toolkit/models"
+)
+
+const createdDefectResponse = `{"CreateResult": {"Errors": [], "Warnings": [], "Object": {"ObjectID": 42, "FormattedID": "DE42", "Name": "Crash"}}}`
+
+func TestCreateDefect_FetchAfterCreate(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{
+		FakeResponses: []*http.Response{
+			jsonResponse(createdDefectResponse),
+			jsonResponse(`{"Defect": {"ObjectID": 42, "FormattedID": "DE42", "Name": "Crash", "State": "Submitted", "Severity": "Minor Problem"}}`),
+		},
+	}
+	rallyClient, err := NewClient(WithHTTPClient(fakeClient), WithFetchAfterCreate())
+	if err != nil {
+		t.Fatalf("NewClient failed unexpectedly: %v", err)
+	}
+
+	defect, err := NewDefect(rallyClient).CreateDefect(context.Background(), models.Defect{Name: "Crash"})
+	if err != nil {
+		t.Fatalf("CreateDefect failed unexpectedly: %v", err)
+	}
+	if defect.ObjectID != 42 || defect.State != "Submitted" || defect.Severity != "Minor Problem" {
+		t.Errorf("expected the fetched defect, got %+v", defect)
+	}
+	if fakeClient.CallCount != 2 {
+		t.Fatalf("expected a create and a fetch, got %d calls", fakeClient.CallCount)
+	}
+	req := fakeClient.SpyRequest
+	if req.Method != "GET" || !strings.HasSuffix(req.URL.Path, "/defect/42") {
+		t.Errorf("expected GET of the new defect, got %s %s", req.Method, req.URL.Path)
+	}
+}
+
+func TestCreateDefect_NoFetchByDefault(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{FakeResponse: jsonResponse(createdDefectResponse)}
+	rallyClient := New("abcdef", "http://myRallyUrl", fakeClient)
+
+	defect, err := NewDefect(rallyClient).CreateDefect(context.Background(), models.Defect{Name: "Crash"})
+	if err != nil {
+		t.Fatalf("CreateDefect failed unexpectedly: %v", err)
+	}
+	if defect.FormattedID != "DE42" || defect.State != "" {
+		t.Errorf("expected the echoed defect, got %+v", defect)
+	}
+	if fakeClient.CallCount != 1 {
+		t.Errorf("expected a single call, got %d", fakeClient.CallCount)
+	}
+}
+
+func TestCreateDefect_FetchAfterCreateFails(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{
+		FakeResponses: []*http.Response{
+			jsonResponse(createdDefectResponse),
+			errorResponse(http.StatusNotFound),
+		},
+	}
+	rallyClient, err := NewClient(WithHTTPClient(fakeClient), WithFetchAfterCreate())
+	if err != nil {
+		t.Fatalf("NewClient failed unexpectedly: %v", err)
+	}
+
+	defect, err := NewDefect(rallyClient).CreateDefect(context.Background(), models.Defect{Name: "Crash"})
+	if !errors.Is(err, ErrRallyAPI) || !strings.Contains(err.Error(), "created defect 42") {
+		t.Fatalf("expected a fetch error noting the create, got %v", err)
+	}
+	if defect.FormattedID != "DE42" {
+		t.Errorf("expected the echoed defect to be returned, got %+v", defect)
+	}
+}
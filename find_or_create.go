@@ -0,0 +1,44 @@
+/**
+* Copyright 2014 Comcast Cable Communications Management, LLC
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+* http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package rallyresttoolkit
+
+import (
+	"context"
+	"fmt"
+)
+
+// findOrCreate returns the first object of queryType matching matchQuery,
+// lowest ObjectID first, or the one create makes when none does. created
+// reports which. An empty matchQuery is rejected, since it would match any
+// object. The query and the create are not atomic: two callers racing on the
+// same match can both create.
+func findOrCreate[T any](ctx context.Context, client *RallyClient, queryType string, matchQuery map[string]string, create func(context.Context) (T, error)) (result T, created bool, err error) {
+	if len(matchQuery) == 0 {
+		return result, false, fmt.Errorf("%w: empty match query", ErrInvalidQuery)
+	}
+
+	page, err := queryPage[T](ctx, client, matchQuery, queryType, QueryOptions{Order: "ObjectID", PageSize: 1})
+	if err != nil {
+		return result, false, err
+	}
+	if len(page.Results) > 0 {
+		return page.Results[0], false, nil
+	}
+
+	result, err = create(ctx)
+	return result, err == nil, err
+}
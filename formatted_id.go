@@ -0,0 +1,99 @@
+/**
+* Copyright 2014 Comcast Cable Communications Management, LLC
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+* http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package rallyresttoolkit
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/aleksofficial/go-rally-rest-toolkit/models"
+)
+
+// resolveFormattedID looks up the ObjectID of the single object of queryType
+// with the given FormattedID, e.g. "DE1234". It returns ErrNotFound when
+// nothing matches and an *AmbiguousFormattedIDError when several objects do.
+func (s *RallyClient) resolveFormattedID(ctx context.Context, formattedID string, queryType string) (string, error) {
+	result, err := s.queryObjectIDs(ctx, map[string]string{"FormattedID": formattedID}, queryType, QueryOptions{}, 2)
+	if err != nil {
+		return "", err
+	}
+
+	// The results decide; TotalResultCount only sizes an ambiguity, since it
+	// can disagree with an empty page.
+	switch {
+	case len(result.Results) == 0:
+		return "", fmt.Errorf("%s %s: %w", queryType, formattedID, ErrNotFound)
+	case len(result.Results) > 1:
+		matches := max(result.TotalResultCount, len(result.Results))
+		return "", &AmbiguousFormattedIDError{QueryType: queryType, FormattedID: formattedID, Matches: matches}
+	}
+	return strconv.Itoa(result.Results[0].ObjectID), nil
+}
+
+// GetByFormattedID fetches the artifact with a bare FormattedID, such as
+// "US1234", into a typed model, choosing the endpoint from its prefix with
+// models.ArtifactTypeForFormattedID. Only Rally's default prefixes are known;
+// for renamed ones use the typed clients' ByFormattedID methods.
+func GetByFormattedID[T any](ctx context.Context, client *RallyClient, formattedID string) (T, error) {
+	var zero T
+	artifactType, err := models.ArtifactTypeForFormattedID(formattedID)
+	if err != nil {
+		return zero, err
+	}
+	objectID, err := client.resolveFormattedID(ctx, formattedID, artifactType)
+	if err != nil {
+		return zero, err
+	}
+	return Get[T](ctx, client, artifactType, "", objectID)
+}
+
+// GetDefectByFormattedID - GetDefect for a FormattedID such as "DE1234"
+func (s *Defect) GetDefectByFormattedID(ctx context.Context, formattedID string) (models.Defect, error) {
+	objectID, err := s.client.resolveFormattedID(ctx, formattedID, "defect")
+	if err != nil {
+		return models.Defect{}, err
+	}
+	return s.GetDefect(ctx, objectID)
+}
+
+// GetHierarchicalRequirementByFormattedID - GetHierarchicalRequirement for a FormattedID such as "US1234"
+func (s *HierarchicalRequirement) GetHierarchicalRequirementByFormattedID(ctx context.Context, formattedID string) (models.HierarchicalRequirement, error) {
+	objectID, err := s.client.resolveFormattedID(ctx, formattedID, "HierarchicalRequirement")
+	if err != nil {
+		return models.HierarchicalRequirement{}, err
+	}
+	return s.GetHierarchicalRequirement(ctx, objectID)
+}
+
+// GetPortfolioItemByFormattedID - GetPortfolioItem for a FormattedID such as "F1234"
+func (s *PortfolioItem) GetPortfolioItemByFormattedID(ctx context.Context, formattedID string) (models.PortfolioItem, error) {
+	objectID, err := s.client.resolveFormattedID(ctx, formattedID, s.queryType)
+	if err != nil {
+		return models.PortfolioItem{}, err
+	}
+	return s.GetPortfolioItem(ctx, objectID)
+}
+
+// GetTaskByFormattedID - GetTask for a FormattedID such as "TA1234"
+func (s *Task) GetTaskByFormattedID(ctx context.Context, formattedID string) (models.Task, error) {
+	objectID, err := s.client.resolveFormattedID(ctx, formattedID, "task")
+	if err != nil {
+		return models.Task{}, err
+	}
+	return s.GetTask(ctx, objectID)
+}
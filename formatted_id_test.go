@@ -0,0 +1,158 @@
+/**
+* Copyright 2014 Comcast Cable Communications Management, LLC
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+* http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package rallyresttoolkit_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+
+	. "github.com/aleksofficial/go-rally-rest-toolkit"
+	"github.com/aleksofficial/go-rally-rest-toolkit/fakes"
+	"github.com/aleksofficial/go-rally-rest-toolkit/models"
+)
+
+func jsonResponse(body string) *http.Response {
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Body:       &fakes.FakeResponseBody{Reader: bytes.NewBufferString(body)},
+	}
+}
+
+func TestGetDefectByFormattedID(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{
+		FakeResponses: []*http.Response{
+			jsonResponse(`{"QueryResult": {"TotalResultCount": 1, "Results": [{"ObjectID": 4242}]}}`),
+			jsonResponse(`{"Defect": {"ObjectID": 4242, "FormattedID": "DE1234", "Name": "Crash on save"}}`),
+		},
+	}
+	defect := NewDefect(New("abcdef", "http://myRallyUrl", fakeClient))
+
+	de, err := defect.GetDefectByFormattedID(context.Background(), "DE1234")
+	if err != nil {
+		t.Fatalf("GetDefectByFormattedID failed unexpectedly: %v", err)
+	}
+	if de.ObjectID != 4242 || de.Name != "Crash on save" {
+		t.Errorf("unexpected defect: %+v", de)
+	}
+	if fakeClient.CallCount != 2 {
+		t.Errorf("expected a query and a GET, got %d calls", fakeClient.CallCount)
+	}
+	if got := fakeClient.SpyRequest.URL.String(); got != "http://myRallyUrl/defect/4242?fetch=true" {
+		t.Errorf("expected a full GET by ObjectID, got %s", got)
+	}
+}
+
+func TestGetTaskByFormattedID_NotFound(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{
+		FakeResponse: jsonResponse(`{"QueryResult": {"TotalResultCount": 0, "Results": []}}`),
+	}
+	task := NewTask(New("abcdef", "http://myRallyUrl", fakeClient))
+
+	_, err := task.GetTaskByFormattedID(context.Background(), "TA99")
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+	expected := "fetch=ObjectID&pagesize=2&query=%28+FormattedID+%3D+TA99+%29"
+	if got := fakeClient.SpyRequest.URL.RawQuery; got != expected {
+		t.Errorf("expected query %q, got %q", expected, got)
+	}
+	if fakeClient.CallCount != 1 {
+		t.Errorf("expected no GET after zero matches, got %d calls", fakeClient.CallCount)
+	}
+}
+
+func TestGetHierarchicalRequirementByFormattedID_Ambiguous(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{
+		FakeResponse: jsonResponse(`{"QueryResult": {"TotalResultCount": 3, "Results": [{"ObjectID": 1}, {"ObjectID": 2}]}}`),
+	}
+	hr := NewHierarchicalRequirement(New("abcdef", "http://myRallyUrl", fakeClient))
+
+	_, err := hr.GetHierarchicalRequirementByFormattedID(context.Background(), "US7")
+	var ambiguous *AmbiguousFormattedIDError
+	if !errors.As(err, &ambiguous) {
+		t.Fatalf("expected *AmbiguousFormattedIDError, got %v", err)
+	}
+	if ambiguous.FormattedID != "US7" || ambiguous.Matches != 3 {
+		t.Errorf("unexpected error fields: %+v", ambiguous)
+	}
+}
+
+func TestGetByFormattedID_ChoosesEndpointFromPrefix(t *testing.T) {
+	tests := []struct {
+		formattedID string
+		queryPath   string
+		body        string
+	}{
+		{"US12", "/hierarchicalrequirement", `{"HierarchicalRequirement": {"ObjectID": 4242, "Name": "Login"}}`},
+		{"de12", "/defect", `{"Defect": {"ObjectID": 4242, "Name": "Login"}}`},
+		{"F12", "/portfolioitem/feature", `{"Feature": {"ObjectID": 4242, "Name": "Login"}}`},
+	}
+
+	for _, tt := range tests {
+		doer := &scriptedDoer{
+			statuses: []int{http.StatusOK, http.StatusOK},
+			bodies:   []string{`{"QueryResult": {"TotalResultCount": 1, "Results": [{"ObjectID": 4242}]}}`, tt.body},
+		}
+		client := New("abcdef", "http://myRallyUrl", doer)
+
+		artifact, err := GetByFormattedID[models.Artifact](context.Background(), client, tt.formattedID)
+		if err != nil {
+			t.Fatalf("%s: GetByFormattedID failed unexpectedly: %v", tt.formattedID, err)
+		}
+		if artifact.ObjectID != 4242 || artifact.Name != "Login" {
+			t.Errorf("%s: unexpected artifact: %+v", tt.formattedID, artifact)
+		}
+		expected := []string{"GET " + tt.queryPath, "GET " + tt.queryPath + "/4242"}
+		if !reflect.DeepEqual(doer.calls, expected) {
+			t.Errorf("%s: expected calls %v, got %v", tt.formattedID, expected, doer.calls)
+		}
+	}
+}
+
+func TestGetByFormattedID_UnknownPrefix(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{}
+	client := New("abcdef", "http://myRallyUrl", fakeClient)
+
+	for _, formattedID := range []string{"BUG12", "12", ""} {
+		_, err := GetByFormattedID[models.Artifact](context.Background(), client, formattedID)
+		if !errors.Is(err, models.ErrUnknownFormattedIDPrefix) && !errors.Is(err, models.ErrInvalidFormattedID) {
+			t.Errorf("%q: expected a FormattedID error, got %v", formattedID, err)
+		}
+	}
+	if fakeClient.CallCount != 0 {
+		t.Errorf("expected no requests, got %d", fakeClient.CallCount)
+	}
+}
+
+func TestGetDefectByFormattedID_CountWithoutResults(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{
+		FakeResponse: jsonResponse(`{"QueryResult": {"TotalResultCount": 1, "Results": []}}`),
+	}
+	defect := NewDefect(New("abcdef", "http://myRallyUrl", fakeClient))
+
+	_, err := defect.GetDefectByFormattedID(context.Background(), "DE1234")
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+	if fakeClient.CallCount != 1 {
+		t.Errorf("expected no GET without a result, got %d calls", fakeClient.CallCount)
+	}
+}
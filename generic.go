@@ -0,0 +1,74 @@
+/**
+* Copyright 2014 Comcast Cable Communications Management, LLC
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+* http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package rallyresttoolkit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// QueryMeta is the envelope metadata of a query page, without the results.
+type QueryMeta struct {
+	TotalResultCount int
+	// StartIndex is the 1-based index of the first result on the page
+	StartIndex int
+	PageSize   int
+	Errors     []string
+	Warnings   []string
+	// Duplicates is how many results paging dropped because an earlier page
+	// had already returned them; it is only set by QueryAllResultsWithMeta
+	Duplicates int
+}
+
+// Query runs a query against elementName, e.g. "defect", and decodes the
+// results of one page into T, which can be any struct shaped like the object,
+// including user-defined models with extra or custom-typed fields.
+func Query[T any](ctx context.Context, client *RallyClient, elementName string, query map[string]string, opts QueryOptions) ([]T, QueryMeta, error) {
+	page, err := queryPage[T](ctx, client, query, elementName, opts)
+	return page.Results, page.Meta(), err
+}
+
+// Get fetches one object of elementName by ObjectID and decodes the object
+// under responseKey, e.g. "Defect", into T. An empty responseKey picks the
+// key from elementName, ignoring case.
+func Get[T any](ctx context.Context, client *RallyClient, elementName string, responseKey string, objectID string) (T, error) {
+	var result T
+
+	envelope := map[string]json.RawMessage{}
+	if err := client.GetRequest(ctx, objectID, elementName, &envelope); err != nil {
+		return result, err
+	}
+
+	var body json.RawMessage
+	if responseKey != "" {
+		var ok bool
+		if body, ok = envelope[responseKey]; !ok {
+			return result, fmt.Errorf("response has no %s object", responseKey)
+		}
+	} else {
+		var err error
+		if body, err = singleObject(envelope, elementName); err != nil {
+			return result, err
+		}
+	}
+
+	if err := client.decode(body, &result); err != nil {
+		return result, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	return result, nil
+}
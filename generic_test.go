@@ -0,0 +1,80 @@
+/**
+* Copyright 2014 Comcast Cable Communications Management, LLC
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+* http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package rallyresttoolkit_test
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/aleksofficial/go-rally-rest-toolkit"
+	"github.com/aleksofficial/go-rally-rest-toolkit/fakes"
+)
+
+// teamDefect is a user-defined model with a custom field the stock
+// models.Defect does not have.
+type teamDefect struct {
+	ObjectID    int
+	FormattedID string
+	Team        string `json:"c_Team"`
+}
+
+func TestQuery_CustomModel(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{
+		FakeResponse: jsonResponse(`{"QueryResult": {"TotalResultCount": 2, "StartIndex": 1, "PageSize": 20, "Warnings": ["deprecated"], "Results": [
+			{"ObjectID": 1, "FormattedID": "DE1", "c_Team": "Payments"},
+			{"ObjectID": 2, "FormattedID": "DE2", "c_Team": "Search"}]}}`),
+	}
+	rallyClient := New("abcdef", "http://myRallyUrl", fakeClient)
+
+	defects, meta, err := Query[teamDefect](context.Background(), rallyClient, "defect", map[string]string{"State": "Open"}, QueryOptions{})
+	if err != nil {
+		t.Fatalf("Query failed unexpectedly: %v", err)
+	}
+	if len(defects) != 2 || defects[0].Team != "Payments" || defects[1].Team != "Search" {
+		t.Errorf("unexpected results: %+v", defects)
+	}
+	if meta.TotalResultCount != 2 || meta.StartIndex != 1 || meta.PageSize != 20 || len(meta.Warnings) != 1 {
+		t.Errorf("unexpected meta: %+v", meta)
+	}
+}
+
+func TestGet_CustomModel(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{
+		FakeResponse: jsonResponse(`{"Defect": {"ObjectID": 7, "FormattedID": "DE7", "c_Team": "Payments"}}`),
+	}
+	rallyClient := New("abcdef", "http://myRallyUrl", fakeClient)
+
+	defect, err := Get[teamDefect](context.Background(), rallyClient, "defect", "Defect", "7")
+	if err != nil {
+		t.Fatalf("Get failed unexpectedly: %v", err)
+	}
+	if defect.ObjectID != 7 || defect.Team != "Payments" {
+		t.Errorf("unexpected defect: %+v", defect)
+	}
+	if got := fakeClient.SpyRequest.URL.Path; got != "/defect/7" {
+		t.Errorf("unexpected path %s", got)
+	}
+}
+
+func TestGet_MissingResponseKey(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{FakeResponse: jsonResponse(`{"Defect": {"ObjectID": 7}}`)}
+	rallyClient := New("abcdef", "http://myRallyUrl", fakeClient)
+
+	if _, err := Get[teamDefect](context.Background(), rallyClient, "defect", "HierarchicalRequirement", "7"); err == nil {
+		t.Error("expected an error for a missing response key")
+	}
+}
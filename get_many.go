@@ -0,0 +1,116 @@
+/**
+* Copyright 2014 Comcast Cable Communications Management, LLC
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+* http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package rallyresttoolkit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/aleksofficial/go-rally-rest-toolkit/models"
+)
+
+// maxQueryLength bounds the length of the query clause GetManyByObjectID
+// sends in one request, keeping URLs well inside what Rally and proxies in
+// front of it accept.
+const maxQueryLength = 4000
+
+// GetManyByObjectID - fetches all objects of a type with the given ObjectIDs
+// using OR queries instead of one GET per object. Long lists are split into
+// several requests whose results are merged into output as a single
+// {"QueryResult": {...}} envelope, e.g. a *QueryDefectResponse. Like other
+// queries, the requests are scoped to Config.DefaultWorkspace and
+// DefaultProject when set. Objects that do not exist, or lie outside that
+// scope, are simply absent from the results.
+func (s *RallyClient) GetManyByObjectID(ctx context.Context, queryType string, ids []int, output interface{}) error {
+	merged := models.QueryResponse[json.RawMessage]{
+		QueryResult: models.QueryResult[json.RawMessage]{
+			Results:    []json.RawMessage{},
+			StartIndex: 1,
+		},
+	}
+
+	for _, chunk := range chunkObjectIDs(ids, maxQueryLength) {
+		var page models.QueryResponse[json.RawMessage]
+		if err := s.QueryRequestRaw(ctx, orChain("ObjectID", chunk), queryType, QueryOptions{PageSize: len(chunk)}, &page); err != nil {
+			return err
+		}
+		merged.QueryResult.Results = append(merged.QueryResult.Results, page.QueryResult.Results...)
+		merged.QueryResult.Errors = append(merged.QueryResult.Errors, page.QueryResult.Errors...)
+		merged.QueryResult.Warnings = append(merged.QueryResult.Warnings, page.QueryResult.Warnings...)
+	}
+	merged.QueryResult.TotalResultCount = len(merged.QueryResult.Results)
+	merged.QueryResult.PageSize = len(merged.QueryResult.Results)
+
+	content, err := json.Marshal(merged)
+	if err != nil {
+		return fmt.Errorf("failed to merge results: %w", err)
+	}
+	if err := s.decode(content, output); err != nil {
+		return fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	return nil
+}
+
+// chunkObjectIDs drops duplicate IDs and splits the rest into chunks whose OR
+// query stays within maxLength.
+func chunkObjectIDs(ids []int, maxLength int) [][]int {
+	var chunks [][]int
+	var chunk []int
+	length := 0
+	seen := make(map[int]bool, len(ids))
+	for _, id := range ids {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		// Each ID adds "(ObjectID = <id>)", plus "(", " OR " and ")" to nest
+		// it with the clauses before it.
+		clause := len("(ObjectID = )") + len(strconv.Itoa(id))
+		if len(chunk) > 0 {
+			clause += len("( OR )")
+		}
+		if len(chunk) > 0 && length+clause > maxLength {
+			chunks = append(chunks, chunk)
+			chunk, length = nil, 0
+			clause -= len("( OR )")
+		}
+		chunk = append(chunk, id)
+		length += clause
+	}
+	if len(chunk) > 0 {
+		chunks = append(chunks, chunk)
+	}
+	return chunks
+}
+
+// orChain builds a query matching any of ids. Rally only accepts binary
+// operators, so the clauses are nested: (((A) OR (B)) OR (C)).
+func orChain(field string, ids []int) string {
+	query := ""
+	for i, id := range ids {
+		clause := fmt.Sprintf("(%s = %d)", field, id)
+		if i == 0 {
+			query = clause
+			continue
+		}
+		query = fmt.Sprintf("(%s OR %s)", query, clause)
+	}
+	return query
+}
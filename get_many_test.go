@@ -0,0 +1,153 @@
+/**
+* Copyright 2014 Comcast Cable Communications Management, LLC
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+* http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package rallyresttoolkit_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	. "github.com/aleksofficial/go-rally-rest-toolkit"
+	"github.com/aleksofficial/go-rally-rest-toolkit/fakes"
+)
+
+// objectIDServer answers ObjectID OR queries with one defect per ID in the
+// query and records every query it receives.
+type objectIDServer struct {
+	queries []string
+}
+
+func (s *objectIDServer) Do(req *http.Request) (*http.Response, error) {
+	query := req.URL.Query().Get("query")
+	s.queries = append(s.queries, query)
+
+	var results []string
+	for _, part := range strings.Split(query, "(ObjectID = ")[1:] {
+		id, _, _ := strings.Cut(part, ")")
+		results = append(results, fmt.Sprintf(`{"ObjectID": %s}`, id))
+	}
+	body := fmt.Sprintf(`{"QueryResult": {"TotalResultCount": %d, "StartIndex": 1, "Results": [%s]}}`, len(results), strings.Join(results, ","))
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Body:       io.NopCloser(bytes.NewBufferString(body)),
+	}, nil
+}
+
+func TestGetManyByObjectID_SingleQuery(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{
+		FakeResponse: &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       &fakes.FakeResponseBody{Reader: bytes.NewBufferString(`{"QueryResult": {"TotalResultCount": 2, "Results": [{"ObjectID": 1}, {"ObjectID": 3}]}}`)},
+		},
+	}
+	rallyClient := New("abcdef", "http://myRallyUrl", fakeClient)
+
+	output := new(QueryDefectResponse)
+	if err := rallyClient.GetManyByObjectID(context.Background(), "defect", []int{1, 2, 3, 2}, output); err != nil {
+		t.Fatalf("GetManyByObjectID failed unexpectedly: %v", err)
+	}
+	if fakeClient.CallCount != 1 {
+		t.Errorf("expected 1 request, got %d", fakeClient.CallCount)
+	}
+	params := fakeClient.SpyRequest.URL.Query()
+	if got := params.Get("query"); got != "(((ObjectID = 1) OR (ObjectID = 2)) OR (ObjectID = 3))" {
+		t.Errorf("unexpected query: %s", got)
+	}
+	if got := params.Get("pagesize"); got != "3" {
+		t.Errorf("expected pagesize=3, got %s", got)
+	}
+	if output.QueryResult.TotalResultCount != 2 || len(output.QueryResult.Results) != 2 {
+		t.Errorf("unexpected results: %+v", output.QueryResult)
+	}
+}
+
+func TestGetManyByObjectID_AppliesDefaultScope(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{
+		FakeResponse: &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       &fakes.FakeResponseBody{Reader: bytes.NewBufferString(`{"QueryResult": {"TotalResultCount": 1, "Results": [{"ObjectID": 1}]}}`)},
+		},
+	}
+	rallyClient, err := NewClient(
+		WithHTTPClient(fakeClient),
+		WithDefaultWorkspace("/workspace/1"),
+		WithDefaultProject("/project/2"),
+	)
+	if err != nil {
+		t.Fatalf("NewClient failed unexpectedly: %v", err)
+	}
+
+	output := new(QueryDefectResponse)
+	if err := rallyClient.GetManyByObjectID(context.Background(), "defect", []int{1}, output); err != nil {
+		t.Fatalf("GetManyByObjectID failed unexpectedly: %v", err)
+	}
+	params := fakeClient.SpyRequest.URL.Query()
+	if params.Get("workspace") != "/workspace/1" || params.Get("project") != "/project/2" {
+		t.Errorf("expected the default workspace and project, got %s", fakeClient.SpyRequest.URL.RawQuery)
+	}
+}
+
+func TestGetManyByObjectID_ChunksLongLists(t *testing.T) {
+	server := &objectIDServer{}
+	rallyClient := New("abcdef", "http://myRallyUrl", server)
+
+	ids := make([]int, 1000)
+	for i := range ids {
+		ids[i] = 50137325000 + i
+	}
+
+	output := new(QueryDefectResponse)
+	if err := rallyClient.GetManyByObjectID(context.Background(), "defect", ids, output); err != nil {
+		t.Fatalf("GetManyByObjectID failed unexpectedly: %v", err)
+	}
+	if len(server.queries) < 2 {
+		t.Fatalf("expected the IDs to be split across several requests, got %d", len(server.queries))
+	}
+	for i, query := range server.queries {
+		if len(query) > 4000 {
+			t.Errorf("query %d is %d characters long", i, len(query))
+		}
+	}
+	if output.QueryResult.TotalResultCount != len(ids) || len(output.QueryResult.Results) != len(ids) {
+		t.Fatalf("expected %d merged results, got %d", len(ids), len(output.QueryResult.Results))
+	}
+	for i, defect := range output.QueryResult.Results {
+		if defect.ObjectID != ids[i] {
+			t.Fatalf("result %d: expected ObjectID=%d, got %d", i, ids[i], defect.ObjectID)
+		}
+	}
+}
+
+func TestGetManyByObjectID_Empty(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{}
+	rallyClient := New("abcdef", "http://myRallyUrl", fakeClient)
+
+	output := new(QueryDefectResponse)
+	if err := rallyClient.GetManyByObjectID(context.Background(), "defect", nil, output); err != nil {
+		t.Fatalf("GetManyByObjectID failed unexpectedly: %v", err)
+	}
+	if fakeClient.CallCount != 0 {
+		t.Errorf("expected no requests, got %d", fakeClient.CallCount)
+	}
+	if len(output.QueryResult.Results) != 0 {
+		t.Errorf("expected no results, got %d", len(output.QueryResult.Results))
+	}
+}
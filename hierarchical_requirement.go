@@ -29,12 +29,7 @@ type HierarchicalRequirement struct {
 }
 
 // QueryHierarchicalRequirementResponse - struct to contain query response
-type QueryHierarchicalRequirementResponse struct {
-	QueryResult struct {
-		Results          []models.HierarchicalRequirement
-		TotalResultCount int
-	}
-}
+type QueryHierarchicalRequirementResponse = models.QueryResponse[models.HierarchicalRequirement]
 
 // GetHierarchicalRequirementResponse - Struct to contain response
 type GetHierarchicalRequirementResponse struct {
@@ -57,9 +52,7 @@ type HrResult struct {
 }
 
 // OperationResponse - struct to contain response
-type OperationResponse struct {
-	OperationalResult HrResult
-}
+type OperationResponse = models.OperationResponse[models.HierarchicalRequirement]
 
 // NewHierarchicalRequirement - creates new HierarchicalRequirement
 func NewHierarchicalRequirement(client *RallyClient) (hr *HierarchicalRequirement) {
@@ -75,6 +68,46 @@ func (s *HierarchicalRequirement) QueryHierarchicalRequirement(ctx context.Conte
 	return qhrs.QueryResult.Results, err
 }
 
+// QueryHierarchicalRequirementWithMeta - QueryRequest that also returns the envelope's paging metadata and Warnings
+func (s *HierarchicalRequirement) QueryHierarchicalRequirementWithMeta(ctx context.Context, query map[string]string) ([]models.HierarchicalRequirement, QueryMeta, error) {
+	return Query[models.HierarchicalRequirement](ctx, s.client, "HierarchicalRequirement", query, QueryOptions{})
+}
+
+// QueryHierarchicalRequirementPage - abstraction for QueryRequestWithOptions that keeps the paging metadata
+func (s *HierarchicalRequirement) QueryHierarchicalRequirementPage(ctx context.Context, query map[string]string, opts QueryOptions) (Page[models.HierarchicalRequirement], error) {
+	return queryPage[models.HierarchicalRequirement](ctx, s.client, query, "HierarchicalRequirement", opts)
+}
+
+// QueryHierarchicalRequirementRaw - abstraction for QueryRequestRaw, for query clauses written by hand
+func (s *HierarchicalRequirement) QueryHierarchicalRequirementRaw(ctx context.Context, rawQuery string, opts QueryOptions) (Page[models.HierarchicalRequirement], error) {
+	return queryRawPage[models.HierarchicalRequirement](ctx, s.client, rawQuery, "HierarchicalRequirement", opts)
+}
+
+// QueryHierarchicalRequirementWith - abstraction for QueryRequestWith
+func (s *HierarchicalRequirement) QueryHierarchicalRequirementWith(ctx context.Context, b *QueryBuilder) (Page[models.HierarchicalRequirement], error) {
+	return queryWithPage[models.HierarchicalRequirement](ctx, s.client, b, "HierarchicalRequirement")
+}
+
+// QueryHierarchicalRequirementByScheduleState - lists every story in the given schedule state, page by page
+func (s *HierarchicalRequirement) QueryHierarchicalRequirementByScheduleState(ctx context.Context, scheduleState string) ([]models.HierarchicalRequirement, error) {
+	return queryAllExprResults[models.HierarchicalRequirement](ctx, s.client, Eq("ScheduleState", scheduleState), "HierarchicalRequirement")
+}
+
+// QueryHierarchicalRequirementByOwner - lists every story owned by the user a ref points at
+func (s *HierarchicalRequirement) QueryHierarchicalRequirementByOwner(ctx context.Context, userRef string) ([]models.HierarchicalRequirement, error) {
+	return queryAllExprResults[models.HierarchicalRequirement](ctx, s.client, RefEq("Owner", RefValue(userRef)), "HierarchicalRequirement")
+}
+
+// QueryHierarchicalRequirementByIteration - lists every story scheduled in the iteration a ref points at
+func (s *HierarchicalRequirement) QueryHierarchicalRequirementByIteration(ctx context.Context, iterationRef string) ([]models.HierarchicalRequirement, error) {
+	return queryAllExprResults[models.HierarchicalRequirement](ctx, s.client, RefEq("Iteration", RefValue(iterationRef)), "HierarchicalRequirement")
+}
+
+// FindHierarchicalRequirementsByName - lists every hierarchical requirement whose Name contains name, ignoring case
+func (s *HierarchicalRequirement) FindHierarchicalRequirementsByName(ctx context.Context, name string) ([]models.HierarchicalRequirement, error) {
+	return queryAllExprResults[models.HierarchicalRequirement](ctx, s.client, NameContainsAnyCase("Name", name), "hierarchicalrequirement")
+}
+
 // GetHierarchicalRequirement - abstraction for GetRequest
 func (s *HierarchicalRequirement) GetHierarchicalRequirement(ctx context.Context, objectID string) (hr models.HierarchicalRequirement, err error) {
 	ghr := new(GetHierarchicalRequirementResponse)
@@ -84,8 +117,9 @@ func (s *HierarchicalRequirement) GetHierarchicalRequirement(ctx context.Context
 
 // CreateHierarchicalRequirement - abstraction for CreateRequest
 func (s *HierarchicalRequirement) CreateHierarchicalRequirement(ctx context.Context, hr models.HierarchicalRequirement) (hrr models.HierarchicalRequirement, err error) {
-	createRequest := HierarchicalRequirementRequest{
-		HierarchicalRequirement: hr,
+	createRequest, err := writeRequest("HierarchicalRequirement", hr)
+	if err != nil {
+		return hrr, err
 	}
 	uhr := new(CreateHierarchicalRequirementResponse)
 	err = s.client.CreateRequest(ctx, "HierarchicalRequirement", createRequest, &uhr)
@@ -93,10 +127,19 @@ func (s *HierarchicalRequirement) CreateHierarchicalRequirement(ctx context.Cont
 	return hrr, err
 }
 
+// FindOrCreateHierarchicalRequirement - returns the first user story matching matchQuery, e.g. on a unique
+// external ID, or else creates hr; the bool reports whether it was created
+func (s *HierarchicalRequirement) FindOrCreateHierarchicalRequirement(ctx context.Context, matchQuery map[string]string, hr models.HierarchicalRequirement) (models.HierarchicalRequirement, bool, error) {
+	return findOrCreate[models.HierarchicalRequirement](ctx, s.client, "hierarchicalrequirement", matchQuery, func(ctx context.Context) (models.HierarchicalRequirement, error) {
+		return s.CreateHierarchicalRequirement(ctx, hr)
+	})
+}
+
 // UpdateHierarchicalRequirement - abstraction for UpdateRequest
 func (s *HierarchicalRequirement) UpdateHierarchicalRequirement(ctx context.Context, hr models.HierarchicalRequirement) (hrr models.HierarchicalRequirement, err error) {
-	updateRequest := HierarchicalRequirementRequest{
-		HierarchicalRequirement: hr,
+	updateRequest, err := writeRequest("HierarchicalRequirement", hr)
+	if err != nil {
+		return hrr, err
 	}
 	uhr := new(OperationResponse)
 	err = s.client.UpdateRequest(ctx, strconv.Itoa(hr.ObjectID), "HierarchicalRequirement", updateRequest, &uhr)
@@ -110,3 +153,31 @@ func (s *HierarchicalRequirement) DeleteHierarchicalRequirement(ctx context.Cont
 	err = s.client.DeleteRequest(ctx, objectID, "HierarchicalRequirement", &uhr)
 	return err
 }
+
+// SetScheduleState - moves a story to another schedule state, sending only
+// ScheduleState so no other field is overwritten, and returns the updated story
+func (s *HierarchicalRequirement) SetScheduleState(ctx context.Context, objectID string, state string) (hrr models.HierarchicalRequirement, err error) {
+	switch state {
+	case models.ScheduleStateDefined, models.ScheduleStateInProgress, models.ScheduleStateCompleted, models.ScheduleStateAccepted:
+	default:
+		return hrr, &InvalidScheduleStateError{State: state}
+	}
+	updateRequest, err := writeRequest("HierarchicalRequirement", models.HierarchicalRequirement{ScheduleState: state})
+	if err != nil {
+		return hrr, err
+	}
+	uhr := new(OperationResponse)
+	err = s.client.UpdateRequest(ctx, objectID, "HierarchicalRequirement", updateRequest, &uhr)
+	hrr = uhr.OperationalResult.Object
+	return hrr, err
+}
+
+// AddMilestone - adds a milestone, by ref, to the Milestones collection of a story
+func (s *HierarchicalRequirement) AddMilestone(ctx context.Context, objectID string, milestoneRef string) error {
+	return s.client.AddToCollection(ctx, "HierarchicalRequirement", objectID, "Milestones", []string{milestoneRef}, nil)
+}
+
+// RemoveMilestone - removes a milestone, by ref, from the Milestones collection of a story
+func (s *HierarchicalRequirement) RemoveMilestone(ctx context.Context, objectID string, milestoneRef string) error {
+	return s.client.RemoveFromCollection(ctx, "HierarchicalRequirement", objectID, "Milestones", []string{milestoneRef}, nil)
+}
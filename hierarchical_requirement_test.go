@@ -19,7 +19,10 @@ package rallyresttoolkit_test
 import (
 	"bytes"
 	"context"
+	"errors"
+	"io"
 	"net/http"
+	"strings"
 	"testing"
 
 	. "github.com/aleksofficial/go-rally-rest-toolkit"
@@ -125,8 +128,8 @@ func TestUpdateHierarchicalRequirement_ValidRequest(t *testing.T) {
 	ctx := context.Background()
 
 	updateHR := models.HierarchicalRequirement{
-		Name:     ctrlName,
-		ObjectID: 50137325678,
+		Name:              ctrlName,
+		PersistableObject: models.PersistableObject{ObjectID: 50137325678},
 	}
 	result, err := hrClient.UpdateHierarchicalRequirement(ctx, updateHR)
 	if err != nil {
@@ -157,3 +160,271 @@ func TestDeleteHierarchicalRequirement_ValidObjectID(t *testing.T) {
 		t.Fatalf("DeleteHierarchicalRequirement failed unexpectedly: %v", err)
 	}
 }
+
+func TestQueryHierarchicalRequirement_BlockedStory(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{
+		FakeResponse: &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       &fakes.FakeResponseBody{Reader: bytes.NewBufferString(`{"QueryResult": { "TotalResultCount": 1, "Results": [{"ObjectID": 50137325678, "FormattedID": "US624340", "Blocked": true, "BlockedReason": "Waiting on API team", "Ready": false}]}}`)},
+		},
+	}
+
+	apiKey := "abcdef"
+	apiURL := "http://myRallyUrl"
+	rallyClient := New(apiKey, apiURL, fakeClient)
+	hrClient := NewHierarchicalRequirement(rallyClient)
+	ctx := context.Background()
+
+	query := map[string]string{
+		"Blocked": "true",
+	}
+	results, err := hrClient.QueryHierarchicalRequirement(ctx, query)
+	if err != nil {
+		t.Fatalf("QueryHierarchicalRequirement failed unexpectedly: %v", err)
+	}
+	if got := fakeClient.SpyRequest.URL.Query().Get("query"); got != "( Blocked = true )" {
+		t.Errorf("expected query '( Blocked = true )', got %q", got)
+	}
+	if len(results) != 1 {
+		t.Fatalf("expected 1 result, got %d", len(results))
+	}
+	story := results[0]
+	if story.Blocked == nil || !*story.Blocked {
+		t.Errorf("expected Blocked=true, got %v", story.Blocked)
+	}
+	if story.BlockedReason == nil || *story.BlockedReason != "Waiting on API team" {
+		t.Errorf("expected BlockedReason='Waiting on API team', got %v", story.BlockedReason)
+	}
+	if story.Ready == nil || *story.Ready {
+		t.Errorf("expected Ready=false, got %v", story.Ready)
+	}
+}
+
+func TestUpdateHierarchicalRequirement_ClearsBlockedReason(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{
+		FakeResponse: &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       &fakes.FakeResponseBody{Reader: bytes.NewBufferString(`{"OperationalResult": {"Object": {"ObjectID": 50137325678, "Blocked": false, "BlockedReason": ""}}}`)},
+		},
+	}
+
+	apiKey := "abcdef"
+	apiURL := "http://myRallyUrl"
+	rallyClient := New(apiKey, apiURL, fakeClient)
+	hrClient := NewHierarchicalRequirement(rallyClient)
+	ctx := context.Background()
+
+	updateHR := models.HierarchicalRequirement{
+		PersistableObject: models.PersistableObject{ObjectID: 50137325678},
+		Blocked:           models.Bool(false),
+		BlockedReason:     models.String(""),
+	}
+	if _, err := hrClient.UpdateHierarchicalRequirement(ctx, updateHR); err != nil {
+		t.Fatalf("UpdateHierarchicalRequirement failed unexpectedly: %v", err)
+	}
+
+	body, err := io.ReadAll(fakeClient.SpyRequest.Body)
+	if err != nil {
+		t.Fatalf("failed to read request body: %v", err)
+	}
+	if !strings.Contains(string(body), `"Blocked":false`) {
+		t.Errorf("expected request body to unblock the story, got %s", body)
+	}
+	if !strings.Contains(string(body), `"BlockedReason":""`) {
+		t.Errorf("expected request body to clear BlockedReason, got %s", body)
+	}
+	if strings.Contains(string(body), `"Ready"`) {
+		t.Errorf("expected unset Ready to be omitted, got %s", body)
+	}
+}
+
+func TestUpdateHierarchicalRequirement_OmitsReadOnlySummaries(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{
+		FakeResponse: &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       &fakes.FakeResponseBody{Reader: bytes.NewBufferString(`{"OperationalResult": {"Object": {"Name": "UpdatedStoryName", "ObjectID": 50137325678, "Discussion": {"Count": 2}, "Attachments": {"Count": 4}}}}`)},
+		},
+	}
+
+	rallyClient := New("abcdef", "http://myRallyUrl", fakeClient)
+	hrClient := NewHierarchicalRequirement(rallyClient)
+
+	updateHR := models.HierarchicalRequirement{
+		Name:              "UpdatedStoryName",
+		PersistableObject: models.PersistableObject{ObjectID: 50137325678},
+		Discussion:        &models.Reference{Count: 2},
+		Attachments:       &models.Reference{Count: 4},
+		Defects:           &models.Reference{Count: 1, Ref: "/hierarchicalrequirement/50137325678/Defects"},
+		TestCases:         &models.Reference{Count: 3},
+		Children:          &models.Reference{Count: 0},
+	}
+	result, err := hrClient.UpdateHierarchicalRequirement(context.Background(), updateHR)
+	if err != nil {
+		t.Fatalf("UpdateHierarchicalRequirement failed unexpectedly: %v", err)
+	}
+	if result.Discussion == nil || result.Discussion.Count != 2 {
+		t.Errorf("expected Discussion.Count=2 on the result, got %+v", result.Discussion)
+	}
+
+	body, err := io.ReadAll(fakeClient.SpyRequest.Body)
+	if err != nil {
+		t.Fatalf("failed to read request body: %v", err)
+	}
+	if !strings.Contains(string(body), `"Name":"UpdatedStoryName"`) {
+		t.Errorf("expected Name in update body, got %s", body)
+	}
+	if strings.Contains(string(body), "Discussion") || strings.Contains(string(body), "Attachments") ||
+		strings.Contains(string(body), "Defects") || strings.Contains(string(body), "TestCases") || strings.Contains(string(body), "Children") {
+		t.Errorf("expected read-only summaries to be omitted from update body, got %s", body)
+	}
+}
+
+func TestCreateHierarchicalRequirement_OmitsDragAndDropRank(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{
+		FakeResponse: &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       &fakes.FakeResponseBody{Reader: bytes.NewBufferString(`{"CreateResult": {"Object": {"Name": "NewStory", "ObjectID": 50137325678, "DragAndDropRank": "O~PPPP"}}}`)},
+		},
+	}
+
+	rallyClient := New("abcdef", "http://myRallyUrl", fakeClient)
+	hrClient := NewHierarchicalRequirement(rallyClient)
+
+	newHR := models.HierarchicalRequirement{
+		Name:            "NewStory",
+		DragAndDropRank: "O~!!!!",
+	}
+	result, err := hrClient.CreateHierarchicalRequirement(context.Background(), newHR)
+	if err != nil {
+		t.Fatalf("CreateHierarchicalRequirement failed unexpectedly: %v", err)
+	}
+	if result.DragAndDropRank != "O~PPPP" {
+		t.Errorf("expected DragAndDropRank to be read back, got %q", result.DragAndDropRank)
+	}
+
+	body, err := io.ReadAll(fakeClient.SpyRequest.Body)
+	if err != nil {
+		t.Fatalf("failed to read request body: %v", err)
+	}
+	if strings.Contains(string(body), "DragAndDropRank") {
+		t.Errorf("expected DragAndDropRank to be omitted from create body, got %s", body)
+	}
+}
+
+func TestAddMilestone_HierarchicalRequirement(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{
+		FakeResponse: &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       &fakes.FakeResponseBody{Reader: bytes.NewBufferString(`{"OperationResult": {"Errors": [], "Warnings": []}}`)},
+		},
+	}
+
+	rallyClient := New("abcdef", "http://myRallyUrl", fakeClient)
+	hrClient := NewHierarchicalRequirement(rallyClient)
+
+	if err := hrClient.AddMilestone(context.Background(), "123", "/milestone/777"); err != nil {
+		t.Fatalf("AddMilestone failed unexpectedly: %v", err)
+	}
+	if got := strings.ToLower(fakeClient.SpyRequest.URL.Path); got != "/hierarchicalrequirement/123/milestones/add" {
+		t.Errorf("expected .../hierarchicalrequirement/123/Milestones/add, got %s", fakeClient.SpyRequest.URL.Path)
+	}
+}
+
+func TestSetScheduleState_SendsOnlyScheduleState(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{
+		FakeResponse: &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       &fakes.FakeResponseBody{Reader: bytes.NewBufferString(`{"OperationalResult": {"Object": {"ObjectID": 50137325678, "Name": "Login page", "ScheduleState": "In-Progress"}}}`)},
+		},
+	}
+
+	hrClient := NewHierarchicalRequirement(New("abcdef", "http://myRallyUrl", fakeClient))
+
+	story, err := hrClient.SetScheduleState(context.Background(), "50137325678", models.ScheduleStateInProgress)
+	if err != nil {
+		t.Fatalf("SetScheduleState failed unexpectedly: %v", err)
+	}
+	if story.ScheduleState != models.ScheduleStateInProgress || story.Name != "Login page" {
+		t.Errorf("expected the updated story to be returned, got %+v", story)
+	}
+
+	if !strings.HasSuffix(fakeClient.SpyRequest.URL.Path, "/HierarchicalRequirement/50137325678") {
+		t.Errorf("unexpected request path %s", fakeClient.SpyRequest.URL.Path)
+	}
+	body, err := io.ReadAll(fakeClient.SpyRequest.Body)
+	if err != nil {
+		t.Fatalf("failed to read request body: %v", err)
+	}
+	if string(body) != `{"HierarchicalRequirement":{"ScheduleState":"In-Progress"}}` {
+		t.Errorf("expected a ScheduleState-only body, got %s", body)
+	}
+}
+
+func TestSetScheduleState_RejectsUnknownState(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{}
+	hrClient := NewHierarchicalRequirement(New("abcdef", "http://myRallyUrl", fakeClient))
+
+	_, err := hrClient.SetScheduleState(context.Background(), "50137325678", "Done")
+
+	var stateErr *InvalidScheduleStateError
+	if !errors.As(err, &stateErr) || stateErr.State != "Done" {
+		t.Fatalf("expected InvalidScheduleStateError for %q, got %v", "Done", err)
+	}
+	if fakeClient.CallCount != 0 {
+		t.Errorf("expected no HTTP call, got %d", fakeClient.CallCount)
+	}
+}
+
+func TestQueryHierarchicalRequirementByScheduleState(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{
+		FakeResponse: &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       &fakes.FakeResponseBody{Reader: bytes.NewBufferString(`{"QueryResult": { "TotalResultCount": 1, "Results": [{"FormattedID": "US1", "ScheduleState": "In-Progress"}]}}`)},
+		},
+	}
+	hrClient := NewHierarchicalRequirement(New("abcdef", "http://myRallyUrl", fakeClient))
+
+	stories, err := hrClient.QueryHierarchicalRequirementByScheduleState(context.Background(), models.ScheduleStateInProgress)
+	if err != nil {
+		t.Fatalf("QueryHierarchicalRequirementByScheduleState failed unexpectedly: %v", err)
+	}
+	if len(stories) != 1 || stories[0].FormattedID != "US1" {
+		t.Errorf("unexpected stories: %+v", stories)
+	}
+	if got := fakeClient.SpyRequest.URL.Query().Get("query"); got != "( ScheduleState = In-Progress )" {
+		t.Errorf("unexpected query %s", got)
+	}
+}
+
+func TestGetCollection_StoryDefects(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{
+		FakeResponses: []*http.Response{
+			jsonResponse(`{"HierarchicalRequirement": {"ObjectID": 123, "FormattedID": "US123",
+				"Defects": {"_ref": "https://rally1.rallydev.com/slm/webservice/v2.0/HierarchicalRequirement/123/Defects", "Count": 3},
+				"TestCases": {"_ref": "https://rally1.rallydev.com/slm/webservice/v2.0/HierarchicalRequirement/123/TestCases", "Count": 0},
+				"Children": {"_ref": "https://rally1.rallydev.com/slm/webservice/v2.0/HierarchicalRequirement/123/Children", "Count": 0}}}`),
+			jsonResponse(`{"QueryResult": {"TotalResultCount": 3, "StartIndex": 1, "Results": [{"ObjectID": 1, "FormattedID": "DE1"}, {"ObjectID": 2, "FormattedID": "DE2"}]}}`),
+			jsonResponse(`{"QueryResult": {"TotalResultCount": 3, "StartIndex": 3, "Results": [{"ObjectID": 3, "FormattedID": "DE3"}]}}`),
+		},
+	}
+	rallyClient := New("abcdef", "http://myRallyUrl", fakeClient)
+
+	story, err := NewHierarchicalRequirement(rallyClient).GetHierarchicalRequirement(context.Background(), "123")
+	if err != nil {
+		t.Fatalf("GetHierarchicalRequirement failed unexpectedly: %v", err)
+	}
+	if story.Defects == nil || story.Defects.Count != 3 || story.TestCases == nil || story.Children == nil {
+		t.Fatalf("expected Defects, TestCases and Children summaries, got %+v", story)
+	}
+
+	defects, err := GetCollection[models.Defect](context.Background(), rallyClient, story.Defects.Ref)
+	if err != nil {
+		t.Fatalf("GetCollection failed unexpectedly: %v", err)
+	}
+	if len(defects) != 3 || defects[2].FormattedID != "DE3" {
+		t.Errorf("expected all three defects across pages, got %+v", defects)
+	}
+	if got := fakeClient.SpyRequest.URL.String(); got != "http://myRallyUrl/HierarchicalRequirement/123/Defects?fetch=true&pagesize=200&start=3" {
+		t.Errorf("unexpected URL for the second page: %s", got)
+	}
+}
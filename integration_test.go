@@ -66,6 +66,23 @@ func TestIntegration_NewClientFromEnv(t *testing.T) {
 	}
 }
 
+// TestIntegration_Ping verifies connectivity and that the API key is accepted
+func TestIntegration_Ping(t *testing.T) {
+	skipIfNoAPIKey(t)
+
+	client, err := rally.NewClientFromEnv()
+	if err != nil {
+		t.Fatalf("NewClientFromEnv() failed: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if err := client.Ping(ctx); err != nil {
+		t.Fatalf("Ping() failed: %v", err)
+	}
+}
+
 // TestIntegration_QueryRequest_FetchProjects queries for projects to verify API connectivity
 func TestIntegration_QueryRequest_FetchProjects(t *testing.T) {
 	skipIfNoAPIKey(t)
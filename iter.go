@@ -0,0 +1,103 @@
+//go:build go1.23
+
+/**
+* Copyright 2014 Comcast Cable Communications Management, LLC
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+* http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package rallyresttoolkit
+
+import (
+	"context"
+	"errors"
+	"iter"
+
+	"github.com/aleksofficial/go-rally-rest-toolkit/models"
+)
+
+// seqPageSize is the page size iterators request unless
+// Config.DefaultPageSize is set; large enough to keep the number of requests
+// down, small enough to keep memory flat.
+const seqPageSize = 200
+
+// errStopIteration ends paging when the consumer breaks out of a range loop.
+var errStopIteration = errors.New("iteration stopped")
+
+// querySeq returns an iterator over every result of a query, fetching pages
+// only as the consumer reaches them. A failed page is yielded once as a
+// *PaginationError, after which iteration ends.
+func querySeq[T any](ctx context.Context, client *RallyClient, query map[string]string, queryType string) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		_, _, err := queryAll[T](ctx, client, query, queryType, QueryOptions{PageSize: client.pageSize(seqPageSize)}, func(result T) error {
+			if !yield(result, nil) {
+				return errStopIteration
+			}
+			return nil
+		})
+		if err != nil && !errors.Is(err, errStopIteration) {
+			var zero T
+			yield(zero, err)
+		}
+	}
+}
+
+// QueryBuildAll - iterates over every Build matching query, fetching pages on demand
+func (s *Build) QueryBuildAll(ctx context.Context, query map[string]string) iter.Seq2[models.Build, error] {
+	return querySeq[models.Build](ctx, s.client, query, "build")
+}
+
+// QueryBuildDefinitionAll - iterates over every BuildDefinition matching query, fetching pages on demand
+func (s *BuildDefinition) QueryBuildDefinitionAll(ctx context.Context, query map[string]string) iter.Seq2[models.BuildDefinition, error] {
+	return querySeq[models.BuildDefinition](ctx, s.client, query, "buildDefinition")
+}
+
+// QueryChangesetAll - iterates over every Changeset matching query, fetching pages on demand
+func (s *Changeset) QueryChangesetAll(ctx context.Context, query map[string]string) iter.Seq2[models.Changeset, error] {
+	return querySeq[models.Changeset](ctx, s.client, query, "changeset")
+}
+
+// QueryDefectAll - iterates over every Defect matching query, fetching pages on demand
+func (s *Defect) QueryDefectAll(ctx context.Context, query map[string]string) iter.Seq2[models.Defect, error] {
+	return querySeq[models.Defect](ctx, s.client, query, "defect")
+}
+
+// QueryHierarchicalRequirementAll - iterates over every HierarchicalRequirement matching query, fetching pages on demand
+func (s *HierarchicalRequirement) QueryHierarchicalRequirementAll(ctx context.Context, query map[string]string) iter.Seq2[models.HierarchicalRequirement, error] {
+	return querySeq[models.HierarchicalRequirement](ctx, s.client, query, "HierarchicalRequirement")
+}
+
+// QueryIterationAll - iterates over every Iteration matching query, fetching pages on demand
+func (s *Iteration) QueryIterationAll(ctx context.Context, query map[string]string) iter.Seq2[models.Iteration, error] {
+	return querySeq[models.Iteration](ctx, s.client, query, "iteration")
+}
+
+// QueryPortfolioItemAll - iterates over every PortfolioItem matching query, fetching pages on demand
+func (s *PortfolioItem) QueryPortfolioItemAll(ctx context.Context, query map[string]string) iter.Seq2[models.PortfolioItem, error] {
+	return querySeq[models.PortfolioItem](ctx, s.client, query, s.queryType)
+}
+
+// QueryReleaseAll - iterates over every Release matching query, fetching pages on demand
+func (s *Release) QueryReleaseAll(ctx context.Context, query map[string]string) iter.Seq2[models.Release, error] {
+	return querySeq[models.Release](ctx, s.client, query, "release")
+}
+
+// QueryTaskAll - iterates over every Task matching query, fetching pages on demand
+func (s *Task) QueryTaskAll(ctx context.Context, query map[string]string) iter.Seq2[models.Task, error] {
+	return querySeq[models.Task](ctx, s.client, query, "task")
+}
+
+// QueryUserAll - iterates over every User matching query, fetching pages on demand
+func (s *User) QueryUserAll(ctx context.Context, query map[string]string) iter.Seq2[models.User, error] {
+	return querySeq[models.User](ctx, s.client, query, "user")
+}
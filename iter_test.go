@@ -0,0 +1,146 @@
+//go:build go1.23
+
+/**
+* Copyright 2014 Comcast Cable Communications Management, LLC
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+* http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package rallyresttoolkit_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+
+	. "github.com/aleksofficial/go-rally-rest-toolkit"
+	"github.com/aleksofficial/go-rally-rest-toolkit/fakes"
+)
+
+func TestQueryHierarchicalRequirementAll_AllPages(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{
+		FakeResponses: []*http.Response{
+			queryPageResponse(1, 20, 45),
+			queryPageResponse(21, 20, 45),
+			queryPageResponse(41, 5, 45),
+		},
+	}
+	hrClient := NewHierarchicalRequirement(New("abcdef", "http://myRallyUrl", fakeClient))
+
+	count := 0
+	for story, err := range hrClient.QueryHierarchicalRequirementAll(context.Background(), map[string]string{}) {
+		if err != nil {
+			t.Fatalf("iteration failed unexpectedly: %v", err)
+		}
+		count++
+		if story.ObjectID != count {
+			t.Errorf("expected ObjectID %d, got %d", count, story.ObjectID)
+		}
+	}
+	if count != 45 {
+		t.Errorf("expected 45 stories, got %d", count)
+	}
+	if fakeClient.CallCount != 3 {
+		t.Errorf("expected 3 HTTP calls, got %d", fakeClient.CallCount)
+	}
+}
+
+func TestQueryDefectAll_EarlyBreakStopsFetching(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{
+		FakeResponses: []*http.Response{
+			queryPageResponse(1, 20, 45),
+			queryPageResponse(21, 20, 45),
+			queryPageResponse(41, 5, 45),
+		},
+	}
+	defectClient := NewDefect(New("abcdef", "http://myRallyUrl", fakeClient))
+
+	count := 0
+	for _, err := range defectClient.QueryDefectAll(context.Background(), map[string]string{}) {
+		if err != nil {
+			t.Fatalf("iteration failed unexpectedly: %v", err)
+		}
+		count++
+		if count == 25 {
+			break
+		}
+	}
+	if fakeClient.CallCount != 2 {
+		t.Errorf("expected 2 HTTP calls after breaking on the second page, got %d", fakeClient.CallCount)
+	}
+}
+
+func TestQueryDefectAll_YieldsError(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{
+		FakeResponses: []*http.Response{
+			queryPageResponse(1, 20, 45),
+			{
+				StatusCode: http.StatusBadRequest,
+				Body:       &fakes.FakeResponseBody{Reader: bytes.NewBufferString(`{"QueryResult": {"Errors": ["Invalid start index"]}}`)},
+			},
+		},
+	}
+	defectClient := NewDefect(New("abcdef", "http://myRallyUrl", fakeClient))
+
+	count := 0
+	var iterErr error
+	for _, err := range defectClient.QueryDefectAll(context.Background(), map[string]string{}) {
+		if err != nil {
+			iterErr = err
+			continue
+		}
+		count++
+	}
+	if count != 20 {
+		t.Errorf("expected 20 defects before the error, got %d", count)
+	}
+	if !errors.Is(iterErr, ErrRallyAPI) {
+		t.Errorf("expected a RallyAPIError, got %v", iterErr)
+	}
+}
+
+func TestQueryDefectAll_ConfigPageSize(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{FakeResponse: queryPageResponse(1, 3, 3)}
+	rallyClient, err := NewClient(WithHTTPClient(fakeClient), WithDefaultPageSize(1000))
+	if err != nil {
+		t.Fatalf("NewClient failed unexpectedly: %v", err)
+	}
+
+	for _, err := range NewDefect(rallyClient).QueryDefectAll(context.Background(), nil) {
+		if err != nil {
+			t.Fatalf("iteration failed unexpectedly: %v", err)
+		}
+	}
+	if got := fakeClient.SpyRequest.URL.Query().Get("pagesize"); got != "1000" {
+		t.Errorf("expected pagesize 1000, got %q", got)
+	}
+}
+
+func TestQueryDefectAll_DropsDuplicatesAcrossPages(t *testing.T) {
+	server := shiftedPages()
+	defectClient := NewDefect(New("abcdef", "http://myRallyUrl", server))
+
+	var ids []int
+	for defect, err := range defectClient.QueryDefectAll(context.Background(), nil) {
+		if err != nil {
+			t.Fatalf("iteration failed unexpectedly: %v", err)
+		}
+		ids = append(ids, defect.ObjectID)
+	}
+	if expected := []int{1, 2, 3, 4, 5, 6, 7, 8}; !reflect.DeepEqual(ids, expected) {
+		t.Errorf("expected %v, got %v", expected, ids)
+	}
+}
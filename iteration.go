@@ -0,0 +1,120 @@
+/**
+* Copyright 2014 Comcast Cable Communications Management, LLC
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+* http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package rallyresttoolkit
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/aleksofficial/go-rally-rest-toolkit/models"
+)
+
+// Iteration - struct to hold client
+type Iteration struct {
+	client *RallyClient
+}
+
+// QueryIterationResponse - struct to contain query response
+type QueryIterationResponse = models.QueryResponse[models.Iteration]
+
+// GetIterationResponse - Struct to contain response
+type GetIterationResponse struct {
+	Iteration models.Iteration
+}
+
+type CreateIterationResponse struct {
+	CreateResult iterationResult
+}
+
+type iterationResult struct {
+	Object models.Iteration
+}
+
+// OperationResponse - struct to contain response
+type iterationOperationResponse = models.OperationResponse[models.Iteration]
+
+// NewIteration - creates new Iteration
+func NewIteration(client *RallyClient) (de *Iteration) {
+	return &Iteration{
+		client: client,
+	}
+}
+
+// QueryIteration - abstraction for QueryRequest
+func (s *Iteration) QueryIteration(ctx context.Context, query map[string]string) (des []models.Iteration, err error) {
+	qdes := new(QueryIterationResponse)
+	err = s.client.QueryRequest(ctx, query, "iteration", &qdes)
+	return qdes.QueryResult.Results, err
+}
+
+// QueryIterationWithMeta - QueryRequest that also returns the envelope's paging metadata and Warnings
+func (s *Iteration) QueryIterationWithMeta(ctx context.Context, query map[string]string) ([]models.Iteration, QueryMeta, error) {
+	return Query[models.Iteration](ctx, s.client, "iteration", query, QueryOptions{})
+}
+
+// QueryIterationPage - abstraction for QueryRequestWithOptions that keeps the paging metadata
+func (s *Iteration) QueryIterationPage(ctx context.Context, query map[string]string, opts QueryOptions) (Page[models.Iteration], error) {
+	return queryPage[models.Iteration](ctx, s.client, query, "iteration", opts)
+}
+
+// QueryIterationRaw - abstraction for QueryRequestRaw, for query clauses written by hand
+func (s *Iteration) QueryIterationRaw(ctx context.Context, rawQuery string, opts QueryOptions) (Page[models.Iteration], error) {
+	return queryRawPage[models.Iteration](ctx, s.client, rawQuery, "iteration", opts)
+}
+
+// QueryIterationWith - abstraction for QueryRequestWith
+func (s *Iteration) QueryIterationWith(ctx context.Context, b *QueryBuilder) (Page[models.Iteration], error) {
+	return queryWithPage[models.Iteration](ctx, s.client, b, "iteration")
+}
+
+// GetIteration - abstraction for GetRequest
+func (s *Iteration) GetIteration(ctx context.Context, objectID string) (de models.Iteration, err error) {
+	gde := new(GetIterationResponse)
+	err = s.client.GetRequest(ctx, objectID, "iteration", &gde)
+	return gde.Iteration, err
+}
+
+// CreateIteration - abstraction for CreateRequest
+func (s *Iteration) CreateIteration(ctx context.Context, iteration models.Iteration) (der models.Iteration, err error) {
+	createRequest, err := writeRequest("Iteration", iteration)
+	if err != nil {
+		return der, err
+	}
+	ude := new(CreateIterationResponse)
+	err = s.client.CreateRequest(ctx, "iteration", createRequest, &ude)
+	der = ude.CreateResult.Object
+	return der, err
+}
+
+// UpdateIteration - abstraction for UpdateRequest
+func (s *Iteration) UpdateIteration(ctx context.Context, iteration models.Iteration) (iterationr models.Iteration, err error) {
+	updateRequest, err := writeRequest("Iteration", iteration)
+	if err != nil {
+		return iterationr, err
+	}
+	ude := new(iterationOperationResponse)
+	err = s.client.UpdateRequest(ctx, strconv.Itoa(iteration.ObjectID), "iteration", updateRequest, &ude)
+	iterationr = ude.OperationalResult.Object
+	return iterationr, err
+}
+
+// DeleteIteration - abstraction for DeleteRequest
+func (s *Iteration) DeleteIteration(ctx context.Context, objectID string) (err error) {
+	ude := new(deOperationResponse)
+	err = s.client.DeleteRequest(ctx, objectID, "iteration", &ude)
+	return err
+}
@@ -0,0 +1,162 @@
+/**
+* Copyright 2014 Comcast Cable Communications Management, LLC
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+* http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package rallyresttoolkit_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	. "github.com/aleksofficial/go-rally-rest-toolkit"
+	"github.com/aleksofficial/go-rally-rest-toolkit/fakes"
+	"github.com/aleksofficial/go-rally-rest-toolkit/models"
+)
+
+func TestQueryIteration_ZSuffixedDates(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{
+		FakeResponse: &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       &fakes.FakeResponseBody{Reader: bytes.NewBufferString(`{"QueryResult": {"TotalResultCount": 1, "Results": [{"ObjectID": 60000000001, "Name": "Sprint 42", "StartDate": "2016-03-07T00:00:00.000Z", "EndDate": "2016-03-18T23:59:59.000Z", "State": "Committed", "PlannedVelocity": 34.5}]}}`)},
+		},
+	}
+
+	rallyClient := New("abcdef", "http://myRallyUrl", fakeClient)
+	iterationClient := NewIteration(rallyClient)
+
+	iterations, err := iterationClient.QueryIteration(context.Background(), map[string]string{"Name": "Sprint 42"})
+	if err != nil {
+		t.Fatalf("QueryIteration failed unexpectedly: %v", err)
+	}
+	if len(iterations) != 1 {
+		t.Fatalf("expected 1 iteration, got %d", len(iterations))
+	}
+	iteration := iterations[0]
+	if !iteration.StartDate.Equal(time.Date(2016, 3, 7, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected StartDate: %v", iteration.StartDate)
+	}
+	if !iteration.EndDate.Equal(time.Date(2016, 3, 18, 23, 59, 59, 0, time.UTC)) {
+		t.Errorf("unexpected EndDate: %v", iteration.EndDate)
+	}
+	if iteration.State != models.IterationStateCommitted || iteration.PlannedVelocity != 34.5 {
+		t.Errorf("unexpected State/PlannedVelocity: %q/%v", iteration.State, iteration.PlannedVelocity)
+	}
+}
+
+func TestCreateIteration_SprintDates(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{
+		FakeResponse: &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       &fakes.FakeResponseBody{Reader: bytes.NewBufferString(`{"CreateResult": {"Object": {"ObjectID": 60000000001, "Name": "Sprint 42", "StartDate": "2016-03-07T00:00:00.000Z", "EndDate": "2016-03-18T23:59:59.000Z", "State": "Planning"}}}`)},
+		},
+	}
+
+	rallyClient := New("abcdef", "http://myRallyUrl", fakeClient)
+	iterationClient := NewIteration(rallyClient)
+
+	sprintStart := time.Date(2016, 3, 7, 0, 0, 0, 0, time.UTC)
+	newIteration := models.Iteration{
+		Project:         &models.Reference{Ref: "/project/1234"},
+		Name:            "Sprint 42",
+		StartDate:       models.NewTime(sprintStart),
+		EndDate:         models.NewTime(sprintStart.AddDate(0, 0, 12).Add(-time.Second)),
+		State:           models.IterationStatePlanning,
+		PlannedVelocity: 34.5,
+		Theme:           "Checkout hardening",
+	}
+	result, err := iterationClient.CreateIteration(context.Background(), newIteration)
+	if err != nil {
+		t.Fatalf("CreateIteration failed unexpectedly: %v", err)
+	}
+	if result.ObjectID != 60000000001 {
+		t.Errorf("expected ObjectID=60000000001, got %d", result.ObjectID)
+	}
+
+	var body struct {
+		Iteration map[string]interface{}
+	}
+	if err := json.NewDecoder(fakeClient.SpyRequest.Body).Decode(&body); err != nil {
+		t.Fatalf("failed to decode request body: %v", err)
+	}
+	if body.Iteration["StartDate"] != "2016-03-07T00:00:00.000Z" {
+		t.Errorf("unexpected StartDate: %v", body.Iteration["StartDate"])
+	}
+	if body.Iteration["EndDate"] != "2016-03-18T23:59:59.000Z" {
+		t.Errorf("unexpected EndDate: %v", body.Iteration["EndDate"])
+	}
+	if body.Iteration["State"] != "Planning" || body.Iteration["Theme"] != "Checkout hardening" {
+		t.Errorf("unexpected State/Theme: %v", body.Iteration)
+	}
+}
+
+func TestUpdateIteration_Accept(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{
+		FakeResponse: &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       &fakes.FakeResponseBody{Reader: bytes.NewBufferString(`{"OperationalResult": {"Object": {"ObjectID": 60000000001, "State": "Accepted"}}}`)},
+		},
+	}
+
+	rallyClient := New("abcdef", "http://myRallyUrl", fakeClient)
+	iterationClient := NewIteration(rallyClient)
+
+	update := models.Iteration{
+		PersistableObject: models.PersistableObject{ObjectID: 60000000001},
+		State:             models.IterationStateAccepted,
+	}
+	result, err := iterationClient.UpdateIteration(context.Background(), update)
+	if err != nil {
+		t.Fatalf("UpdateIteration failed unexpectedly: %v", err)
+	}
+	if result.State != models.IterationStateAccepted {
+		t.Errorf("expected State=Accepted, got %q", result.State)
+	}
+	if got := fakeClient.SpyRequest.URL.Path; got != "/iteration/60000000001" {
+		t.Errorf("unexpected URL path: %s", got)
+	}
+
+	var body struct {
+		Iteration map[string]interface{}
+	}
+	if err := json.NewDecoder(fakeClient.SpyRequest.Body).Decode(&body); err != nil {
+		t.Fatalf("failed to decode request body: %v", err)
+	}
+	if body.Iteration["State"] != "Accepted" {
+		t.Errorf("expected State=Accepted to be written, got %v", body.Iteration)
+	}
+	if _, ok := body.Iteration["StartDate"]; ok {
+		t.Errorf("expected unset dates not to be written, got %v", body.Iteration)
+	}
+}
+
+func TestDeleteIteration_ValidObjectID(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{
+		FakeResponse: &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       &fakes.FakeResponseBody{Reader: bytes.NewBufferString(`{"OperationResult": {"Errors": [], "Warnings": []}}`)},
+		},
+	}
+
+	rallyClient := New("abcdef", "http://myRallyUrl", fakeClient)
+	iterationClient := NewIteration(rallyClient)
+
+	if err := iterationClient.DeleteIteration(context.Background(), "60000000001"); err != nil {
+		t.Fatalf("DeleteIteration failed unexpectedly: %v", err)
+	}
+}
@@ -0,0 +1,122 @@
+/**
+* Copyright 2014 Comcast Cable Communications Management, LLC
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+* http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package rallyresttoolkit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/aleksofficial/go-rally-rest-toolkit/models"
+)
+
+// lookbackPath is where the Lookback API serves snapshot queries for a
+// workspace, on the same host as WSAPI.
+const lookbackPath = "/analytics/v2.0/service/rally/workspace/%s/artifact/snapshot/query.js"
+
+// LookbackQuery is a Lookback API snapshot query. Find is a MongoDB-style
+// filter such as {"ObjectID": 123, "_ValidFrom": {"$gte": "2024-01-01"}}.
+type LookbackQuery struct {
+	Find map[string]interface{} `json:"find"`
+	// Fields lists the fields to return (optional, defaults to the server's
+	// default set)
+	Fields []string `json:"fields,omitempty"`
+	// Hydrate lists fields whose IDs should be expanded to names, e.g.
+	// "ScheduleState" (optional)
+	Hydrate []string `json:"hydrate,omitempty"`
+	// Sort orders snapshots by field, 1 ascending and -1 descending, e.g.
+	// {"_ValidFrom": 1} (optional)
+	Sort map[string]int `json:"sort,omitempty"`
+	// PageSize is the number of snapshots per page (optional)
+	PageSize int `json:"pagesize,omitempty"`
+	// Start is the 0-based index of the first snapshot to return (optional)
+	Start int `json:"start,omitempty"`
+}
+
+// LookbackPage is one page of snapshots.
+type LookbackPage struct {
+	models.LookbackResult
+}
+
+// HasMore reports whether there are snapshots beyond this page.
+func (p LookbackPage) HasMore() bool {
+	return len(p.Results) > 0 && p.NextStart() < p.TotalResultCount
+}
+
+// NextStart returns the Start to request the page after this one.
+func (p LookbackPage) NextStart() int {
+	return p.StartIndex + len(p.Results)
+}
+
+// LookbackClient - struct to hold client
+type LookbackClient struct {
+	client    *RallyClient
+	workspace string
+}
+
+// NewLookbackClient - creates a Lookback API client for the workspace with
+// the given ObjectID. It sends requests through client, with its API key,
+// retries and rate limit.
+func NewLookbackClient(client *RallyClient, workspaceID string) (lb *LookbackClient) {
+	return &LookbackClient{
+		client:    client,
+		workspace: workspaceID,
+	}
+}
+
+// FindSnapshots - runs a snapshot query and returns one page. Follow
+// HasMore and NextStart to page through the rest.
+func (s *LookbackClient) FindSnapshots(ctx context.Context, query LookbackQuery) (LookbackPage, error) {
+	var page LookbackPage
+
+	u, err := s.endpoint()
+	if err != nil {
+		return page, err
+	}
+	if query.Find == nil {
+		query.Find = map[string]interface{}{}
+	}
+	body, err := json.Marshal(query)
+	if err != nil {
+		return page, fmt.Errorf("failed to marshal request body: %w", err)
+	}
+
+	if err := s.client.execute(ctx, "POST", u, body, &page.LookbackResult); err != nil {
+		return page, err
+	}
+	if len(page.Errors) > 0 {
+		return page, &RallyAPIError{
+			StatusCode: http.StatusOK,
+			Message:    strings.Join(page.Errors, "; "),
+			Errors:     page.Errors,
+			Warnings:   page.Warnings,
+		}
+	}
+	return page, nil
+}
+
+// endpoint builds the snapshot query URL on the host of the client's base URL.
+func (s *LookbackClient) endpoint() (*url.URL, error) {
+	base, err := url.Parse(s.client.apiurl)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse URL: %w", err)
+	}
+	return &url.URL{Scheme: base.Scheme, Host: base.Host, Path: fmt.Sprintf(lookbackPath, url.PathEscape(s.workspace))}, nil
+}
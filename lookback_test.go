@@ -0,0 +1,130 @@
+/**
+* Copyright 2014 Comcast Cable Communications Management, LLC
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+* http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package rallyresttoolkit_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+
+	. "github.com/aleksofficial/go-rally-rest-toolkit"
+	"github.com/aleksofficial/go-rally-rest-toolkit/fakes"
+)
+
+func TestFindSnapshots_Serialization(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{
+		FakeResponse: jsonResponse(`{"_rallyAPIMajor": "2", "TotalResultCount": 1, "StartIndex": 0, "PageSize": 100, "ETLDate": "2024-06-01T12:00:00.000Z",
+			"Results": [{"ObjectID": 123, "ScheduleState": "In-Progress", "_ValidFrom": "2024-05-01T00:00:00.000Z", "_ValidTo": "9999-01-01T00:00:00.000Z"}]}`),
+	}
+	rallyClient := New("abcdef", "https://rally1.rallydev.com/slm/webservice/v2.0", fakeClient)
+	lookback := NewLookbackClient(rallyClient, "41529001")
+
+	page, err := lookback.FindSnapshots(context.Background(), LookbackQuery{
+		Find:     map[string]interface{}{"ObjectID": 123, "_ValidFrom": map[string]string{"$gte": "2024-05-01"}},
+		Fields:   []string{"ObjectID", "ScheduleState", "_ValidFrom", "_ValidTo"},
+		Hydrate:  []string{"ScheduleState"},
+		Sort:     map[string]int{"_ValidFrom": 1},
+		PageSize: 100,
+	})
+	if err != nil {
+		t.Fatalf("FindSnapshots failed unexpectedly: %v", err)
+	}
+
+	req := fakeClient.SpyRequest
+	if req.Method != "POST" {
+		t.Errorf("expected POST, got %s", req.Method)
+	}
+	if got := req.URL.String(); got != "https://rally1.rallydev.com/analytics/v2.0/service/rally/workspace/41529001/artifact/snapshot/query.js" {
+		t.Errorf("unexpected URL: %s", got)
+	}
+	if got := req.Header.Get("ZSESSIONID"); got != "abcdef" {
+		t.Errorf("expected the API key header, got %q", got)
+	}
+
+	var body map[string]interface{}
+	if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+		t.Fatalf("failed to decode request body: %v", err)
+	}
+	expected := map[string]interface{}{
+		"find":     map[string]interface{}{"ObjectID": float64(123), "_ValidFrom": map[string]interface{}{"$gte": "2024-05-01"}},
+		"fields":   []interface{}{"ObjectID", "ScheduleState", "_ValidFrom", "_ValidTo"},
+		"hydrate":  []interface{}{"ScheduleState"},
+		"sort":     map[string]interface{}{"_ValidFrom": float64(1)},
+		"pagesize": float64(100),
+	}
+	if !reflect.DeepEqual(body, expected) {
+		t.Errorf("expected body %v, got %v", expected, body)
+	}
+
+	if len(page.Results) != 1 || page.Results[0]["ScheduleState"] != "In-Progress" || page.ETLDate != "2024-06-01T12:00:00.000Z" {
+		t.Errorf("unexpected page: %+v", page)
+	}
+	if page.HasMore() {
+		t.Error("expected a single page")
+	}
+}
+
+func TestFindSnapshots_Paging(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{
+		FakeResponses: []*http.Response{
+			jsonResponse(`{"TotalResultCount": 3, "StartIndex": 0, "PageSize": 2, "Results": [{"_SnapshotNumber": 0}, {"_SnapshotNumber": 1}]}`),
+			jsonResponse(`{"TotalResultCount": 3, "StartIndex": 2, "PageSize": 2, "Results": [{"_SnapshotNumber": 2}]}`),
+		},
+	}
+	lookback := NewLookbackClient(New("abcdef", "http://myRallyUrl", fakeClient), "1")
+
+	query := LookbackQuery{Find: map[string]interface{}{"ObjectID": 123}, PageSize: 2}
+	var snapshots int
+	for {
+		page, err := lookback.FindSnapshots(context.Background(), query)
+		if err != nil {
+			t.Fatalf("FindSnapshots failed unexpectedly: %v", err)
+		}
+		snapshots += len(page.Results)
+		if !page.HasMore() {
+			break
+		}
+		query.Start = page.NextStart()
+	}
+	if snapshots != 3 || fakeClient.CallCount != 2 {
+		t.Errorf("expected 3 snapshots over 2 requests, got %d over %d", snapshots, fakeClient.CallCount)
+	}
+
+	var body map[string]interface{}
+	if err := json.NewDecoder(fakeClient.SpyRequest.Body).Decode(&body); err != nil {
+		t.Fatalf("failed to decode request body: %v", err)
+	}
+	if body["start"] != float64(2) {
+		t.Errorf("expected the second request to start at 2, got %v", body["start"])
+	}
+}
+
+func TestFindSnapshots_ErrorsInBody(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{
+		FakeResponse: jsonResponse(`{"Errors": ["Unknown operator $foo"], "Results": []}`),
+	}
+	lookback := NewLookbackClient(New("abcdef", "http://myRallyUrl", fakeClient), "1")
+
+	_, err := lookback.FindSnapshots(context.Background(), LookbackQuery{Find: map[string]interface{}{"ObjectID": map[string]int{"$foo": 1}}})
+	var apiErr *RallyAPIError
+	if !errors.As(err, &apiErr) || len(apiErr.Errors) != 1 {
+		t.Errorf("expected a *RallyAPIError with the body's errors, got %v", err)
+	}
+}
@@ -0,0 +1,53 @@
+/**
+* Copyright 2014 Comcast Cable Communications Management, LLC
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+* http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package models
+
+import "encoding/json"
+
+// Artifact holds the fields common to every work item type, for queries
+// against the "artifact" endpoint that return defects, stories and other
+// types on one page. Type tells them apart, and Decode reads the full object
+// into its concrete model.
+type Artifact struct {
+	PersistableObject
+	FormattedID string     `json:",omitempty" rally:"readonly"`
+	Name        string     `json:",omitempty"`
+	Description string     `json:",omitempty"`
+	Owner       *Reference `json:",omitempty"`
+	Project     *Reference `json:",omitempty"`
+	Workspace   *Reference `json:",omitempty"`
+	// Raw is the object as Rally returned it
+	Raw json.RawMessage `json:"-"`
+}
+
+// UnmarshalJSON reads the common fields and keeps the whole object in Raw.
+func (a *Artifact) UnmarshalJSON(data []byte) error {
+	type plain Artifact
+	var decoded plain
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+	*a = Artifact(decoded)
+	a.Raw = append(json.RawMessage(nil), data...)
+	return nil
+}
+
+// Decode reads the full object into a concrete model, e.g. a *Defect when
+// Type is "Defect".
+func (a Artifact) Decode(v interface{}) error {
+	return json.Unmarshal(a.Raw, v)
+}
@@ -0,0 +1,85 @@
+/**
+* Copyright 2014 Comcast Cable Communications Management, LLC
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+* http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// Collection is a to-many relationship such as Milestones or Tags. Rally reads
+// it back as a summary with a Count and the _ref of the collection endpoint,
+// and writes it as an array of object refs, which replaces the whole
+// collection. Set Items to write it; a Collection without Items is never sent.
+type Collection struct {
+	Count int
+	Ref   string
+	Type  string
+	Items []Reference
+}
+
+// NewCollection returns a Collection that sets the relationship to refs.
+func NewCollection(refs ...string) *Collection {
+	items := make([]Reference, 0, len(refs))
+	for _, ref := range refs {
+		items = append(items, Reference{Ref: ref})
+	}
+	return &Collection{Items: items}
+}
+
+// IsWritable reports whether the collection should be sent on create or update.
+func (c *Collection) IsWritable() bool {
+	return c != nil && c.Items != nil
+}
+
+type collectionSummary struct {
+	Count int    `json:",omitempty"`
+	Ref   string `json:"_ref,omitempty"`
+	Type  string `json:"_type,omitempty"`
+}
+
+// MarshalJSON writes Items as an array of refs, or the summary if there are none.
+func (c Collection) MarshalJSON() ([]byte, error) {
+	if c.Items != nil {
+		refs := make([]Reference, 0, len(c.Items))
+		for _, item := range c.Items {
+			refs = append(refs, Reference{Ref: item.Ref})
+		}
+		return json.Marshal(refs)
+	}
+	return json.Marshal(collectionSummary{Count: c.Count, Ref: c.Ref, Type: c.Type})
+}
+
+// UnmarshalJSON accepts both the collection summary and an array of objects.
+func (c *Collection) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if bytes.HasPrefix(data, []byte("[")) {
+		var items []Reference
+		if err := json.Unmarshal(data, &items); err != nil {
+			return err
+		}
+		*c = Collection{Count: len(items), Items: items}
+		return nil
+	}
+
+	var summary collectionSummary
+	if err := json.Unmarshal(data, &summary); err != nil {
+		return err
+	}
+	*c = Collection{Count: summary.Count, Ref: summary.Ref, Type: summary.Type}
+	return nil
+}
@@ -0,0 +1,63 @@
+/**
+* Copyright 2014 Comcast Cable Communications Management, LLC
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+* http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package models_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/aleksofficial/go-rally-rest-toolkit/models"
+)
+
+func TestCollection_UnmarshalSummary(t *testing.T) {
+	var c models.Collection
+	err := json.Unmarshal([]byte(`{"_ref": "https://rally1.rallydev.com/slm/webservice/v2.0/Defect/1/Milestones", "_type": "Milestone", "Count": 3}`), &c)
+	if err != nil {
+		t.Fatalf("Unmarshal failed unexpectedly: %v", err)
+	}
+	if c.Count != 3 || c.Type != "Milestone" || len(c.Items) != 0 {
+		t.Errorf("unexpected collection: %+v", c)
+	}
+	if c.IsWritable() {
+		t.Error("expected a read-back summary not to be writable")
+	}
+}
+
+func TestCollection_UnmarshalArray(t *testing.T) {
+	var c models.Collection
+	err := json.Unmarshal([]byte(`[{"_ref": "/milestone/1"}, {"_ref": "/milestone/2"}]`), &c)
+	if err != nil {
+		t.Fatalf("Unmarshal failed unexpectedly: %v", err)
+	}
+	if c.Count != 2 || len(c.Items) != 2 || c.Items[1].Ref != "/milestone/2" {
+		t.Errorf("unexpected collection: %+v", c)
+	}
+}
+
+func TestNewCollection_MarshalsRefs(t *testing.T) {
+	c := models.NewCollection("/milestone/1", "/milestone/2")
+	if !c.IsWritable() {
+		t.Fatal("expected a collection built from refs to be writable")
+	}
+	b, err := json.Marshal(c)
+	if err != nil {
+		t.Fatalf("Marshal failed unexpectedly: %v", err)
+	}
+	if string(b) != `[{"_ref":"/milestone/1"},{"_ref":"/milestone/2"}]` {
+		t.Errorf("unexpected JSON: %s", b)
+	}
+}
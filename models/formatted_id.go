@@ -0,0 +1,87 @@
+/**
+* Copyright 2014 Comcast Cable Communications Management, LLC
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+* http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidFormattedID is returned when a string is not shaped like a
+// FormattedID, i.e. a letter prefix followed by a number ("US1234").
+var ErrInvalidFormattedID = errors.New("invalid FormattedID")
+
+// ErrUnknownFormattedIDPrefix is returned when a FormattedID prefix does not map
+// to a known artifact type.
+var ErrUnknownFormattedIDPrefix = errors.New("unknown FormattedID prefix")
+
+// formattedIDPrefixes maps the default Rally FormattedID prefixes to the WSAPI
+// type path used to query them.
+var formattedIDPrefixes = map[string]string{
+	"US": "hierarchicalrequirement",
+	"DE": "defect",
+	"TA": "task",
+	"TC": "testcase",
+	"F":  "portfolioitem/feature",
+	"I":  "portfolioitem/initiative",
+}
+
+// ParseFormattedID splits a FormattedID such as "US1234" into its upper-cased
+// prefix ("US") and number (1234).
+func ParseFormattedID(s string) (prefix string, number int, err error) {
+	s = strings.TrimSpace(s)
+	i := strings.IndexFunc(s, func(r rune) bool { return !isLetter(r) })
+	if i <= 0 || !isDigit(s[i]) {
+		return "", 0, fmt.Errorf("%w: %q", ErrInvalidFormattedID, s)
+	}
+
+	number, err = strconv.Atoi(s[i:])
+	if err != nil {
+		return "", 0, fmt.Errorf("%w: %q", ErrInvalidFormattedID, s)
+	}
+	return strings.ToUpper(s[:i]), number, nil
+}
+
+func isLetter(r rune) bool {
+	return (r >= 'A' && r <= 'Z') || (r >= 'a' && r <= 'z')
+}
+
+func isDigit(b byte) bool {
+	return b >= '0' && b <= '9'
+}
+
+// ArtifactTypeForPrefix returns the WSAPI type path for a FormattedID prefix,
+// e.g. "DE" -> "defect" and "F" -> "portfolioitem/feature".
+func ArtifactTypeForPrefix(prefix string) (string, error) {
+	artifactType, ok := formattedIDPrefixes[strings.ToUpper(prefix)]
+	if !ok {
+		return "", fmt.Errorf("%w: %q", ErrUnknownFormattedIDPrefix, prefix)
+	}
+	return artifactType, nil
+}
+
+// ArtifactTypeForFormattedID returns the WSAPI type path for a full FormattedID,
+// e.g. "US1234" -> "hierarchicalrequirement".
+func ArtifactTypeForFormattedID(formattedID string) (string, error) {
+	prefix, _, err := ParseFormattedID(formattedID)
+	if err != nil {
+		return "", err
+	}
+	return ArtifactTypeForPrefix(prefix)
+}
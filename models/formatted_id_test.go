@@ -0,0 +1,105 @@
+/**
+* Copyright 2014 Comcast Cable Communications Management, LLC
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+* http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package models_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aleksofficial/go-rally-rest-toolkit/models"
+)
+
+func TestParseFormattedID(t *testing.T) {
+	tests := []struct {
+		input  string
+		prefix string
+		number int
+	}{
+		{"US1234", "US", 1234},
+		{"DE99", "DE", 99},
+		{"TA7", "TA", 7},
+		{"TC4410", "TC", 4410},
+		{"F42", "F", 42},
+		{"I3", "I", 3},
+		{"us12", "US", 12},
+		{" DE5 ", "DE", 5},
+	}
+
+	for _, tt := range tests {
+		prefix, number, err := models.ParseFormattedID(tt.input)
+		if err != nil {
+			t.Errorf("ParseFormattedID(%q) failed unexpectedly: %v", tt.input, err)
+			continue
+		}
+		if prefix != tt.prefix || number != tt.number {
+			t.Errorf("ParseFormattedID(%q) = (%q, %d), expected (%q, %d)", tt.input, prefix, number, tt.prefix, tt.number)
+		}
+	}
+}
+
+func TestParseFormattedID_Invalid(t *testing.T) {
+	for _, input := range []string{"", "1234", "US", "US-1", "US+1", "US12a", "U S12"} {
+		if _, _, err := models.ParseFormattedID(input); !errors.Is(err, models.ErrInvalidFormattedID) {
+			t.Errorf("ParseFormattedID(%q): expected ErrInvalidFormattedID, got %v", input, err)
+		}
+	}
+}
+
+func TestArtifactTypeForPrefix(t *testing.T) {
+	tests := []struct {
+		prefix       string
+		artifactType string
+	}{
+		{"US", "hierarchicalrequirement"},
+		{"DE", "defect"},
+		{"TA", "task"},
+		{"TC", "testcase"},
+		{"F", "portfolioitem/feature"},
+		{"I", "portfolioitem/initiative"},
+		{"de", "defect"},
+	}
+
+	for _, tt := range tests {
+		artifactType, err := models.ArtifactTypeForPrefix(tt.prefix)
+		if err != nil {
+			t.Errorf("ArtifactTypeForPrefix(%q) failed unexpectedly: %v", tt.prefix, err)
+			continue
+		}
+		if artifactType != tt.artifactType {
+			t.Errorf("ArtifactTypeForPrefix(%q) = %q, expected %q", tt.prefix, artifactType, tt.artifactType)
+		}
+	}
+}
+
+func TestArtifactTypeForPrefix_Unknown(t *testing.T) {
+	if _, err := models.ArtifactTypeForPrefix("XY"); !errors.Is(err, models.ErrUnknownFormattedIDPrefix) {
+		t.Errorf("expected ErrUnknownFormattedIDPrefix, got %v", err)
+	}
+	if _, err := models.ArtifactTypeForFormattedID("XY12"); !errors.Is(err, models.ErrUnknownFormattedIDPrefix) {
+		t.Errorf("expected ErrUnknownFormattedIDPrefix, got %v", err)
+	}
+}
+
+func TestArtifactTypeForFormattedID(t *testing.T) {
+	artifactType, err := models.ArtifactTypeForFormattedID("US1234")
+	if err != nil {
+		t.Fatalf("ArtifactTypeForFormattedID failed unexpectedly: %v", err)
+	}
+	if artifactType != "hierarchicalrequirement" {
+		t.Errorf("expected hierarchicalrequirement, got %q", artifactType)
+	}
+}
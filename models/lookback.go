@@ -0,0 +1,36 @@
+/**
+* Copyright 2014 Comcast Cable Communications Management, LLC
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+* http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package models
+
+// Snapshot is one Lookback API snapshot: the fields of an artifact as they
+// were between its _ValidFrom and _ValidTo dates, keyed by field name.
+type Snapshot map[string]interface{}
+
+// LookbackResult is a page of Lookback API snapshots. Unlike WSAPI queries,
+// StartIndex is 0-based.
+type LookbackResult struct {
+	RallyAPIMajor    string `json:"_rallyAPIMajor,omitempty"`
+	RallyAPIMinor    string `json:"_rallyAPIMinor,omitempty"`
+	Results          []Snapshot
+	TotalResultCount int
+	StartIndex       int
+	PageSize         int
+	// ETLDate is when the snapshots were last brought up to date
+	ETLDate  string
+	Errors   []string
+	Warnings []string
+}
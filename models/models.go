@@ -16,6 +16,61 @@
 
 package models
 
+// Bool returns a pointer to v, for setting optional boolean fields such as
+// Blocked or Ready.
+func Bool(v bool) *bool {
+	return &v
+}
+
+// String returns a pointer to v, for setting optional string fields such as
+// BlockedReason. A pointer to the empty string clears the field in Rally.
+func String(v string) *string {
+	return &v
+}
+
+// PersistableObject holds the identity and metadata fields every Rally object
+// carries. It is embedded in each model.
+//
+// All of its fields are assigned by Rally: they are populated on reads and
+// never sent on creates or updates. ObjectVersion changes on every update, so
+// comparing it with a fresh read detects a stale copy.
+type PersistableObject struct {
+	RallyAPIMajor string   `json:"_rallyAPIMajor,omitempty" rally:"readonly"`
+	RallyAPIMinor string   `json:"_rallyAPIMinor,omitempty" rally:"readonly"`
+	Ref           string   `json:"_ref,omitempty" rally:"readonly"`
+	RefObjectName string   `json:"_refObjectName,omitempty" rally:"readonly"`
+	RefObjectUUID string   `json:"_refObjectUUID,omitempty" rally:"readonly"`
+	ObjectVersion string   `json:"_objectVersion,omitempty" rally:"readonly"`
+	CreatedAt     string   `json:"_CreatedAt,omitempty" rally:"readonly"`
+	VersionId     string   `json:",omitempty" rally:"readonly"`
+	Type          string   `json:"_type,omitempty" rally:"readonly"`
+	CreationDate  string   `json:",omitempty" rally:"readonly"`
+	ObjectID      int      `json:",omitempty" rally:"readonly"`
+	ObjectUUID    string   `json:",omitempty" rally:"readonly"`
+	Errors        []string `json:",omitempty" rally:"readonly"`
+	Warnings      []string `json:",omitempty" rally:"readonly"`
+}
+
+// GetRef returns the object's _ref URL.
+func (p PersistableObject) GetRef() string {
+	return p.Ref
+}
+
+// GetType returns the object's _type, e.g. "HierarchicalRequirement".
+func (p PersistableObject) GetType() string {
+	return p.Type
+}
+
+// GetObjectID returns the object's ObjectID.
+func (p PersistableObject) GetObjectID() int {
+	return p.ObjectID
+}
+
+// GetObjectVersion returns the object's _objectVersion.
+func (p PersistableObject) GetObjectVersion() string {
+	return p.ObjectVersion
+}
+
 type Reference struct {
 	Count         int    `json:",omitempty"`
 	Ref           string `json:"_ref,omitempty"`
@@ -25,72 +80,100 @@ type Reference struct {
 }
 
 type Defect struct {
-	Ref                 string     `json:"_ref,omitempty"`
-	CreationDate        string     `json:",omitempty"`
-	ObjectID            int        `json:",omitempty"`
-	ObjectUUID          string     `json:",omitempty"`
-	Subscription        *Reference `json:",omitempty"`
-	Workspace           *Reference `json:",omitempty"`
-	Changesets          *Reference `json:",omitempty"`
-	Requirement         *Reference `json:",omitempty"`
-	Description         string     `json:",omitempty"`
-	FormattedID         string     `json:",omitempty"`
-	Name                string     `json:",omitempty"`
-	Notes               string     `json:",omitempty"`
-	Owner               *Reference `json:",omitempty"`
-	Project             *Reference `json:",omitempty"`
-	LastBuild           string     `json:",omitempty"`
-	LastRun             string     `json:",omitempty"`
-	ScheduleState       string     `json:",omitempty"`
-	ScheduleStatePrefix string     `json:",omitempty"`
-	Iteration           *Reference `json:",omitempty"`
-	State               string     `json:",omitempty"`
-	Priority            string     `json:",omitempty"`
-	Severity            string     `json:",omitempty"`
-	Tasks               *Reference `json:",omitempty"`
-	Resolution          string     `json:",omitempty"`
+	PersistableObject
+	Subscription        *Reference  `json:",omitempty"`
+	Workspace           *Reference  `json:",omitempty"`
+	Changesets          *Reference  `json:",omitempty"`
+	Requirement         *Reference  `json:",omitempty"`
+	Description         string      `json:",omitempty"`
+	FormattedID         string      `json:",omitempty" rally:"readonly"`
+	Name                string      `json:",omitempty"`
+	Notes               string      `json:",omitempty"`
+	Owner               *Reference  `json:",omitempty"`
+	Project             *Reference  `json:",omitempty"`
+	LastBuild           string      `json:",omitempty"`
+	LastRun             string      `json:",omitempty"`
+	ScheduleState       string      `json:",omitempty"`
+	ScheduleStatePrefix string      `json:",omitempty"`
+	Iteration           *Reference  `json:",omitempty"`
+	State               string      `json:",omitempty"`
+	Priority            string      `json:",omitempty"`
+	Severity            string      `json:",omitempty"`
+	Tasks               *Reference  `json:",omitempty"`
+	Resolution          string      `json:",omitempty"`
+	DragAndDropRank     string      `json:",omitempty" rally:"readonly"`
+	Milestones          *Collection `json:",omitempty"`
+	Duplicates          *Collection `json:",omitempty"`
+	Discussion          *Reference  `json:",omitempty" rally:"readonly"`
+	Attachments         *Reference  `json:",omitempty" rally:"readonly"`
+	Blocked             *bool       `json:",omitempty"`
+	BlockedReason       *string     `json:",omitempty"`
+	Ready               *bool       `json:",omitempty"`
 }
 
+// Default defect states, in the order a defect moves through them.
+const (
+	DefectStateSubmitted = "Submitted"
+	DefectStateOpen      = "Open"
+	DefectStateFixed     = "Fixed"
+	DefectStateClosed    = "Closed"
+)
+
+// Schedule states, in the order a story or defect moves through them.
+const (
+	ScheduleStateDefined    = "Defined"
+	ScheduleStateInProgress = "In-Progress"
+	ScheduleStateCompleted  = "Completed"
+	ScheduleStateAccepted   = "Accepted"
+)
+
 type HierarchicalRequirement struct {
-	Ref                 string     `json:"_ref,omitempty"`
-	Project             *Reference `json:",omitempty"`
-	CreationDate        string     `json:",omitempty"`
-	ObjectID            int        `json:",omitempty"`
-	ObjectUUID          string     `json:",omitempty"`
-	Subscription        *Reference `json:",omitempty"`
-	Workspace           *Reference `json:",omitempty"`
-	Changesets          *Reference `json:",omitempty"`
-	Description         string     `json:",omitempty"`
-	FormattedID         string     `json:",omitempty"`
-	Name                string     `json:",omitempty"`
-	LastBuild           string     `json:",omitempty"`
-	LastRun             string     `json:",omitempty"`
-	ScheduleState       string     `json:",omitempty"`
-	ScheduleStatePrefix string     `json:",omitempty"`
-	AcceptedDate        string     `json:",omitempty"`
-	InProgressDate      string     `json:",omitempty"`
-	Tasks               *Reference `json:",omitempty"`
+	PersistableObject
+	Project             *Reference  `json:",omitempty"`
+	Subscription        *Reference  `json:",omitempty"`
+	Workspace           *Reference  `json:",omitempty"`
+	Changesets          *Reference  `json:",omitempty"`
+	Description         string      `json:",omitempty"`
+	FormattedID         string      `json:",omitempty" rally:"readonly"`
+	Name                string      `json:",omitempty"`
+	Owner               *Reference  `json:",omitempty"`
+	LastBuild           string      `json:",omitempty"`
+	LastRun             string      `json:",omitempty"`
+	ScheduleState       string      `json:",omitempty"`
+	ScheduleStatePrefix string      `json:",omitempty"`
+	AcceptedDate        string      `json:",omitempty"`
+	InProgressDate      string      `json:",omitempty"`
+	Tasks               *Reference  `json:",omitempty"`
+	Defects             *Reference  `json:",omitempty" rally:"readonly"`
+	TestCases           *Reference  `json:",omitempty" rally:"readonly"`
+	Children            *Reference  `json:",omitempty" rally:"readonly"`
+	DragAndDropRank     string      `json:",omitempty" rally:"readonly"`
+	Milestones          *Collection `json:",omitempty"`
+	Discussion          *Reference  `json:",omitempty" rally:"readonly"`
+	Attachments         *Reference  `json:",omitempty" rally:"readonly"`
+	Blocked             *bool       `json:",omitempty"`
+	BlockedReason       *string     `json:",omitempty"`
+	Ready               *bool       `json:",omitempty"`
 }
 
 type Task struct {
-	Ref             string     `json:"_ref,omitempty"`
-	CreationDate    string     `json:",omitempty"`
-	ObjectID        int        `json:",omitempty"`
-	ObjectUUID      string     `json:",omitempty"`
+	PersistableObject
 	Subscription    *Reference `json:",omitempty"`
 	Workspace       *Reference `json:",omitempty"`
 	Changesets      *Reference `json:",omitempty"`
-	FormattedID     string     `json:",omitempty"`
+	FormattedID     string     `json:",omitempty" rally:"readonly"`
 	Name            string     `json:",omitempty"`
 	Description     string     `json:",omitempty"`
 	Actuals         float32    `json:",omitempty"`
 	Attachments     *Reference `json:",omitempty"`
 	Blocked         bool       `json:",omitempty"`
 	BlockedReason   string     `json:",omitempty"`
-	DragAndDropRank string     `json:",omitempty"`
+	DragAndDropRank string     `json:",omitempty" rally:"readonly"`
 	Estimate        float32    `json:",omitempty"`
 	Iteration       *Reference `json:",omitempty"`
+	Owner           *Reference `json:",omitempty"`
 	Project         *Reference `json:",omitempty"`
+	Ready           bool       `json:",omitempty"`
 	Recycled        bool       `json:",omitempty"`
 	Release         *Reference `json:",omitempty"`
 	State           string     `json:",omitempty"`
@@ -101,10 +184,7 @@ type Task struct {
 }
 
 type BuildDefinition struct {
-	Ref          string     `json:"_ref,omitempty"`
-	CreationDate string     `json:",omitempty"`
-	ObjectID     int        `json:",omitempty"`
-	ObjectUUID   string     `json:",omitempty"`
+	PersistableObject
 	Subscription *Reference `json:",omitempty"`
 	Workspace    *Reference `json:",omitempty"`
 	Builds       *Reference `json:",omitempty"`
@@ -118,27 +198,29 @@ type BuildDefinition struct {
 }
 
 type Build struct {
-	Ref             string       `json:"_ref,omitempty"`
-	CreationDate    string       `json:",omitempty"`
-	ObjectID        int          `json:",omitempty"`
-	ObjectUUID      string       `json:",omitempty"`
-	Subscription    *Reference   `json:",omitempty"`
-	Workspace       *Reference   `json:",omitempty"`
-	BuildDefinition *Reference   `json:",omitempty"`
-	Changesets      []*Reference `json:",omitempty"`
-	Number          string       `json:",omitempty"`
-	Duration        float32      `json:",omitempty"`
-	Start           string       `json:",omitempty"`
-	Message         string       `json:",omitempty"`
-	Status          string       `json:",omitempty"`
-	Uri             string       `json:",omitempty"`
+	PersistableObject
+	Subscription    *Reference  `json:",omitempty"`
+	Workspace       *Reference  `json:",omitempty"`
+	BuildDefinition *Reference  `json:",omitempty"`
+	Changesets      *Collection `json:",omitempty"`
+	Number          string      `json:",omitempty"`
+	Duration        float64     `json:",omitempty"`
+	Start           string      `json:",omitempty"`
+	Message         string      `json:",omitempty"`
+	Status          string      `json:",omitempty"`
+	Uri             string      `json:",omitempty"`
 }
 
+// Build statuses accepted by Rally.
+const (
+	BuildStatusSuccess    = "SUCCESS"
+	BuildStatusFailure    = "FAILURE"
+	BuildStatusIncomplete = "INCOMPLETE"
+	BuildStatusUnknown    = "UNKNOWN"
+)
+
 type Changeset struct {
-	Ref             string     `json:"_ref,omitempty"`
-	CreationDate    string     `json:",omitempty"`
-	ObjectID        int        `json:",omitempty"`
-	ObjectUUID      string     `json:",omitempty"`
+	PersistableObject
 	Subscription    *Reference `json:",omitempty"`
 	Workspace       *Reference `json:",omitempty"`
 	Artifacts       *Reference `json:",omitempty"`
@@ -153,3 +235,181 @@ type Changeset struct {
 	SCMRepository   *Reference `json:",omitempty"`
 	Uri             string     `json:",omitempty"`
 }
+
+type PortfolioItem struct {
+	PersistableObject
+	Subscription                   *Reference  `json:",omitempty"`
+	Workspace                      *Reference  `json:",omitempty"`
+	Project                        *Reference  `json:",omitempty"`
+	PortfolioItemType              *Reference  `json:",omitempty"`
+	FormattedID                    string      `json:",omitempty" rally:"readonly"`
+	Name                           string      `json:",omitempty"`
+	Description                    string      `json:",omitempty"`
+	Notes                          string      `json:",omitempty"`
+	Owner                          *Reference  `json:",omitempty"`
+	State                          *Reference  `json:",omitempty"`
+	Parent                         *Reference  `json:",omitempty"`
+	Children                       *Reference  `json:",omitempty"`
+	UserStories                    *Reference  `json:",omitempty"`
+	DragAndDropRank                string      `json:",omitempty" rally:"readonly"`
+	Milestones                     *Collection `json:",omitempty"`
+	PreliminaryEstimate            *Reference  `json:",omitempty"`
+	RefinedEstimate                float64     `json:",omitempty"`
+	PercentDoneByStoryCount        float64     `json:",omitempty" rally:"readonly"`
+	PercentDoneByStoryPlanEstimate float64     `json:",omitempty" rally:"readonly"`
+	InvestmentCategory             string      `json:",omitempty"`
+	ValueScore                     int         `json:",omitempty"`
+	RiskScore                      int         `json:",omitempty"`
+	PlannedStartDate               *Time       `json:",omitempty"`
+	PlannedEndDate                 *Time       `json:",omitempty"`
+}
+
+type Attachment struct {
+	PersistableObject
+	Subscription *Reference `json:",omitempty"`
+	Workspace    *Reference `json:",omitempty"`
+	Name         string     `json:",omitempty"`
+	Description  string     `json:",omitempty"`
+	Size         int64      `json:",omitempty"`
+	ContentType  string     `json:",omitempty"`
+	Content      *Reference `json:",omitempty"`
+	Artifact     *Reference `json:",omitempty"`
+	User         *Reference `json:",omitempty"`
+}
+
+type Subscription struct {
+	PersistableObject
+	Name            string      `json:",omitempty"`
+	SubscriptionID  int         `json:",omitempty"`
+	ExpirationDate  string      `json:",omitempty"`
+	Workspaces      *Collection `json:",omitempty"`
+	MaximumProjects int         `json:",omitempty"`
+}
+
+type TestCase struct {
+	PersistableObject
+	Subscription    *Reference `json:",omitempty"`
+	Workspace       *Reference `json:",omitempty"`
+	Project         *Reference `json:",omitempty"`
+	FormattedID     string     `json:",omitempty" rally:"readonly"`
+	Name            string     `json:",omitempty"`
+	Description     string     `json:",omitempty"`
+	Notes           string     `json:",omitempty"`
+	Objective       string     `json:",omitempty"`
+	PreConditions   string     `json:",omitempty"`
+	PostConditions  string     `json:",omitempty"`
+	Owner           *Reference `json:",omitempty"`
+	WorkProduct     *Reference `json:",omitempty"`
+	TestCaseType    string     `json:"Type,omitempty"`
+	Method          string     `json:",omitempty"`
+	Priority        string     `json:",omitempty"`
+	Risk            string     `json:",omitempty"`
+	LastVerdict     string     `json:",omitempty" rally:"readonly"`
+	LastRun         *string    `json:",omitempty" rally:"readonly"`
+	LastBuild       string     `json:",omitempty" rally:"readonly"`
+	Results         *Reference `json:",omitempty" rally:"readonly"`
+	Steps           *Reference `json:",omitempty"`
+	DragAndDropRank string     `json:",omitempty" rally:"readonly"`
+}
+
+type Iteration struct {
+	PersistableObject
+	Subscription    *Reference `json:",omitempty"`
+	Workspace       *Reference `json:",omitempty"`
+	Project         *Reference `json:",omitempty"`
+	Name            string     `json:",omitempty"`
+	StartDate       *Time      `json:",omitempty"`
+	EndDate         *Time      `json:",omitempty"`
+	State           string     `json:",omitempty"`
+	PlannedVelocity float64    `json:",omitempty"`
+	Theme           string     `json:",omitempty"`
+	Notes           string     `json:",omitempty"`
+}
+
+// Iteration states, in the order an iteration moves through them.
+const (
+	IterationStatePlanning  = "Planning"
+	IterationStateCommitted = "Committed"
+	IterationStateAccepted  = "Accepted"
+)
+
+type Release struct {
+	PersistableObject
+	Subscription                 *Reference `json:",omitempty"`
+	Workspace                    *Reference `json:",omitempty"`
+	Project                      *Reference `json:",omitempty"`
+	Name                         string     `json:",omitempty"`
+	ReleaseStartDate             *Time      `json:",omitempty"`
+	ReleaseDate                  *Time      `json:",omitempty"`
+	State                        string     `json:",omitempty"`
+	Theme                        string     `json:",omitempty"`
+	PlannedVelocity              float64    `json:",omitempty"`
+	Version                      string     `json:",omitempty"`
+	GrossEstimateConversionRatio float64    `json:",omitempty"`
+	Notes                        string     `json:",omitempty"`
+}
+
+type User struct {
+	PersistableObject
+	Subscription   *Reference `json:",omitempty"`
+	UserName       string     `json:",omitempty"`
+	EmailAddress   string     `json:",omitempty"`
+	DisplayName    string     `json:",omitempty"`
+	FirstName      string     `json:",omitempty"`
+	LastName       string     `json:",omitempty"`
+	Disabled       bool       `json:",omitempty" rally:"readonly"`
+	Role           string     `json:",omitempty"`
+	OfficeLocation string     `json:",omitempty"`
+	LastLoginDate  *Time      `json:",omitempty" rally:"readonly"`
+}
+
+// Webhook is a Rally webhook subscription. Webhooks live outside WSAPI and are
+// identified by ObjectUUID.
+type Webhook struct {
+	ObjectID     int                 `json:",omitempty"`
+	ObjectUUID   string              `json:",omitempty"`
+	Name         string              `json:",omitempty"`
+	AppName      string              `json:",omitempty"`
+	AppUrl       string              `json:",omitempty"`
+	TargetUrl    string              `json:",omitempty"`
+	ObjectTypes  []string            `json:",omitempty"`
+	Expressions  []WebhookExpression `json:",omitempty"`
+	Enabled      bool
+	Security     string `json:",omitempty"`
+	CreatedAt    string `json:",omitempty"`
+	LastUpdateAt string `json:",omitempty"`
+}
+
+// WebhookExpression is one condition an event must match to fire a Webhook,
+// e.g. {AttributeName: "State", Operator: "=", Value: "Open"}.
+type WebhookExpression struct {
+	AttributeID   string      `json:",omitempty"`
+	AttributeName string      `json:",omitempty"`
+	Operator      string      `json:",omitempty"`
+	Value         interface{} `json:",omitempty"`
+}
+
+// WebhookList is the response to listing webhooks.
+type WebhookList struct {
+	Results          []Webhook
+	TotalResultCount int
+	StartIndex       int
+	PageSize         int
+}
+
+// Suggestion is the lightweight form of an artifact returned by Suggest for
+// autocomplete.
+type Suggestion struct {
+	ObjectID    int    `json:",omitempty"`
+	FormattedID string `json:",omitempty"`
+	Name        string `json:",omitempty"`
+}
+
+// SearchResult is one match from a keyword search, in relevance order. Type
+// holds the artifact type, e.g. "Defect" or "HierarchicalRequirement".
+type SearchResult struct {
+	PersistableObject
+	FormattedID  string `json:",omitempty"`
+	Name         string `json:",omitempty"`
+	MatchingText string `json:",omitempty"`
+}
@@ -0,0 +1,223 @@
+/**
+* Copyright 2014 Comcast Cable Communications Management, LLC
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+* http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package models_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/aleksofficial/go-rally-rest-toolkit/models"
+)
+
+const defectFixture = `{
+	"_rallyAPIMajor": "2",
+	"_rallyAPIMinor": "0",
+	"_ref": "https://rally1.rallydev.com/slm/webservice/v2.0/defect/50137325678",
+	"_refObjectUUID": "0b9a2c1e-6f7a-4a5e-9d8c-3c2b1a0f9e8d",
+	"_objectVersion": "12",
+	"_refObjectName": "Login button unresponsive",
+	"_type": "Defect",
+	"CreationDate": "2016-01-21T21:47:08.551Z",
+	"ObjectID": 50137325678,
+	"ObjectUUID": "0b9a2c1e-6f7a-4a5e-9d8c-3c2b1a0f9e8d",
+	"FormattedID": "DE624340",
+	"Name": "Login button unresponsive",
+	"State": "Open",
+	"Errors": [],
+	"Warnings": []
+}`
+
+func TestPersistableObject_UnmarshalDefect(t *testing.T) {
+	var defect models.Defect
+	if err := json.Unmarshal([]byte(defectFixture), &defect); err != nil {
+		t.Fatalf("failed to unmarshal defect: %v", err)
+	}
+
+	if defect.GetRef() != "https://rally1.rallydev.com/slm/webservice/v2.0/defect/50137325678" {
+		t.Errorf("unexpected _ref: %q", defect.GetRef())
+	}
+	if defect.GetType() != "Defect" {
+		t.Errorf("expected _type=Defect, got %q", defect.GetType())
+	}
+	if defect.GetObjectID() != 50137325678 {
+		t.Errorf("expected ObjectID=50137325678, got %d", defect.GetObjectID())
+	}
+	if defect.ObjectVersion != "12" {
+		t.Errorf("expected _objectVersion=12, got %q", defect.ObjectVersion)
+	}
+	if defect.RefObjectUUID != "0b9a2c1e-6f7a-4a5e-9d8c-3c2b1a0f9e8d" {
+		t.Errorf("unexpected _refObjectUUID: %q", defect.RefObjectUUID)
+	}
+	if defect.CreationDate != "2016-01-21T21:47:08.551Z" {
+		t.Errorf("unexpected CreationDate: %q", defect.CreationDate)
+	}
+	if defect.FormattedID != "DE624340" {
+		t.Errorf("expected FormattedID=DE624340, got %q", defect.FormattedID)
+	}
+}
+
+func TestPersistableObject_UnmarshalStory(t *testing.T) {
+	fixture := `{"_ref": "https://rally1.rallydev.com/slm/webservice/v2.0/hierarchicalrequirement/29227987232", "_type": "HierarchicalRequirement", "ObjectID": 29227987232, "Warnings": ["It is no longer necessary to append \".js\" to WSAPI resources."]}`
+
+	var story models.HierarchicalRequirement
+	if err := json.Unmarshal([]byte(fixture), &story); err != nil {
+		t.Fatalf("failed to unmarshal story: %v", err)
+	}
+	if story.GetType() != "HierarchicalRequirement" {
+		t.Errorf("expected _type=HierarchicalRequirement, got %q", story.GetType())
+	}
+	if story.Ref != "https://rally1.rallydev.com/slm/webservice/v2.0/hierarchicalrequirement/29227987232" {
+		t.Errorf("unexpected _ref: %q", story.Ref)
+	}
+	if len(story.Warnings) != 1 {
+		t.Errorf("expected 1 warning, got %v", story.Warnings)
+	}
+}
+
+const attachmentFixture = `{
+	"_rallyAPIMajor": "2",
+	"_rallyAPIMinor": "0",
+	"_ref": "https://rally1.rallydev.com/slm/webservice/v2.0/attachment/61234567890",
+	"_type": "Attachment",
+	"ObjectID": 61234567890,
+	"CreationDate": "2016-03-02T10:15:00.000Z",
+	"Name": "heap-dump.hprof",
+	"Description": "Heap dump captured during the outage",
+	"Size": 3221225472,
+	"ContentType": "application/octet-stream",
+	"Content": {"_ref": "https://rally1.rallydev.com/slm/webservice/v2.0/attachmentcontent/61234567891", "_type": "AttachmentContent"},
+	"Artifact": {"_ref": "https://rally1.rallydev.com/slm/webservice/v2.0/defect/50137325678", "_type": "Defect"},
+	"User": {"_ref": "https://rally1.rallydev.com/slm/webservice/v2.0/user/12345", "_type": "User"},
+	"Errors": [],
+	"Warnings": []
+}`
+
+func TestAttachment_Unmarshal(t *testing.T) {
+	var attachment models.Attachment
+	if err := json.Unmarshal([]byte(attachmentFixture), &attachment); err != nil {
+		t.Fatalf("failed to unmarshal attachment: %v", err)
+	}
+
+	if attachment.GetObjectID() != 61234567890 {
+		t.Errorf("expected ObjectID=61234567890, got %d", attachment.GetObjectID())
+	}
+	if attachment.Name != "heap-dump.hprof" {
+		t.Errorf("unexpected Name: %q", attachment.Name)
+	}
+	if attachment.Description != "Heap dump captured during the outage" {
+		t.Errorf("unexpected Description: %q", attachment.Description)
+	}
+	if attachment.Size != 3221225472 {
+		t.Errorf("expected Size=3221225472, got %d", attachment.Size)
+	}
+	if attachment.ContentType != "application/octet-stream" {
+		t.Errorf("unexpected ContentType: %q", attachment.ContentType)
+	}
+	if attachment.Content == nil || attachment.Content.Ref != "https://rally1.rallydev.com/slm/webservice/v2.0/attachmentcontent/61234567891" {
+		t.Errorf("unexpected Content: %+v", attachment.Content)
+	}
+	if attachment.Artifact == nil || attachment.Artifact.Ref != "https://rally1.rallydev.com/slm/webservice/v2.0/defect/50137325678" {
+		t.Errorf("unexpected Artifact: %+v", attachment.Artifact)
+	}
+	if attachment.User == nil || attachment.User.Ref != "https://rally1.rallydev.com/slm/webservice/v2.0/user/12345" {
+		t.Errorf("unexpected User: %+v", attachment.User)
+	}
+}
+
+func TestAttachment_RoundTripContentType(t *testing.T) {
+	var attachment models.Attachment
+	if err := json.Unmarshal([]byte(attachmentFixture), &attachment); err != nil {
+		t.Fatalf("failed to unmarshal attachment: %v", err)
+	}
+
+	b, err := json.Marshal(attachment)
+	if err != nil {
+		t.Fatalf("failed to marshal attachment: %v", err)
+	}
+	var again models.Attachment
+	if err := json.Unmarshal(b, &again); err != nil {
+		t.Fatalf("failed to unmarshal round-tripped attachment: %v", err)
+	}
+	if again.ContentType != attachment.ContentType || again.Size != attachment.Size {
+		t.Errorf("round trip changed ContentType/Size: got %q/%d, want %q/%d", again.ContentType, again.Size, attachment.ContentType, attachment.Size)
+	}
+}
+
+const testCasesFixture = `[
+	{
+		"_ref": "https://rally1.rallydev.com/slm/webservice/v2.0/testcase/70000000001",
+		"_type": "TestCase",
+		"ObjectID": 70000000001,
+		"FormattedID": "TC101",
+		"Name": "Login rejects expired password",
+		"Method": "Manual",
+		"Priority": "Important",
+		"Risk": "Medium",
+		"LastVerdict": null,
+		"LastRun": null,
+		"LastBuild": null
+	},
+	{
+		"_ref": "https://rally1.rallydev.com/slm/webservice/v2.0/testcase/70000000002",
+		"_type": "TestCase",
+		"ObjectID": 70000000002,
+		"FormattedID": "TC102",
+		"Name": "Login accepts valid password",
+		"Type": "Acceptance",
+		"Method": "Automated",
+		"Priority": "Critical",
+		"Risk": "High",
+		"LastVerdict": "Pass",
+		"LastRun": "2016-04-12T08:30:00.000Z",
+		"LastBuild": "build-1432"
+	}
+]`
+
+func TestTestCase_UnmarshalLastRun(t *testing.T) {
+	var testCases []models.TestCase
+	if err := json.Unmarshal([]byte(testCasesFixture), &testCases); err != nil {
+		t.Fatalf("failed to unmarshal test cases: %v", err)
+	}
+	if len(testCases) != 2 {
+		t.Fatalf("expected 2 test cases, got %d", len(testCases))
+	}
+
+	neverRun := testCases[0]
+	if neverRun.LastRun != nil {
+		t.Errorf("expected nil LastRun for a never-run test case, got %q", *neverRun.LastRun)
+	}
+	if neverRun.LastVerdict != "" || neverRun.LastBuild != "" {
+		t.Errorf("expected empty LastVerdict/LastBuild, got %q/%q", neverRun.LastVerdict, neverRun.LastBuild)
+	}
+	if neverRun.Method != "Manual" || neverRun.Priority != "Important" || neverRun.Risk != "Medium" {
+		t.Errorf("unexpected Method/Priority/Risk: %q/%q/%q", neverRun.Method, neverRun.Priority, neverRun.Risk)
+	}
+
+	executed := testCases[1]
+	if executed.LastRun == nil || *executed.LastRun != "2016-04-12T08:30:00.000Z" {
+		t.Errorf("unexpected LastRun: %v", executed.LastRun)
+	}
+	if executed.LastVerdict != "Pass" || executed.LastBuild != "build-1432" {
+		t.Errorf("unexpected LastVerdict/LastBuild: %q/%q", executed.LastVerdict, executed.LastBuild)
+	}
+	if executed.Method != "Automated" {
+		t.Errorf("expected Method=Automated, got %q", executed.Method)
+	}
+	if executed.TestCaseType != "Acceptance" || executed.GetType() != "TestCase" {
+		t.Errorf("expected TestCaseType=Acceptance and _type TestCase, got %q/%q", executed.TestCaseType, executed.GetType())
+	}
+}
@@ -0,0 +1,56 @@
+/**
+* Copyright 2014 Comcast Cable Communications Management, LLC
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+* http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package models
+
+import "encoding/json"
+
+// OperationResult is the envelope Rally wraps the object of an update in.
+type OperationResult[T any] struct {
+	RallyAPIMajor string `json:"_rallyAPIMajor,omitempty"`
+	RallyAPIMinor string `json:"_rallyAPIMinor,omitempty"`
+	Object        T
+	Errors        []string
+	Warnings      []string
+}
+
+// OperationResponse is the top-level update response. Rally names its key
+// either "OperationalResult" or "OperationResult" depending on the endpoint
+// and version; both are read into OperationalResult.
+type OperationResponse[T any] struct {
+	OperationalResult OperationResult[T]
+}
+
+// UnmarshalJSON accepts both the OperationalResult and OperationResult keys.
+func (r *OperationResponse[T]) UnmarshalJSON(data []byte) error {
+	var envelope struct {
+		OperationalResult *OperationResult[T]
+		OperationResult   *OperationResult[T]
+	}
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		return err
+	}
+
+	switch {
+	case envelope.OperationalResult != nil:
+		r.OperationalResult = *envelope.OperationalResult
+	case envelope.OperationResult != nil:
+		r.OperationalResult = *envelope.OperationResult
+	default:
+		r.OperationalResult = OperationResult[T]{}
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+/**
+* Copyright 2014 Comcast Cable Communications Management, LLC
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+* http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package models_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/aleksofficial/go-rally-rest-toolkit/models"
+)
+
+func TestOperationResponse_BothEnvelopeKeys(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload string
+	}{
+		{"OperationalResult", `{"OperationalResult": {"Object": {"ObjectID": 42, "Name": "Updated"}, "Warnings": ["deprecated"]}}`},
+		{"OperationResult", `{"OperationResult": {"Object": {"ObjectID": 42, "Name": "Updated"}, "Warnings": ["deprecated"]}}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var response models.OperationResponse[models.Changeset]
+			if err := json.Unmarshal([]byte(tt.payload), &response); err != nil {
+				t.Fatalf("Unmarshal failed unexpectedly: %v", err)
+			}
+			result := response.OperationalResult
+			if result.Object.ObjectID != 42 || result.Object.Name != "Updated" {
+				t.Errorf("unexpected object: %+v", result.Object)
+			}
+			if len(result.Warnings) != 1 || result.Warnings[0] != "deprecated" {
+				t.Errorf("unexpected warnings: %v", result.Warnings)
+			}
+		})
+	}
+}
+
+func TestOperationResponse_NoEnvelope(t *testing.T) {
+	var response models.OperationResponse[models.Changeset]
+	if err := json.Unmarshal([]byte(`{}`), &response); err != nil {
+		t.Fatalf("Unmarshal failed unexpectedly: %v", err)
+	}
+	if response.OperationalResult.Object.ObjectID != 0 {
+		t.Errorf("expected an empty object, got %+v", response.OperationalResult.Object)
+	}
+}
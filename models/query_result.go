@@ -0,0 +1,36 @@
+/**
+* Copyright 2014 Comcast Cable Communications Management, LLC
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+* http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package models
+
+// QueryResult is the envelope Rally wraps query results in. Errors and Warnings
+// can be populated even on a successful query, e.g. for deprecated fields.
+type QueryResult[T any] struct {
+	RallyAPIMajor    string `json:"_rallyAPIMajor,omitempty"`
+	RallyAPIMinor    string `json:"_rallyAPIMinor,omitempty"`
+	Results          []T
+	TotalResultCount int
+	// StartIndex is the 1-based index of the first result in Results
+	StartIndex int
+	PageSize   int
+	Errors     []string
+	Warnings   []string
+}
+
+// QueryResponse is the top-level {"QueryResult": {...}} query response.
+type QueryResponse[T any] struct {
+	QueryResult QueryResult[T]
+}
@@ -0,0 +1,52 @@
+/**
+* Copyright 2014 Comcast Cable Communications Management, LLC
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+* http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package models
+
+import "sort"
+
+// Ranked is implemented by models that carry a DragAndDropRank.
+type Ranked interface {
+	GetDragAndDropRank() string
+}
+
+// SortByRank sorts items into Rally backlog order. DragAndDropRank values are
+// opaque strings designed to sort lexically.
+func SortByRank[T Ranked](items []T) {
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].GetDragAndDropRank() < items[j].GetDragAndDropRank()
+	})
+}
+
+// GetDragAndDropRank returns the defect's backlog rank.
+func (d Defect) GetDragAndDropRank() string {
+	return d.DragAndDropRank
+}
+
+// GetDragAndDropRank returns the story's backlog rank.
+func (hr HierarchicalRequirement) GetDragAndDropRank() string {
+	return hr.DragAndDropRank
+}
+
+// GetDragAndDropRank returns the task's rank.
+func (t Task) GetDragAndDropRank() string {
+	return t.DragAndDropRank
+}
+
+// GetDragAndDropRank returns the portfolio item's rank.
+func (pi PortfolioItem) GetDragAndDropRank() string {
+	return pi.DragAndDropRank
+}
@@ -0,0 +1,64 @@
+/**
+* Copyright 2014 Comcast Cable Communications Management, LLC
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+* http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package models
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+)
+
+// TimeFormat is the ISO 8601 layout Rally expects for dates on writes.
+const TimeFormat = "2006-01-02T15:04:05.000Z"
+
+// Time is a date field such as an iteration's StartDate. It is written in UTC
+// in TimeFormat and read from any RFC 3339 form, including Rally's Z-suffixed
+// one. A JSON null reads as the zero Time.
+type Time struct {
+	time.Time
+}
+
+// NewTime returns a Time for t, for use in a model's *Time field.
+func NewTime(t time.Time) *Time {
+	return &Time{Time: t}
+}
+
+// MarshalJSON writes the time in UTC in TimeFormat, or null for the zero Time.
+func (t Time) MarshalJSON() ([]byte, error) {
+	if t.IsZero() {
+		return []byte("null"), nil
+	}
+	return []byte(`"` + t.UTC().Format(TimeFormat) + `"`), nil
+}
+
+// UnmarshalJSON accepts an RFC 3339 timestamp with or without fractional
+// seconds, or null.
+func (t *Time) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(bytes.TrimSpace(data), []byte("null")) {
+		*t = Time{}
+		return nil
+	}
+	if len(data) < 2 || data[0] != '"' || data[len(data)-1] != '"' {
+		return fmt.Errorf("invalid time %s: expected a string", data)
+	}
+	parsed, err := time.Parse(time.RFC3339Nano, string(data[1:len(data)-1]))
+	if err != nil {
+		return fmt.Errorf("invalid time %s: %w", data, err)
+	}
+	*t = Time{Time: parsed}
+	return nil
+}
@@ -0,0 +1,68 @@
+/**
+* Copyright 2014 Comcast Cable Communications Management, LLC
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+* http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package models_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/aleksofficial/go-rally-rest-toolkit/models"
+)
+
+func TestTime_MarshalUTC(t *testing.T) {
+	local := time.FixedZone("EST", -5*60*60)
+	b, err := json.Marshal(models.NewTime(time.Date(2016, 3, 6, 19, 0, 0, 0, local)))
+	if err != nil {
+		t.Fatalf("Marshal failed unexpectedly: %v", err)
+	}
+	if string(b) != `"2016-03-07T00:00:00.000Z"` {
+		t.Errorf("unexpected JSON: %s", b)
+	}
+}
+
+func TestTime_Unmarshal(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected time.Time
+	}{
+		{"milliseconds with Z", `"2016-03-07T00:00:00.000Z"`, time.Date(2016, 3, 7, 0, 0, 0, 0, time.UTC)},
+		{"seconds with Z", `"2016-03-07T00:00:00Z"`, time.Date(2016, 3, 7, 0, 0, 0, 0, time.UTC)},
+		{"offset", `"2016-03-06T19:00:00-05:00"`, time.Date(2016, 3, 7, 0, 0, 0, 0, time.UTC)},
+		{"null", `null`, time.Time{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got models.Time
+			if err := json.Unmarshal([]byte(tt.input), &got); err != nil {
+				t.Fatalf("Unmarshal failed unexpectedly: %v", err)
+			}
+			if !got.Equal(tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, got.Time)
+			}
+		})
+	}
+}
+
+func TestTime_UnmarshalInvalid(t *testing.T) {
+	var got models.Time
+	if err := json.Unmarshal([]byte(`"03/07/2016"`), &got); err == nil {
+		t.Error("expected an error for a non-ISO date")
+	}
+}
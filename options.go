@@ -0,0 +1,347 @@
+/**
+* Copyright 2014 Comcast Cable Communications Management, LLC
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+* http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package rallyresttoolkit
+
+import (
+	"errors"
+	"io"
+	"maps"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Option configures a RallyClient built by NewClient.
+type Option func(*RallyClient) error
+
+// Logger is the minimal logging interface used by RallyClient. *log.Logger
+// satisfies it.
+type Logger interface {
+	Printf(format string, v ...interface{})
+}
+
+// NewClient creates a new RallyClient from functional options, applied in
+// order. Unset values default to DefaultBaseURL, the default retry settings and
+// an http.Client built from the config's Timeout and transport settings. It returns ErrInvalidBaseURL if the base URL is not an
+// absolute URL with a scheme and host.
+func NewClient(opts ...Option) (*RallyClient, error) {
+	s, err := newClient(opts...)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateBaseURL(s.apiurl); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func newClient(opts ...Option) (*RallyClient, error) {
+	s := &RallyClient{
+		apiurl: DefaultBaseURL,
+		clock:  realClock{},
+		closed: make(chan struct{}),
+	}
+	for _, opt := range opts {
+		if err := opt(s); err != nil {
+			return nil, err
+		}
+	}
+	if s.client == nil {
+		if s.config != nil {
+			client, err := newHTTPClient(s.config)
+			if err != nil {
+				return nil, err
+			}
+			s.client = client
+		} else {
+			s.client = &http.Client{
+				Timeout: DefaultTimeout * time.Second,
+			}
+		}
+	}
+	s.applyConfigRateLimit()
+	if bucket, ok := s.limiter.(*tokenBucket); ok {
+		bucket.clock = s.clock
+	}
+	return s, nil
+}
+
+// WithAPIKey sets the Rally API key sent in the ZSESSIONID header.
+func WithAPIKey(apikey string) Option {
+	return func(s *RallyClient) error {
+		s.apikey = apikey
+		return nil
+	}
+}
+
+// WithBaseURL sets the Rally WSAPI base URL. Trailing slashes are trimmed so
+// request paths don't contain "//".
+func WithBaseURL(apiurl string) Option {
+	return func(s *RallyClient) error {
+		s.apiurl = strings.TrimRight(apiurl, "/")
+		return nil
+	}
+}
+
+// WithHTTPClient sets the client used to execute requests. A nil client keeps
+// the default http.Client, built with the configured Timeout.
+func WithHTTPClient(client ClientDoer) Option {
+	return func(s *RallyClient) error {
+		if httpClient, ok := client.(*http.Client); ok && httpClient == nil {
+			client = nil
+		}
+		s.client = client
+		return nil
+	}
+}
+
+// WithRetries sets the maximum number of retries and the initial retry delay.
+func WithRetries(maxRetries int, retryDelay time.Duration) Option {
+	return func(s *RallyClient) error {
+		if maxRetries < 0 {
+			return errors.New("max retries must not be negative")
+		}
+		if retryDelay < 0 {
+			return errors.New("retry delay must not be negative")
+		}
+		config := s.ensureConfig()
+		config.MaxRetries = maxRetries
+		config.RetryDelay = int(retryDelay / time.Millisecond)
+		return nil
+	}
+}
+
+// WithBackoff sets the strategy that computes the delay before each retry.
+func WithBackoff(strategy BackoffStrategy) Option {
+	return func(s *RallyClient) error {
+		if strategy == nil {
+			return errors.New("backoff strategy must not be nil")
+		}
+		s.ensureConfig().BackoffStrategy = strategy
+		return nil
+	}
+}
+
+// WithJitter sets how randomness is applied to retry delays.
+func WithJitter(mode JitterMode) Option {
+	return func(s *RallyClient) error {
+		if mode < JitterAdditive || mode > JitterFull {
+			return errors.New("unknown jitter mode")
+		}
+		s.ensureConfig().JitterMode = mode
+		return nil
+	}
+}
+
+// WithRateLimit limits the client to requestsPerSecond requests, allowing
+// bursts of up to burst requests. Every attempt, including retries, waits for
+// the limiter.
+func WithRateLimit(requestsPerSecond float64, burst int) Option {
+	return func(s *RallyClient) error {
+		if requestsPerSecond <= 0 {
+			return errors.New("requests per second must be positive")
+		}
+		if burst < 1 {
+			return errors.New("burst must be at least 1")
+		}
+		s.limiter = newTokenBucket(requestsPerSecond, burst)
+		return nil
+	}
+}
+
+// WithRateLimiter gates every attempt, including retries, on limiter, such as
+// a *rate.Limiter shared with other clients. It takes precedence over
+// Config.RequestsPerSecond.
+func WithRateLimiter(limiter RateLimiter) Option {
+	return func(s *RallyClient) error {
+		if limiter == nil {
+			return errors.New("rate limiter must not be nil")
+		}
+		s.limiter = limiter
+		return nil
+	}
+}
+
+// WithMaxRetryAfter caps how long a retry waits when the server asks for a
+// delay with a Retry-After header; see Config.MaxRetryAfter.
+func WithMaxRetryAfter(max time.Duration) Option {
+	return func(s *RallyClient) error {
+		if max <= 0 {
+			return errors.New("max retry after must be positive")
+		}
+		s.ensureConfig().MaxRetryAfter = int(max / time.Millisecond)
+		return nil
+	}
+}
+
+// WithRetryBudget caps retries across all requests of the client at max,
+// refilling at refillPerSecond retries per second; see Config.RetryBudget.
+func WithRetryBudget(max int, refillPerSecond float64) Option {
+	return func(s *RallyClient) error {
+		if max < 0 {
+			return errors.New("retry budget must not be negative")
+		}
+		if refillPerSecond <= 0 {
+			return errors.New("retry budget refill rate must be positive")
+		}
+		s.ensureConfig().RetryBudget = NewRetryBudget(max, refillPerSecond)
+		return nil
+	}
+}
+
+// WithRetryableErrorMessages retries requests that fail with a Rally error
+// containing one of messages; see Config.RetryableErrorMessages.
+func WithRetryableErrorMessages(messages ...string) Option {
+	return func(s *RallyClient) error {
+		for _, message := range messages {
+			if message == "" {
+				return errors.New("retryable error messages must not be empty")
+			}
+		}
+		config := s.ensureConfig()
+		config.RetryableErrorMessages = append(config.RetryableErrorMessages, messages...)
+		return nil
+	}
+}
+
+// WithLogger sets a logger that reports retried requests.
+func WithLogger(logger Logger) Option {
+	return func(s *RallyClient) error {
+		s.logger = logger
+		return nil
+	}
+}
+
+// WithDecoder sets the function that decodes successful response bodies; see
+// Config.Decoder.
+func WithDecoder(decoder func(io.Reader, interface{}) error) Option {
+	return func(s *RallyClient) error {
+		if decoder == nil {
+			return errors.New("decoder must not be nil")
+		}
+		s.ensureConfig().Decoder = decoder
+		return nil
+	}
+}
+
+// WithFetchAfterCreate makes creates fetch the new object once it exists, so
+// computed fields that Rally leaves out of the create response, such as
+// ranks and defaulted values, are filled in. It costs one extra GET per
+// create; see Config.FetchAfterCreate.
+func WithFetchAfterCreate() Option {
+	return func(s *RallyClient) error {
+		s.ensureConfig().FetchAfterCreate = true
+		return nil
+	}
+}
+
+// WithDefaultProject scopes queries to a project, by ref, unless their
+// QueryOptions name another.
+func WithDefaultProject(projectRef string) Option {
+	return func(s *RallyClient) error {
+		s.ensureConfig().DefaultProject = projectRef
+		return nil
+	}
+}
+
+// WithConfig uses a copy of config for the client's settings, including its
+// APIKey and BaseURL when set. It replaces settings made by earlier options,
+// so put it first and refine it with later ones.
+func WithConfig(config *Config) Option {
+	return func(s *RallyClient) error {
+		if config == nil {
+			return errors.New("config must not be nil")
+		}
+		copied := *config
+		copied.Headers = maps.Clone(config.Headers)
+		copied.RetryableErrorMessages = append([]string(nil), config.RetryableErrorMessages...)
+		s.config = &copied
+		if config.APIKey != "" {
+			s.apikey = config.APIKey
+		}
+		if config.BaseURL != "" {
+			return WithBaseURL(config.BaseURL)(s)
+		}
+		return nil
+	}
+}
+
+// WithDefaultWorkspace scopes queries to a workspace, by ref, unless their
+// QueryOptions name another.
+func WithDefaultWorkspace(workspaceRef string) Option {
+	return func(s *RallyClient) error {
+		s.ensureConfig().DefaultWorkspace = workspaceRef
+		return nil
+	}
+}
+
+// WithHeaders adds headers to every request, e.g. for an API gateway in front
+// of Rally. Repeated calls add to the earlier headers. The API key header
+// cannot be set this way; use WithAPIKey.
+func WithHeaders(headers map[string]string) Option {
+	return func(s *RallyClient) error {
+		config := s.ensureConfig()
+		for name, value := range headers {
+			if http.CanonicalHeaderKey(name) == "Zsessionid" {
+				return errors.New("the ZSESSIONID header is set with WithAPIKey")
+			}
+			if config.Headers == nil {
+				config.Headers = make(map[string]string, len(headers))
+			}
+			config.Headers[name] = value
+		}
+		return nil
+	}
+}
+
+// WithDefaultPageSize sets the page size of queries whose QueryOptions set
+// none, from 1 to MaxPageSize.
+func WithDefaultPageSize(pageSize int) Option {
+	return func(s *RallyClient) error {
+		if err := checkDefaultPageSize(pageSize); err != nil {
+			return err
+		}
+		s.ensureConfig().DefaultPageSize = pageSize
+		return nil
+	}
+}
+
+// WithClock sets the time source used for retry waits and rate limiting.
+// It exists for tests; a nil clock keeps the real one.
+func WithClock(clock Clock) Option {
+	return func(s *RallyClient) error {
+		if clock != nil {
+			s.clock = clock
+		}
+		return nil
+	}
+}
+
+// ensureConfig returns the client config, creating one with default values if
+// none has been set.
+func (s *RallyClient) ensureConfig() *Config {
+	if s.config == nil {
+		s.config = &Config{
+			Timeout:       DefaultTimeout,
+			MaxRetries:    DefaultMaxRetries,
+			RetryDelay:    DefaultRetryDelay,
+			MaxRetryDelay: DefaultMaxRetryDelay,
+			MaxRetryAfter: DefaultMaxRetryAfter,
+		}
+	}
+	return s.config
+}
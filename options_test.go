@@ -0,0 +1,812 @@
+/**
+* Copyright 2014 Comcast Cable Communications Management, LLC
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+* http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package rallyresttoolkit_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/aleksofficial/go-rally-rest-toolkit"
+	"github.com/aleksofficial/go-rally-rest-toolkit/fakes"
+)
+
+type spyLogger struct {
+	lines []string
+}
+
+func (l *spyLogger) Printf(format string, v ...interface{}) {
+	l.lines = append(l.lines, fmt.Sprintf(format, v...))
+}
+
+func okResponse() *http.Response {
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Body:       &fakes.FakeResponseBody{Reader: bytes.NewBufferString(`{"QueryResult": { "TotalResultCount": 1, "Results": [{"FakeValue": "fakeresponse"}]}}`)},
+	}
+}
+
+func errorResponse(statusCode int) *http.Response {
+	return &http.Response{
+		StatusCode: statusCode,
+		Body:       &fakes.FakeResponseBody{Reader: bytes.NewBufferString(`{"OperationResult": {"Errors": ["Server error"]}}`)},
+	}
+}
+
+func TestNewClient_Defaults(t *testing.T) {
+	rallyClient, err := NewClient(WithAPIKey("abcdef"))
+	if err != nil {
+		t.Fatalf("NewClient failed unexpectedly: %v", err)
+	}
+
+	httpClient, ok := rallyClient.HTTPClient().(*http.Client)
+	if !ok {
+		t.Fatalf("expected default *http.Client, got %T", rallyClient.HTTPClient())
+	}
+	if httpClient.Timeout != DefaultTimeout*time.Second {
+		t.Errorf("expected Timeout=%v, got %v", DefaultTimeout*time.Second, httpClient.Timeout)
+	}
+}
+
+func TestNewClient_WithOptions(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{FakeResponse: okResponse()}
+
+	rallyClient, err := NewClient(
+		WithAPIKey("abcdef"),
+		WithBaseURL("http://myRallyUrl"),
+		WithHTTPClient(fakeClient),
+	)
+	if err != nil {
+		t.Fatalf("NewClient failed unexpectedly: %v", err)
+	}
+
+	fakeOutput := new(fakes.FakeOutput)
+	if err := rallyClient.GetRequest(context.Background(), "12345", "defect", &fakeOutput); err != nil {
+		t.Fatalf("GetRequest failed unexpectedly: %v", err)
+	}
+	if got := fakeClient.SpyRequest.URL.String(); !strings.HasPrefix(got, "http://myRallyUrl/defect/12345") {
+		t.Errorf("expected request to http://myRallyUrl/defect/12345, got %s", got)
+	}
+	if got := fakeClient.SpyRequest.Header.Get("ZSESSIONID"); got != "abcdef" {
+		t.Errorf("expected ZSESSIONID=abcdef, got %q", got)
+	}
+}
+
+func TestNewClient_WithRetriesAndLogger(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{
+		FakeResponses: []*http.Response{
+			errorResponse(http.StatusServiceUnavailable),
+			errorResponse(http.StatusServiceUnavailable),
+			okResponse(),
+		},
+	}
+	logger := &spyLogger{}
+
+	rallyClient, err := NewClient(
+		WithHTTPClient(fakeClient),
+		WithRetries(2, time.Millisecond),
+		WithLogger(logger),
+	)
+	if err != nil {
+		t.Fatalf("NewClient failed unexpectedly: %v", err)
+	}
+
+	fakeOutput := new(fakes.FakeOutput)
+	if err := rallyClient.QueryRequest(context.Background(), map[string]string{}, "defect", &fakeOutput); err != nil {
+		t.Fatalf("QueryRequest should have succeeded after retries: %v", err)
+	}
+	if fakeClient.CallCount != 3 {
+		t.Errorf("expected 3 calls, got %d", fakeClient.CallCount)
+	}
+	if len(logger.lines) != 2 {
+		t.Fatalf("expected 2 retry log lines, got %d: %v", len(logger.lines), logger.lines)
+	}
+	if !strings.Contains(logger.lines[0], "retry 1 of 2") {
+		t.Errorf("unexpected log line: %s", logger.lines[0])
+	}
+}
+
+func TestNewClient_WithRateLimit(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{}
+	for i := 0; i < 4; i++ {
+		fakeClient.FakeResponses = append(fakeClient.FakeResponses, okResponse())
+	}
+
+	rallyClient, err := NewClient(
+		WithHTTPClient(fakeClient),
+		WithRateLimit(20, 2),
+	)
+	if err != nil {
+		t.Fatalf("NewClient failed unexpectedly: %v", err)
+	}
+
+	// The burst of 2 is immediate; the remaining 2 requests wait 50ms each.
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		fakeOutput := new(fakes.FakeOutput)
+		if err := rallyClient.GetRequest(context.Background(), "12345", "defect", &fakeOutput); err != nil {
+			t.Fatalf("GetRequest failed unexpectedly: %v", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 90*time.Millisecond {
+		t.Errorf("expected rate limiter to space requests over at least 90ms, took %v", elapsed)
+	}
+}
+
+func TestNewClient_WithRateLimitRespectsContext(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{FakeResponse: okResponse()}
+
+	rallyClient, err := NewClient(
+		WithHTTPClient(fakeClient),
+		WithRateLimit(0.001, 1),
+	)
+	if err != nil {
+		t.Fatalf("NewClient failed unexpectedly: %v", err)
+	}
+
+	fakeOutput := new(fakes.FakeOutput)
+	if err := rallyClient.GetRequest(context.Background(), "12345", "defect", &fakeOutput); err != nil {
+		t.Fatalf("GetRequest failed unexpectedly: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := rallyClient.GetRequest(ctx, "12345", "defect", &fakeOutput); err == nil {
+		t.Fatal("expected rate-limited request to fail when the context expires")
+	}
+	if fakeClient.CallCount != 1 {
+		t.Errorf("expected 1 call, got %d", fakeClient.CallCount)
+	}
+}
+
+func TestNewClient_WithClockRetryDelays(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{}
+	for i := 0; i < 4; i++ {
+		fakeClient.FakeResponses = append(fakeClient.FakeResponses, &http.Response{
+			StatusCode: http.StatusServiceUnavailable,
+			Body:       &fakes.FakeResponseBody{Reader: bytes.NewBufferString(`{}`)},
+		})
+	}
+	clock := &fakes.FakeClock{Current: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+
+	rallyClient, err := NewClient(
+		WithHTTPClient(fakeClient),
+		WithRetries(3, time.Second),
+		WithJitter(JitterNone),
+		WithClock(clock),
+	)
+	if err != nil {
+		t.Fatalf("NewClient failed unexpectedly: %v", err)
+	}
+
+	start := time.Now()
+	fakeOutput := new(fakes.FakeOutput)
+	if err := rallyClient.GetRequest(context.Background(), "12345", "defect", &fakeOutput); err == nil {
+		t.Fatal("expected GetRequest to fail after exhausting retries")
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("expected retries not to sleep in real time, took %v", elapsed)
+	}
+
+	expected := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second}
+	if !reflect.DeepEqual(clock.Sleeps, expected) {
+		t.Errorf("expected sleeps %v, got %v", expected, clock.Sleeps)
+	}
+	if fakeClient.CallCount != 4 {
+		t.Errorf("expected 4 calls, got %d", fakeClient.CallCount)
+	}
+}
+
+func TestNewClient_WithClockRateLimit(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{}
+	for i := 0; i < 4; i++ {
+		fakeClient.FakeResponses = append(fakeClient.FakeResponses, okResponse())
+	}
+	clock := &fakes.FakeClock{Current: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+
+	// The clock option is applied after the limiter is created.
+	rallyClient, err := NewClient(
+		WithHTTPClient(fakeClient),
+		WithRateLimit(20, 2),
+		WithClock(clock),
+	)
+	if err != nil {
+		t.Fatalf("NewClient failed unexpectedly: %v", err)
+	}
+
+	for i := 0; i < 4; i++ {
+		fakeOutput := new(fakes.FakeOutput)
+		if err := rallyClient.GetRequest(context.Background(), "12345", "defect", &fakeOutput); err != nil {
+			t.Fatalf("GetRequest failed unexpectedly: %v", err)
+		}
+	}
+
+	// The burst of 2 is immediate; the remaining 2 requests wait 50ms each.
+	expected := []time.Duration{50 * time.Millisecond, 50 * time.Millisecond}
+	if !reflect.DeepEqual(clock.Sleeps, expected) {
+		t.Errorf("expected sleeps %v, got %v", expected, clock.Sleeps)
+	}
+}
+
+func TestNewClient_WithDecoder(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{
+		FakeResponse: &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       &fakes.FakeResponseBody{Reader: bytes.NewBufferString(`{"QueryResult": {"TotalResultCount": 1, "Results": [{"FakeValue": "x"}], "Surprise": true}}`)},
+		},
+	}
+
+	calls := 0
+	strict := func(r io.Reader, v interface{}) error {
+		calls++
+		dec := json.NewDecoder(r)
+		dec.DisallowUnknownFields()
+		return dec.Decode(v)
+	}
+	rallyClient, err := NewClient(WithHTTPClient(fakeClient), WithDecoder(strict))
+	if err != nil {
+		t.Fatalf("NewClient failed unexpectedly: %v", err)
+	}
+
+	fakeOutput := new(fakes.FakeOutput)
+	err = rallyClient.QueryRequest(context.Background(), map[string]string{}, "defect", fakeOutput)
+	if err == nil || !strings.Contains(err.Error(), "Surprise") {
+		t.Errorf("expected the custom decoder to reject the unknown field, got %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("expected the decoder to be called once, got %d", calls)
+	}
+}
+
+func TestNewClient_DefaultDecoderIgnoresUnknownFields(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{
+		FakeResponse: &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       &fakes.FakeResponseBody{Reader: bytes.NewBufferString(`{"QueryResult": {"TotalResultCount": 1, "Results": [{"FakeValue": "x"}], "Surprise": true}}`)},
+		},
+	}
+	rallyClient, err := NewClient(WithHTTPClient(fakeClient))
+	if err != nil {
+		t.Fatalf("NewClient failed unexpectedly: %v", err)
+	}
+
+	fakeOutput := new(fakes.FakeOutput)
+	if err := rallyClient.QueryRequest(context.Background(), map[string]string{}, "defect", fakeOutput); err != nil {
+		t.Fatalf("QueryRequest failed unexpectedly: %v", err)
+	}
+	if fakeOutput.QueryResult.Results[0].FakeValue != "x" {
+		t.Errorf("unexpected output: %+v", fakeOutput)
+	}
+}
+
+func TestNewClient_InvalidOptions(t *testing.T) {
+	tests := map[string]Option{
+		"negative retries": WithRetries(-1, time.Second),
+		"negative delay":   WithRetries(1, -time.Second),
+		"zero rate":        WithRateLimit(0, 1),
+		"zero burst":       WithRateLimit(10, 0),
+		"nil decoder":      WithDecoder(nil),
+	}
+
+	for name, opt := range tests {
+		if _, err := NewClient(opt); err == nil {
+			t.Errorf("%s: expected NewClient to fail", name)
+		}
+	}
+}
+
+func TestNewClient_InvalidBaseURL(t *testing.T) {
+	for _, apiurl := range []string{
+		"",
+		"rally1.rallydev.com/slm/webservice/v2.0",
+		"https://",
+		"http://bad host",
+		"/slm/webservice/v2.0",
+		"https://rally1.rallydev.com/slm//webservice/v2.0",
+		"https://rally1.rallydev.com/slm/webservice/v2.0?workspace=1",
+	} {
+		_, err := NewClient(WithBaseURL(apiurl))
+		if !errors.Is(err, ErrInvalidBaseURL) {
+			t.Errorf("%q: expected ErrInvalidBaseURL, got %v", apiurl, err)
+		}
+	}
+
+	if _, err := NewClient(WithBaseURL("http://localhost:8080/slm/webservice/v2.0")); err != nil {
+		t.Errorf("expected a valid base URL to be accepted, got %v", err)
+	}
+}
+
+func TestBaseURL_TrailingSlashes(t *testing.T) {
+	for _, apiurl := range []string{"http://myRallyUrl/slm/webservice/v2.0", "http://myRallyUrl/slm/webservice/v2.0/", "http://myRallyUrl/slm/webservice/v2.0//"} {
+		fakeClient := &fakes.FakeHTTPClient{FakeResponse: okResponse()}
+		rallyClient, err := NewClient(WithBaseURL(apiurl), WithHTTPClient(fakeClient))
+		if err != nil {
+			t.Fatalf("%q: NewClient failed unexpectedly: %v", apiurl, err)
+		}
+
+		fakeOutput := new(fakes.FakeOutput)
+		if err := rallyClient.QueryRequest(context.Background(), map[string]string{}, "defect", &fakeOutput); err != nil {
+			t.Fatalf("%q: QueryRequest failed unexpectedly: %v", apiurl, err)
+		}
+		if got := fakeClient.SpyRequest.URL.Path; got != "/slm/webservice/v2.0/defect" {
+			t.Errorf("%q: unexpected query path %s", apiurl, got)
+		}
+
+		fakeClient.FakeResponse = okResponse()
+		if err := rallyClient.GetRequest(context.Background(), "12345", "defect", &fakeOutput); err != nil {
+			t.Fatalf("%q: GetRequest failed unexpectedly: %v", apiurl, err)
+		}
+		if got := fakeClient.SpyRequest.URL.Path; got != "/slm/webservice/v2.0/defect/12345" {
+			t.Errorf("%q: unexpected get path %s", apiurl, got)
+		}
+	}
+
+	fakeClient := &fakes.FakeHTTPClient{FakeResponse: okResponse()}
+	fakeOutput := new(fakes.FakeOutput)
+	if err := New("abcdef", "http://myRallyUrl/", fakeClient).GetRequest(context.Background(), "12345", "defect", &fakeOutput); err != nil {
+		t.Fatalf("GetRequest failed unexpectedly: %v", err)
+	}
+	if got := fakeClient.SpyRequest.URL.Path; got != "/defect/12345" {
+		t.Errorf("expected New to trim the trailing slash, got path %s", got)
+	}
+}
+
+func TestNewClient_WithDefaultPageSizeBounds(t *testing.T) {
+	for _, size := range []int{0, -1, MaxPageSize + 1} {
+		if _, err := NewClient(WithDefaultPageSize(size)); !errors.Is(err, ErrInvalidPageSize) {
+			t.Errorf("%d: expected ErrInvalidPageSize, got %v", size, err)
+		}
+	}
+	for _, size := range []int{1, MaxPageSize} {
+		if _, err := NewClient(WithDefaultPageSize(size)); err != nil {
+			t.Errorf("%d: expected NewClient to succeed, got %v", size, err)
+		}
+	}
+}
+
+func TestDefaultPageSize_FallbackChain(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     []Option
+		pageSize int
+		expected string
+	}{
+		{"package default", nil, 0, ""},
+		{"config", []Option{WithDefaultPageSize(500)}, 0, "500"},
+		{"per call", []Option{WithDefaultPageSize(500)}, 50, "50"},
+	}
+
+	for _, tt := range tests {
+		fakeClient := &fakes.FakeHTTPClient{FakeResponse: okResponse()}
+		rallyClient, err := NewClient(append(tt.opts, WithHTTPClient(fakeClient))...)
+		if err != nil {
+			t.Fatalf("%s: NewClient failed unexpectedly: %v", tt.name, err)
+		}
+
+		fakeOutput := new(fakes.FakeOutput)
+		if err := rallyClient.QueryRequestWithOptions(context.Background(), nil, "defect", QueryOptions{PageSize: tt.pageSize}, &fakeOutput); err != nil {
+			t.Fatalf("%s: QueryRequestWithOptions failed unexpectedly: %v", tt.name, err)
+		}
+		if got := fakeClient.SpyRequest.URL.Query().Get("pagesize"); got != tt.expected {
+			t.Errorf("%s: expected pagesize %q, got %q", tt.name, tt.expected, got)
+		}
+	}
+}
+
+func TestDefaultPageSize_InvalidConfig(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{FakeResponse: okResponse()}
+	rallyClient := New("abcdef", "http://myRallyUrl", fakeClient)
+	rallyClient.SetConfig(&Config{DefaultPageSize: MaxPageSize + 1})
+
+	fakeOutput := new(fakes.FakeOutput)
+	err := rallyClient.QueryRequest(context.Background(), nil, "defect", &fakeOutput)
+	if !errors.Is(err, ErrInvalidPageSize) {
+		t.Errorf("expected ErrInvalidPageSize, got %v", err)
+	}
+}
+
+func TestNewClient_WithRetryBudget(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{}
+	for i := 0; i < 10; i++ {
+		fakeClient.FakeResponses = append(fakeClient.FakeResponses, errorResponse(http.StatusServiceUnavailable))
+	}
+	clock := &fakes.FakeClock{Current: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	logger := &spyLogger{}
+
+	// Two retries to spend, refilling at one per 1000 seconds.
+	rallyClient, err := NewClient(
+		WithHTTPClient(fakeClient),
+		WithRetries(3, time.Second),
+		WithJitter(JitterNone),
+		WithRetryBudget(2, 0.001),
+		WithClock(clock),
+		WithLogger(logger),
+	)
+	if err != nil {
+		t.Fatalf("NewClient failed unexpectedly: %v", err)
+	}
+
+	get := func() int {
+		before := fakeClient.CallCount
+		fakeOutput := new(fakes.FakeOutput)
+		if err := rallyClient.GetRequest(context.Background(), "12345", "defect", &fakeOutput); err == nil {
+			t.Fatal("expected GetRequest to fail")
+		}
+		return fakeClient.CallCount - before
+	}
+
+	if calls := get(); calls != 3 {
+		t.Errorf("expected the budget to allow 2 of 3 retries, got %d calls", calls)
+	}
+	if calls := get(); calls != 1 {
+		t.Errorf("expected an exhausted budget to fail fast, got %d calls", calls)
+	}
+	if !strings.Contains(strings.Join(logger.lines, "\n"), "retry budget exhausted") {
+		t.Errorf("expected the exhausted budget to be logged, got %v", logger.lines)
+	}
+
+	clock.Current = clock.Current.Add(1000 * time.Second)
+	if calls := get(); calls != 2 {
+		t.Errorf("expected one refilled retry, got %d calls", calls)
+	}
+}
+
+func TestNewClient_WithRetryBudgetInvalid(t *testing.T) {
+	if _, err := NewClient(WithRetryBudget(-1, 1)); err == nil {
+		t.Error("expected an error for a negative budget")
+	}
+	if _, err := NewClient(WithRetryBudget(10, 0)); err == nil {
+		t.Error("expected an error for a zero refill rate")
+	}
+}
+
+func TestNewClient_WithConfig(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{FakeResponse: okResponse()}
+	config := &Config{APIKey: "abcdef", BaseURL: "http://myRallyUrl/", DefaultProject: "/project/1"}
+
+	rallyClient, err := NewClient(WithConfig(config), WithHTTPClient(fakeClient))
+	if err != nil {
+		t.Fatalf("NewClient failed unexpectedly: %v", err)
+	}
+	config.DefaultProject = "/project/2"
+
+	fakeOutput := new(fakes.FakeOutput)
+	if err := rallyClient.QueryRequest(context.Background(), nil, "defect", &fakeOutput); err != nil {
+		t.Fatalf("QueryRequest failed unexpectedly: %v", err)
+	}
+	if got := fakeClient.SpyRequest.URL.Path; got != "/defect" {
+		t.Errorf("expected path /defect, got %s", got)
+	}
+	if got := fakeClient.SpyRequest.Header.Get("ZSESSIONID"); got != "abcdef" {
+		t.Errorf("expected ZSESSIONID=abcdef, got %q", got)
+	}
+	if got := fakeClient.SpyRequest.URL.Query().Get("project"); got != "/project/1" {
+		t.Errorf("expected the client to keep its own copy of the config, got project %q", got)
+	}
+}
+
+func TestNewClient_WithConfigLeavesConfigUntouched(t *testing.T) {
+	config := &Config{
+		Headers:                map[string]string{"X-Trace": "first"},
+		RetryableErrorMessages: make([]string, 1, 4),
+	}
+	config.RetryableErrorMessages[0] = "lock timeout"
+
+	_, err := NewClient(
+		WithConfig(config),
+		WithHeaders(map[string]string{"X-Trace": "second"}),
+		WithRetryableErrorMessages("deadlock"),
+	)
+	if err != nil {
+		t.Fatalf("NewClient failed unexpectedly: %v", err)
+	}
+	if got := config.Headers["X-Trace"]; got != "first" {
+		t.Errorf("expected the caller's headers to stay unchanged, got X-Trace=%q", got)
+	}
+	if got := config.RetryableErrorMessages[:cap(config.RetryableErrorMessages)][1]; got != "" {
+		t.Errorf("expected the caller's messages to stay unchanged, got %q appended", got)
+	}
+}
+
+func TestNewClient_WithConfigAppliedInOrder(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{FakeResponse: okResponse()}
+
+	rallyClient, err := NewClient(
+		WithAPIKey("ignored"),
+		WithConfig(&Config{APIKey: "abcdef"}),
+		WithAPIKey("override"),
+		WithHTTPClient(fakeClient),
+	)
+	if err != nil {
+		t.Fatalf("NewClient failed unexpectedly: %v", err)
+	}
+
+	fakeOutput := new(fakes.FakeOutput)
+	if err := rallyClient.GetRequest(context.Background(), "12345", "defect", &fakeOutput); err != nil {
+		t.Fatalf("GetRequest failed unexpectedly: %v", err)
+	}
+	if got := fakeClient.SpyRequest.Header.Get("ZSESSIONID"); got != "override" {
+		t.Errorf("expected the last option to win, got ZSESSIONID=%q", got)
+	}
+	if got := fakeClient.SpyRequest.URL.String(); !strings.HasPrefix(got, DefaultBaseURL) {
+		t.Errorf("expected the default base URL, got %s", got)
+	}
+}
+
+func TestNewClient_WithConfigNil(t *testing.T) {
+	if _, err := NewClient(WithConfig(nil)); err == nil {
+		t.Error("expected an error for a nil config")
+	}
+}
+
+func TestNewClient_NilHTTPClientUsesConfiguredTimeout(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     []Option
+		expected time.Duration
+	}{
+		{"no config", []Option{WithHTTPClient(nil)}, DefaultTimeout * time.Second},
+		{"typed nil", []Option{WithHTTPClient((*http.Client)(nil))}, DefaultTimeout * time.Second},
+		{"config timeout", []Option{WithConfig(&Config{Timeout: 5}), WithHTTPClient(nil)}, 5 * time.Second},
+		{"zero timeout", []Option{WithConfig(&Config{})}, DefaultTimeout * time.Second},
+	}
+
+	for _, tt := range tests {
+		rallyClient, err := NewClient(tt.opts...)
+		if err != nil {
+			t.Fatalf("%s: NewClient failed unexpectedly: %v", tt.name, err)
+		}
+		httpClient, ok := rallyClient.HTTPClient().(*http.Client)
+		if !ok {
+			t.Fatalf("%s: expected default *http.Client, got %T", tt.name, rallyClient.HTTPClient())
+		}
+		if httpClient.Timeout != tt.expected {
+			t.Errorf("%s: expected Timeout=%v, got %v", tt.name, tt.expected, httpClient.Timeout)
+		}
+	}
+}
+
+func TestNewClient_WithDefaultWorkspace(t *testing.T) {
+	tests := []struct {
+		name      string
+		workspace string
+		expected  string
+	}{
+		{"client default", "", "/workspace/1"},
+		{"per call", "/workspace/2", "/workspace/2"},
+	}
+
+	for _, tt := range tests {
+		fakeClient := &fakes.FakeHTTPClient{FakeResponse: okResponse()}
+		rallyClient, err := NewClient(WithDefaultWorkspace("/workspace/1"), WithHTTPClient(fakeClient))
+		if err != nil {
+			t.Fatalf("%s: NewClient failed unexpectedly: %v", tt.name, err)
+		}
+
+		fakeOutput := new(fakes.FakeOutput)
+		if err := rallyClient.QueryRequestWithOptions(context.Background(), nil, "defect", QueryOptions{Workspace: tt.workspace}, &fakeOutput); err != nil {
+			t.Fatalf("%s: QueryRequestWithOptions failed unexpectedly: %v", tt.name, err)
+		}
+		if got := fakeClient.SpyRequest.URL.Query().Get("workspace"); got != tt.expected {
+			t.Errorf("%s: expected workspace %q, got %q", tt.name, tt.expected, got)
+		}
+	}
+}
+
+func TestNewClient_WithDefaultProject(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{FakeResponse: okResponse()}
+	rallyClient, err := NewClient(WithDefaultProject("/project/1"), WithHTTPClient(fakeClient))
+	if err != nil {
+		t.Fatalf("NewClient failed unexpectedly: %v", err)
+	}
+
+	fakeOutput := new(fakes.FakeOutput)
+	if err := rallyClient.QueryRequest(context.Background(), nil, "defect", &fakeOutput); err != nil {
+		t.Fatalf("QueryRequest failed unexpectedly: %v", err)
+	}
+	if got := fakeClient.SpyRequest.URL.Query().Get("project"); got != "/project/1" {
+		t.Errorf("expected project /project/1, got %q", got)
+	}
+}
+
+func TestNewClient_WithHeaders(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{FakeResponse: okResponse()}
+	rallyClient, err := NewClient(
+		WithAPIKey("abcdef"),
+		WithHeaders(map[string]string{"X-Gateway-Key": "gw", "X-Trace": "first"}),
+		WithHeaders(map[string]string{"X-Trace": "second"}),
+		WithHTTPClient(fakeClient),
+	)
+	if err != nil {
+		t.Fatalf("NewClient failed unexpectedly: %v", err)
+	}
+
+	fakeOutput := new(fakes.FakeOutput)
+	if err := rallyClient.GetRequest(context.Background(), "12345", "defect", &fakeOutput); err != nil {
+		t.Fatalf("GetRequest failed unexpectedly: %v", err)
+	}
+	for name, expected := range map[string]string{"X-Gateway-Key": "gw", "X-Trace": "second", "ZSESSIONID": "abcdef"} {
+		if got := fakeClient.SpyRequest.Header.Get(name); got != expected {
+			t.Errorf("expected %s=%q, got %q", name, expected, got)
+		}
+	}
+}
+
+func TestNewClient_WithHeadersRejectsAPIKey(t *testing.T) {
+	if _, err := NewClient(WithHeaders(map[string]string{"zsessionid": "abcdef"})); err == nil {
+		t.Error("expected an error for a ZSESSIONID header")
+	}
+}
+
+func TestNew_CompatibilityShim(t *testing.T) {
+	rallyClient := New("abcdef", "http://myRallyUrl", nil)
+
+	httpClient, ok := rallyClient.HTTPClient().(*http.Client)
+	if !ok {
+		t.Fatalf("expected default *http.Client, got %T", rallyClient.HTTPClient())
+	}
+	if httpClient.Timeout != DefaultTimeout*time.Second {
+		t.Errorf("expected Timeout=%v, got %v", DefaultTimeout*time.Second, httpClient.Timeout)
+	}
+}
+
+// countingLimiter permits every attempt, counting them.
+type countingLimiter struct {
+	waits int
+}
+
+func (l *countingLimiter) Wait(ctx context.Context) error {
+	l.waits++
+	return ctx.Err()
+}
+
+func TestNewClient_WithRateLimiter(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{
+		FakeResponses: []*http.Response{errorResponse(http.StatusServiceUnavailable), okResponse()},
+	}
+	limiter := &countingLimiter{}
+
+	rallyClient, err := NewClient(
+		WithHTTPClient(fakeClient),
+		WithRetries(1, time.Millisecond),
+		WithRateLimiter(limiter),
+	)
+	if err != nil {
+		t.Fatalf("NewClient failed unexpectedly: %v", err)
+	}
+
+	fakeOutput := new(fakes.FakeOutput)
+	if err := rallyClient.GetRequest(context.Background(), "12345", "defect", &fakeOutput); err != nil {
+		t.Fatalf("GetRequest failed unexpectedly: %v", err)
+	}
+	if limiter.waits != 2 {
+		t.Errorf("expected the limiter to gate both attempts, got %d waits", limiter.waits)
+	}
+}
+
+func TestNewClient_WithRateLimiterNil(t *testing.T) {
+	if _, err := NewClient(WithRateLimiter(nil)); err == nil {
+		t.Error("expected an error for a nil rate limiter")
+	}
+}
+
+func TestNewClient_ConfigRateLimit(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   Config
+		expected []time.Duration
+	}{
+		{"unlimited", Config{}, nil},
+		{"default burst", Config{RequestsPerSecond: 10}, []time.Duration{100 * time.Millisecond, 100 * time.Millisecond, 100 * time.Millisecond}},
+		{"burst", Config{RequestsPerSecond: 10, Burst: 3}, []time.Duration{100 * time.Millisecond}},
+	}
+
+	for _, tt := range tests {
+		fakeClient := &fakes.FakeHTTPClient{FakeResponse: okResponse()}
+		clock := &fakes.FakeClock{Current: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+		rallyClient, err := NewWithConfig(&tt.config, WithHTTPClient(fakeClient), WithClock(clock))
+		if err != nil {
+			t.Fatalf("%s: NewWithConfig failed unexpectedly: %v", tt.name, err)
+		}
+
+		for i := 0; i < 4; i++ {
+			fakeClient.FakeResponse = okResponse()
+			fakeOutput := new(fakes.FakeOutput)
+			if err := rallyClient.GetRequest(context.Background(), "12345", "defect", &fakeOutput); err != nil {
+				t.Fatalf("%s: GetRequest failed unexpectedly: %v", tt.name, err)
+			}
+		}
+		if !reflect.DeepEqual(clock.Sleeps, tt.expected) {
+			t.Errorf("%s: expected sleeps %v, got %v", tt.name, tt.expected, clock.Sleeps)
+		}
+	}
+}
+
+func TestNewClient_WithRateLimiterOverridesConfig(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{FakeResponse: okResponse()}
+	clock := &fakes.FakeClock{Current: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	limiter := &countingLimiter{}
+
+	rallyClient, err := NewWithConfig(&Config{RequestsPerSecond: 0.001}, WithHTTPClient(fakeClient), WithClock(clock), WithRateLimiter(limiter))
+	if err != nil {
+		t.Fatalf("NewWithConfig failed unexpectedly: %v", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		fakeClient.FakeResponse = okResponse()
+		fakeOutput := new(fakes.FakeOutput)
+		if err := rallyClient.GetRequest(context.Background(), "12345", "defect", &fakeOutput); err != nil {
+			t.Fatalf("GetRequest failed unexpectedly: %v", err)
+		}
+	}
+	if limiter.waits != 2 || len(clock.Sleeps) != 0 {
+		t.Errorf("expected only the injected limiter, got %d waits and sleeps %v", limiter.waits, clock.Sleeps)
+	}
+}
+
+func TestSetConfig_AppliesRateLimit(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{FakeResponse: okResponse()}
+	rallyClient := New("abcdef", "http://myRallyUrl", fakeClient)
+	rallyClient.SetConfig(&Config{RequestsPerSecond: 0.001, Burst: 1})
+
+	fakeOutput := new(fakes.FakeOutput)
+	if err := rallyClient.GetRequest(context.Background(), "12345", "defect", &fakeOutput); err != nil {
+		t.Fatalf("GetRequest failed unexpectedly: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	fakeClient.FakeResponse = okResponse()
+	if err := rallyClient.GetRequest(ctx, "12345", "defect", &fakeOutput); err == nil {
+		t.Fatal("expected the second request to wait on the rate limit until the context expired")
+	}
+	if fakeClient.CallCount != 1 {
+		t.Errorf("expected 1 permitted call, got %d", fakeClient.CallCount)
+	}
+
+	// Dropping the rate from the config lifts the limit.
+	rallyClient.SetConfig(&Config{})
+	if err := rallyClient.GetRequest(context.Background(), "12345", "defect", &fakeOutput); err != nil {
+		t.Fatalf("GetRequest failed unexpectedly after removing the limit: %v", err)
+	}
+}
+
+func TestSetConfig_KeepsInjectedRateLimiter(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{FakeResponse: okResponse()}
+	limiter := &countingLimiter{}
+	rallyClient, err := NewClient(WithHTTPClient(fakeClient), WithRateLimiter(limiter))
+	if err != nil {
+		t.Fatalf("NewClient failed unexpectedly: %v", err)
+	}
+	rallyClient.SetConfig(&Config{})
+
+	fakeOutput := new(fakes.FakeOutput)
+	if err := rallyClient.GetRequest(context.Background(), "12345", "defect", &fakeOutput); err != nil {
+		t.Fatalf("GetRequest failed unexpectedly: %v", err)
+	}
+	if limiter.waits != 1 {
+		t.Errorf("expected the injected limiter to survive SetConfig, got %d waits", limiter.waits)
+	}
+}
@@ -0,0 +1,95 @@
+/**
+* Copyright 2014 Comcast Cable Communications Management, LLC
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+* http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package rallyresttoolkit
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// SortDirection is the direction of one sort key in an OrderBy.
+type SortDirection string
+
+const (
+	// Asc sorts in ascending order.
+	Asc SortDirection = "ASC"
+	// Desc sorts in descending order.
+	Desc SortDirection = "DESC"
+)
+
+// ErrInvalidOrder is returned when an OrderBy has an empty field name or an
+// unknown direction.
+var ErrInvalidOrder = errors.New("invalid order")
+
+// OrderBy builds Rally's order parameter from one or more sort keys, e.g.
+// OrderBy{}.Asc("Priority").Desc("CreationDate") gives
+// "Priority ASC,CreationDate DESC". Keys apply in the order they are added.
+type OrderBy struct {
+	keys []sortKey
+}
+
+type sortKey struct {
+	field     string
+	direction SortDirection
+}
+
+// Asc adds a key sorting field in ascending order.
+func (o OrderBy) Asc(field string) OrderBy {
+	return o.By(field, Asc)
+}
+
+// Desc adds a key sorting field in descending order.
+func (o OrderBy) Desc(field string) OrderBy {
+	return o.By(field, Desc)
+}
+
+// By adds a key sorting field in the given direction.
+func (o OrderBy) By(field string, direction SortDirection) OrderBy {
+	keys := make([]sortKey, len(o.keys), len(o.keys)+1)
+	copy(keys, o.keys)
+	o.keys = append(keys, sortKey{field: field, direction: direction})
+	return o
+}
+
+// IsZero reports whether no sort keys have been added.
+func (o OrderBy) IsZero() bool {
+	return len(o.keys) == 0
+}
+
+// Validate checks that every key has a field name and a known direction.
+func (o OrderBy) Validate() error {
+	for i, key := range o.keys {
+		if strings.TrimSpace(key.field) == "" {
+			return fmt.Errorf("%w: sort key %d has an empty field name", ErrInvalidOrder, i+1)
+		}
+		if key.direction != Asc && key.direction != Desc {
+			return fmt.Errorf("%w: sort key %q has direction %q, expected ASC or DESC", ErrInvalidOrder, key.field, key.direction)
+		}
+	}
+	return nil
+}
+
+// String returns the order parameter, e.g. "Priority ASC,CreationDate DESC".
+// Call Validate first; String does not check the keys.
+func (o OrderBy) String() string {
+	parts := make([]string, 0, len(o.keys))
+	for _, key := range o.keys {
+		parts = append(parts, strings.TrimSpace(key.field)+" "+string(key.direction))
+	}
+	return strings.Join(parts, ",")
+}
@@ -0,0 +1,83 @@
+/**
+* Copyright 2014 Comcast Cable Communications Management, LLC
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+* http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package rallyresttoolkit_test
+
+import (
+	"errors"
+	"testing"
+
+	. "github.com/aleksofficial/go-rally-rest-toolkit"
+)
+
+func TestOrderBy_String(t *testing.T) {
+	tests := []struct {
+		name     string
+		order    OrderBy
+		expected string
+	}{
+		{"empty", OrderBy{}, ""},
+		{"single", OrderBy{}.Asc("Priority"), "Priority ASC"},
+		{"multiple", OrderBy{}.Asc("Priority").Desc("CreationDate"), "Priority ASC,CreationDate DESC"},
+		{"explicit direction", OrderBy{}.By("DragAndDropRank", Asc), "DragAndDropRank ASC"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.order.Validate(); err != nil {
+				t.Fatalf("Validate failed unexpectedly: %v", err)
+			}
+			if got := tt.order.String(); got != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestOrderBy_Immutable(t *testing.T) {
+	base := OrderBy{}.Asc("Priority")
+	first := base.Desc("CreationDate")
+	second := base.Asc("Name")
+
+	if got := first.String(); got != "Priority ASC,CreationDate DESC" {
+		t.Errorf("unexpected first order: %q", got)
+	}
+	if got := second.String(); got != "Priority ASC,Name ASC" {
+		t.Errorf("unexpected second order: %q", got)
+	}
+	if got := base.String(); got != "Priority ASC" {
+		t.Errorf("expected base order to be unchanged, got %q", got)
+	}
+}
+
+func TestOrderBy_Validate(t *testing.T) {
+	tests := []struct {
+		name  string
+		order OrderBy
+	}{
+		{"empty field", OrderBy{}.Asc("")},
+		{"blank field", OrderBy{}.Asc("Priority").Desc("  ")},
+		{"unknown direction", OrderBy{}.By("Priority", SortDirection("SIDEWAYS"))},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.order.Validate(); !errors.Is(err, ErrInvalidOrder) {
+				t.Errorf("expected ErrInvalidOrder, got %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,77 @@
+/**
+* Copyright 2014 Comcast Cable Communications Management, LLC
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+* http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package rallyresttoolkit
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/aleksofficial/go-rally-rest-toolkit/models"
+)
+
+// AssignOwner - sets the Owner of an object, sending only Owner so no other
+// field is overwritten. userRef is a user's _ref or bare ObjectID, which is
+// expanded to a ref on the client's base URL. output receives the
+// OperationResult and may be nil.
+func (s *RallyClient) AssignOwner(ctx context.Context, queryType string, objectID string, userRef string, output interface{}) error {
+	ref, err := s.userRef(userRef)
+	if err != nil {
+		return err
+	}
+
+	key := queryType
+	if e, ok := lookupEntity(queryType); ok {
+		key = e.name
+	}
+	updateRequest := map[string]interface{}{key: map[string]string{"Owner": ref}}
+	return s.UpdateRequest(ctx, objectID, queryType, updateRequest, output)
+}
+
+// userRef returns ref unchanged, or the ref of the user with that ObjectID
+// when ref is a bare number.
+func (s *RallyClient) userRef(ref string) (string, error) {
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return "", fmt.Errorf("%w: empty user ref", ErrInvalidRef)
+	}
+	if strings.Trim(ref, "0123456789") == "" {
+		return strings.TrimSuffix(s.apiurl, "/") + "/user/" + ref, nil
+	}
+	return ref, nil
+}
+
+// AssignOwner - abstraction for RallyClient.AssignOwner that returns the updated defect
+func (s *Defect) AssignOwner(ctx context.Context, objectID string, userRef string) (der models.Defect, err error) {
+	ude := new(deOperationResponse)
+	err = s.client.AssignOwner(ctx, "defect", objectID, userRef, &ude)
+	return ude.OperationalResult.Object, err
+}
+
+// AssignOwner - abstraction for RallyClient.AssignOwner that returns the updated story
+func (s *HierarchicalRequirement) AssignOwner(ctx context.Context, objectID string, userRef string) (hrr models.HierarchicalRequirement, err error) {
+	uhr := new(OperationResponse)
+	err = s.client.AssignOwner(ctx, "HierarchicalRequirement", objectID, userRef, &uhr)
+	return uhr.OperationalResult.Object, err
+}
+
+// AssignOwner - abstraction for RallyClient.AssignOwner that returns the updated task
+func (s *Task) AssignOwner(ctx context.Context, objectID string, userRef string) (tr models.Task, err error) {
+	ut := new(taskOperationResponse)
+	err = s.client.AssignOwner(ctx, "task", objectID, userRef, &ut)
+	return ut.OperationalResult.Object, err
+}
@@ -0,0 +1,86 @@
+/**
+* Copyright 2014 Comcast Cable Communications Management, LLC
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+* http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package rallyresttoolkit_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+
+	. "github.com/aleksofficial/go-rally-rest-toolkit"
+	"github.com/aleksofficial/go-rally-rest-toolkit/fakes"
+)
+
+func assignedOwner(t *testing.T, fakeClient *fakes.FakeHTTPClient) map[string]map[string]string {
+	t.Helper()
+	var body map[string]map[string]string
+	if err := json.NewDecoder(fakeClient.SpyRequest.Body).Decode(&body); err != nil {
+		t.Fatalf("failed to decode request body: %v", err)
+	}
+	return body
+}
+
+func TestAssignOwner_FullRef(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{
+		FakeResponse: jsonResponse(`{"OperationResult": {"Errors": [], "Object": {"ObjectID": 5, "FormattedID": "DE5", "Owner": {"_ref": "https://rally1.rallydev.com/slm/webservice/v2.0/user/42"}}}}`),
+	}
+	defect := NewDefect(New("abcdef", "http://myRallyUrl", fakeClient))
+
+	de, err := defect.AssignOwner(context.Background(), "5", "https://rally1.rallydev.com/slm/webservice/v2.0/user/42")
+	if err != nil {
+		t.Fatalf("AssignOwner failed unexpectedly: %v", err)
+	}
+	if de.Owner == nil || de.Owner.Ref != "https://rally1.rallydev.com/slm/webservice/v2.0/user/42" {
+		t.Errorf("expected the updated defect to be returned, got %+v", de)
+	}
+	if got := fakeClient.SpyRequest.URL.String(); got != "http://myRallyUrl/defect/5" {
+		t.Errorf("unexpected URL: %s", got)
+	}
+	expected := map[string]map[string]string{"Defect": {"Owner": "https://rally1.rallydev.com/slm/webservice/v2.0/user/42"}}
+	if body := assignedOwner(t, fakeClient); !reflect.DeepEqual(body, expected) {
+		t.Errorf("expected only Owner to be sent, got %v", body)
+	}
+}
+
+func TestAssignOwner_BareObjectID(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{
+		FakeResponse: jsonResponse(`{"OperationResult": {"Errors": [], "Object": {"ObjectID": 8}}}`),
+	}
+	task := NewTask(New("abcdef", "http://myRallyUrl/", fakeClient))
+
+	if _, err := task.AssignOwner(context.Background(), "8", "42"); err != nil {
+		t.Fatalf("AssignOwner failed unexpectedly: %v", err)
+	}
+	expected := map[string]map[string]string{"Task": {"Owner": "http://myRallyUrl/user/42"}}
+	if body := assignedOwner(t, fakeClient); !reflect.DeepEqual(body, expected) {
+		t.Errorf("expected the ref to be built from the base URL, got %v", body)
+	}
+}
+
+func TestAssignOwner_EmptyRef(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{}
+	hr := NewHierarchicalRequirement(New("abcdef", "http://myRallyUrl", fakeClient))
+
+	if _, err := hr.AssignOwner(context.Background(), "8", " "); !errors.Is(err, ErrInvalidRef) {
+		t.Errorf("expected ErrInvalidRef, got %v", err)
+	}
+	if fakeClient.CallCount != 0 {
+		t.Errorf("expected no request, got %d", fakeClient.CallCount)
+	}
+}
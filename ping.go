@@ -0,0 +1,71 @@
+/**
+* Copyright 2014 Comcast Cable Communications Management, LLC
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+* http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package rallyresttoolkit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+var (
+	// ErrUnauthorized is returned by Ping when Rally rejects the API key
+	// (HTTP 401 or 403).
+	ErrUnauthorized = errors.New("rally: API key rejected")
+	// ErrUnreachable is returned by Ping when Rally could not be reached at all,
+	// e.g. DNS, TLS or connection failures.
+	ErrUnreachable = errors.New("rally: unable to reach Rally")
+)
+
+// Ping checks connectivity and that the API key is valid by fetching the
+// subscription, which every key can read. It returns nil on success. Auth
+// failures, including a non-JSON login page in place of the subscription,
+// wrap ErrUnauthorized and transport failures wrap ErrUnreachable; the
+// underlying *RallyAPIError or transport error is still reachable via
+// errors.As. Cancellation, deadlines, ErrClientClosed and any other Rally
+// error are returned as is.
+func (s *RallyClient) Ping(ctx context.Context) error {
+	u, err := s.endpoint("subscription")
+	if err != nil {
+		return err
+	}
+	u.RawQuery = url.Values{"fetch": {"ObjectID"}}.Encode()
+
+	err = s.execute(ctx, "GET", u, nil, nil)
+	if err == nil {
+		return nil
+	}
+
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) || errors.Is(err, ErrClientClosed) {
+		return err
+	}
+	// A rejected key can be redirected to an HTML login page instead of a 401.
+	if errors.Is(err, ErrNonJSONResponse) {
+		return fmt.Errorf("%w: %w", ErrUnauthorized, err)
+	}
+
+	var apiErr *RallyAPIError
+	if !errors.As(err, &apiErr) {
+		return fmt.Errorf("%w: %w", ErrUnreachable, err)
+	}
+	if apiErr.StatusCode == http.StatusUnauthorized || apiErr.StatusCode == http.StatusForbidden {
+		return fmt.Errorf("%w: %w", ErrUnauthorized, err)
+	}
+	return err
+}
@@ -0,0 +1,146 @@
+/**
+* Copyright 2014 Comcast Cable Communications Management, LLC
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+* http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package rallyresttoolkit_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"testing"
+
+	. "github.com/aleksofficial/go-rally-rest-toolkit"
+	"github.com/aleksofficial/go-rally-rest-toolkit/fakes"
+)
+
+func TestPing_Success(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{
+		FakeResponse: &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       &fakes.FakeResponseBody{Reader: bytes.NewBufferString(`{"Subscription": {"ObjectID": 1234}}`)},
+		},
+	}
+	rallyClient := New("abcdef", "http://myRallyUrl", fakeClient)
+
+	if err := rallyClient.Ping(context.Background()); err != nil {
+		t.Fatalf("Ping failed unexpectedly: %v", err)
+	}
+	if got := fakeClient.SpyRequest.URL.Path; got != "/subscription" {
+		t.Errorf("expected Ping to hit /subscription, got %s", got)
+	}
+	if got := fakeClient.SpyRequest.Header.Get("ZSESSIONID"); got != "abcdef" {
+		t.Errorf("expected API key header, got %q", got)
+	}
+}
+
+func TestPing_Unauthorized(t *testing.T) {
+	for _, status := range []int{http.StatusUnauthorized, http.StatusForbidden} {
+		fakeClient := &fakes.FakeHTTPClient{
+			FakeResponse: &http.Response{
+				StatusCode: status,
+				Body:       &fakes.FakeResponseBody{Reader: bytes.NewBufferString(`{"OperationResult": {"Errors": ["Not authorized"]}}`)},
+			},
+		}
+		rallyClient := New("badkey", "http://myRallyUrl", fakeClient)
+
+		err := rallyClient.Ping(context.Background())
+		if !errors.Is(err, ErrUnauthorized) {
+			t.Errorf("status %d: expected ErrUnauthorized, got %v", status, err)
+		}
+		if errors.Is(err, ErrUnreachable) {
+			t.Errorf("status %d: auth failure must not be reported as ErrUnreachable", status)
+		}
+		var apiErr *RallyAPIError
+		if !errors.As(err, &apiErr) || apiErr.StatusCode != status {
+			t.Errorf("status %d: expected wrapped RallyAPIError, got %v", status, err)
+		}
+	}
+}
+
+func TestPing_Unreachable(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{
+		FakeError: errors.New("dial tcp: lookup myRallyUrl: no such host"),
+	}
+	rallyClient := New("abcdef", "http://myRallyUrl", fakeClient)
+
+	err := rallyClient.Ping(context.Background())
+	if !errors.Is(err, ErrUnreachable) {
+		t.Errorf("expected ErrUnreachable, got %v", err)
+	}
+	if errors.Is(err, ErrUnauthorized) {
+		t.Error("connectivity failure must not be reported as ErrUnauthorized")
+	}
+}
+
+func TestPing_OtherAPIError(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{
+		FakeResponse: &http.Response{
+			StatusCode: http.StatusNotFound,
+			Body:       &fakes.FakeResponseBody{Reader: bytes.NewBufferString(`{}`)},
+		},
+	}
+	rallyClient := New("abcdef", "http://myRallyUrl", fakeClient)
+
+	err := rallyClient.Ping(context.Background())
+	if err == nil || errors.Is(err, ErrUnauthorized) || errors.Is(err, ErrUnreachable) {
+		t.Errorf("expected a plain RallyAPIError, got %v", err)
+	}
+	if !errors.Is(err, ErrRallyAPI) {
+		t.Errorf("expected a RallyAPIError, got %v", err)
+	}
+}
+
+func TestPing_LoginPage(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{
+		FakeResponse: &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Content-Type": []string{"text/html; charset=utf-8"}},
+			Body:       &fakes.FakeResponseBody{Reader: bytes.NewBufferString(`<html><body>Sign in</body></html>`)},
+		},
+	}
+	rallyClient := New("badkey", "http://myRallyUrl", fakeClient)
+
+	err := rallyClient.Ping(context.Background())
+	if !errors.Is(err, ErrUnauthorized) || !errors.Is(err, ErrNonJSONResponse) {
+		t.Errorf("expected ErrUnauthorized wrapping ErrNonJSONResponse, got %v", err)
+	}
+}
+
+func TestPing_Cancelled(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{
+		FakeError: &url.Error{Op: "Get", URL: "http://myRallyUrl/subscription", Err: context.Canceled},
+	}
+	rallyClient := New("abcdef", "http://myRallyUrl", fakeClient)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := rallyClient.Ping(ctx)
+	if !errors.Is(err, context.Canceled) || errors.Is(err, ErrUnreachable) {
+		t.Errorf("expected context.Canceled without ErrUnreachable, got %v", err)
+	}
+}
+
+func TestPing_ClientClosed(t *testing.T) {
+	rallyClient := New("abcdef", "http://myRallyUrl", &fakes.FakeHTTPClient{})
+	rallyClient.Close()
+
+	err := rallyClient.Ping(context.Background())
+	if !errors.Is(err, ErrClientClosed) || errors.Is(err, ErrUnreachable) {
+		t.Errorf("expected ErrClientClosed without ErrUnreachable, got %v", err)
+	}
+}
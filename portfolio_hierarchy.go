@@ -0,0 +1,64 @@
+/**
+* Copyright 2014 Comcast Cable Communications Management, LLC
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+* http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package rallyresttoolkit
+
+import (
+	"context"
+
+	"github.com/aleksofficial/go-rally-rest-toolkit/models"
+)
+
+// WalkPortfolioHierarchy calls fn for the portfolio item rootRef points at and
+// then, depth first, for each of its descendants, following the Children
+// collection of every item. depth is 0 for the root. An item reached twice,
+// e.g. through a parent cycle, is only visited once. The walk stops at the
+// first error from fn or Rally, or when ctx is done.
+func (s *PortfolioItem) WalkPortfolioHierarchy(ctx context.Context, rootRef string, fn func(item models.PortfolioItem, depth int) error) error {
+	root, err := GetByRef[models.PortfolioItem](ctx, s.client, rootRef)
+	if err != nil {
+		return err
+	}
+	return s.walkPortfolioItem(ctx, root, 0, map[int]bool{}, fn)
+}
+
+func (s *PortfolioItem) walkPortfolioItem(ctx context.Context, item models.PortfolioItem, depth int, visited map[int]bool, fn func(item models.PortfolioItem, depth int) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if visited[item.ObjectID] {
+		return nil
+	}
+	visited[item.ObjectID] = true
+
+	if err := fn(item, depth); err != nil {
+		return err
+	}
+	if item.Children == nil || item.Children.Ref == "" {
+		return nil
+	}
+
+	children, err := GetCollection[models.PortfolioItem](ctx, s.client, item.Children.Ref)
+	if err != nil {
+		return err
+	}
+	for _, child := range children {
+		if err := s.walkPortfolioItem(ctx, child, depth+1, visited, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
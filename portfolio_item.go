@@ -0,0 +1,150 @@
+/**
+* Copyright 2014 Comcast Cable Communications Management, LLC
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+* http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package rallyresttoolkit
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/aleksofficial/go-rally-rest-toolkit/models"
+)
+
+// PortfolioItem - struct to hold client
+type PortfolioItem struct {
+	client    *RallyClient
+	queryType string
+	key       string
+}
+
+// QueryPortfolioItemResponse - struct to contain query response
+type QueryPortfolioItemResponse = models.QueryResponse[models.PortfolioItem]
+
+type piResult struct {
+	Object models.PortfolioItem
+}
+
+// CreatePortfolioItemResponse - struct to contain response
+type CreatePortfolioItemResponse struct {
+	CreateResult piResult
+}
+
+type piOperationResponse = models.OperationResponse[models.PortfolioItem]
+
+// NewPortfolioItem - creates new PortfolioItem client for a portfolio item type
+// such as "Feature" or "BusinessInitiative". The type name is sent as given as
+// the envelope key of creates and updates. An empty itemType queries and reads
+// across all portfolio item types but cannot create.
+func NewPortfolioItem(client *RallyClient, itemType string) (pi *PortfolioItem) {
+	pi = &PortfolioItem{
+		client:    client,
+		queryType: "portfolioitem",
+		key:       "PortfolioItem",
+	}
+	if itemType != "" {
+		pi.queryType = "portfolioitem/" + strings.ToLower(itemType)
+		pi.key = itemType
+	}
+	return pi
+}
+
+// QueryPortfolioItem - abstraction for QueryRequest
+func (s *PortfolioItem) QueryPortfolioItem(ctx context.Context, query map[string]string) (pis []models.PortfolioItem, err error) {
+	qpis := new(QueryPortfolioItemResponse)
+	err = s.client.QueryRequest(ctx, query, s.queryType, &qpis)
+	return qpis.QueryResult.Results, err
+}
+
+// QueryPortfolioItemWithMeta - QueryRequest that also returns the envelope's paging metadata and Warnings
+func (s *PortfolioItem) QueryPortfolioItemWithMeta(ctx context.Context, query map[string]string) ([]models.PortfolioItem, QueryMeta, error) {
+	return Query[models.PortfolioItem](ctx, s.client, s.queryType, query, QueryOptions{})
+}
+
+// QueryPortfolioItemPage - abstraction for QueryRequestWithOptions that keeps the paging metadata
+func (s *PortfolioItem) QueryPortfolioItemPage(ctx context.Context, query map[string]string, opts QueryOptions) (Page[models.PortfolioItem], error) {
+	return queryPage[models.PortfolioItem](ctx, s.client, query, s.queryType, opts)
+}
+
+// QueryPortfolioItemRaw - abstraction for QueryRequestRaw, for query clauses written by hand
+func (s *PortfolioItem) QueryPortfolioItemRaw(ctx context.Context, rawQuery string, opts QueryOptions) (Page[models.PortfolioItem], error) {
+	return queryRawPage[models.PortfolioItem](ctx, s.client, rawQuery, s.queryType, opts)
+}
+
+// QueryPortfolioItemWith - abstraction for QueryRequestWith
+func (s *PortfolioItem) QueryPortfolioItemWith(ctx context.Context, b *QueryBuilder) (Page[models.PortfolioItem], error) {
+	return queryWithPage[models.PortfolioItem](ctx, s.client, b, s.queryType)
+}
+
+// FindPortfolioItemsByName - lists every portfolio item whose Name contains name, ignoring case
+func (s *PortfolioItem) FindPortfolioItemsByName(ctx context.Context, name string) ([]models.PortfolioItem, error) {
+	return queryAllExprResults[models.PortfolioItem](ctx, s.client, NameContainsAnyCase("Name", name), s.queryType)
+}
+
+// GetPortfolioItem - abstraction for GetRequest. The object is read from under
+// the type name Rally returns, and a response without one is an error.
+func (s *PortfolioItem) GetPortfolioItem(ctx context.Context, objectID string) (pi models.PortfolioItem, err error) {
+	return Get[models.PortfolioItem](ctx, s.client, s.queryType, "", objectID)
+}
+
+// CreatePortfolioItem - abstraction for CreateRequest
+func (s *PortfolioItem) CreatePortfolioItem(ctx context.Context, pi models.PortfolioItem) (pir models.PortfolioItem, err error) {
+	createRequest, err := writeRequest(s.key, pi)
+	if err != nil {
+		return pir, err
+	}
+	upi := new(CreatePortfolioItemResponse)
+	err = s.client.CreateRequest(ctx, s.queryType, createRequest, &upi)
+	pir = upi.CreateResult.Object
+	return pir, err
+}
+
+// FindOrCreatePortfolioItem - returns the first portfolio item matching matchQuery, e.g. on a unique
+// external ID, or else creates pi; the bool reports whether it was created
+func (s *PortfolioItem) FindOrCreatePortfolioItem(ctx context.Context, matchQuery map[string]string, pi models.PortfolioItem) (models.PortfolioItem, bool, error) {
+	return findOrCreate[models.PortfolioItem](ctx, s.client, s.queryType, matchQuery, func(ctx context.Context) (models.PortfolioItem, error) {
+		return s.CreatePortfolioItem(ctx, pi)
+	})
+}
+
+// UpdatePortfolioItem - abstraction for UpdateRequest
+func (s *PortfolioItem) UpdatePortfolioItem(ctx context.Context, pi models.PortfolioItem) (pir models.PortfolioItem, err error) {
+	updateRequest, err := writeRequest(s.key, pi)
+	if err != nil {
+		return pir, err
+	}
+	upi := new(piOperationResponse)
+	err = s.client.UpdateRequest(ctx, strconv.Itoa(pi.ObjectID), s.queryType, updateRequest, &upi)
+	pir = upi.OperationalResult.Object
+	return pir, err
+}
+
+// DeletePortfolioItem - abstraction for DeleteRequest
+func (s *PortfolioItem) DeletePortfolioItem(ctx context.Context, objectID string) (err error) {
+	upi := new(piOperationResponse)
+	err = s.client.DeleteRequest(ctx, objectID, s.queryType, &upi)
+	return err
+}
+
+// AddMilestone - adds a milestone, by ref, to the Milestones collection of a portfolio item
+func (s *PortfolioItem) AddMilestone(ctx context.Context, objectID string, milestoneRef string) error {
+	return s.client.AddToCollection(ctx, s.queryType, objectID, "Milestones", []string{milestoneRef}, nil)
+}
+
+// RemoveMilestone - removes a milestone, by ref, from the Milestones collection of a portfolio item
+func (s *PortfolioItem) RemoveMilestone(ctx context.Context, objectID string, milestoneRef string) error {
+	return s.client.RemoveFromCollection(ctx, s.queryType, objectID, "Milestones", []string{milestoneRef}, nil)
+}
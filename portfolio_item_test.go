@@ -0,0 +1,412 @@
+/**
+* Copyright 2014 Comcast Cable Communications Management, LLC
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+* http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package rallyresttoolkit_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	. "github.com/aleksofficial/go-rally-rest-toolkit"
+	"github.com/aleksofficial/go-rally-rest-toolkit/fakes"
+	"github.com/aleksofficial/go-rally-rest-toolkit/models"
+)
+
+func TestQueryPortfolioItem_Milestones(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{
+		FakeResponse: &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       &fakes.FakeResponseBody{Reader: bytes.NewBufferString(`{"QueryResult": { "TotalResultCount": 1, "Results": [{"ObjectID": 42, "FormattedID": "F42", "_type": "PortfolioItem/Feature", "Milestones": {"_ref": "https://rally1.rallydev.com/slm/webservice/v2.0/PortfolioItem/Feature/42/Milestones", "_type": "Milestone", "Count": 2}}]}}`)},
+		},
+	}
+
+	rallyClient := New("abcdef", "http://myRallyUrl", fakeClient)
+	featureClient := NewPortfolioItem(rallyClient, "Feature")
+
+	results, err := featureClient.QueryPortfolioItem(context.Background(), map[string]string{"FormattedID": "F42"})
+	if err != nil {
+		t.Fatalf("QueryPortfolioItem failed unexpectedly: %v", err)
+	}
+	if got := fakeClient.SpyRequest.URL.Path; got != "/portfolioitem/feature" {
+		t.Errorf("expected query against /portfolioitem/feature, got %s", got)
+	}
+	if len(results) != 1 {
+		t.Fatalf("expected 1 result, got %d", len(results))
+	}
+	milestones := results[0].Milestones
+	if milestones == nil || milestones.Count != 2 || !strings.HasSuffix(milestones.Ref, "/Feature/42/Milestones") {
+		t.Errorf("unexpected Milestones summary: %+v", milestones)
+	}
+}
+
+func TestGetPortfolioItem_ValidObjectID(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{
+		FakeResponse: &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       &fakes.FakeResponseBody{Reader: bytes.NewBufferString(`{"Feature": {"ObjectID": 42, "Name": "Single sign-on"}}`)},
+		},
+	}
+
+	rallyClient := New("abcdef", "http://myRallyUrl", fakeClient)
+	featureClient := NewPortfolioItem(rallyClient, "feature")
+
+	result, err := featureClient.GetPortfolioItem(context.Background(), "42")
+	if err != nil {
+		t.Fatalf("GetPortfolioItem failed unexpectedly: %v", err)
+	}
+	if result.ObjectID != 42 || result.Name != "Single sign-on" {
+		t.Errorf("unexpected portfolio item: %+v", result)
+	}
+}
+
+func TestCreatePortfolioItem_ValidRequest(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{
+		FakeResponse: &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       &fakes.FakeResponseBody{Reader: bytes.NewBufferString(`{"CreateResult": {"Object": {"Name": "Single sign-on", "ObjectID": 42}}}`)},
+		},
+	}
+
+	rallyClient := New("abcdef", "http://myRallyUrl", fakeClient)
+	featureClient := NewPortfolioItem(rallyClient, "feature")
+
+	result, err := featureClient.CreatePortfolioItem(context.Background(), models.PortfolioItem{Name: "Single sign-on"})
+	if err != nil {
+		t.Fatalf("CreatePortfolioItem failed unexpectedly: %v", err)
+	}
+	if result.ObjectID != 42 {
+		t.Errorf("expected ObjectID=42, got %d", result.ObjectID)
+	}
+	if got := fakeClient.SpyRequest.URL.Path; got != "/portfolioitem/feature/create" {
+		t.Errorf("unexpected create path: %s", got)
+	}
+}
+
+func TestUpdatePortfolioItem_WritesMilestoneRefs(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{
+		FakeResponse: &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       &fakes.FakeResponseBody{Reader: bytes.NewBufferString(`{"OperationalResult": {"Object": {"ObjectID": 42, "Milestones": {"Count": 1}}}}`)},
+		},
+	}
+
+	rallyClient := New("abcdef", "http://myRallyUrl", fakeClient)
+	featureClient := NewPortfolioItem(rallyClient, "feature")
+
+	update := models.PortfolioItem{
+		PersistableObject: models.PersistableObject{ObjectID: 42},
+		Milestones:        models.NewCollection("/milestone/777"),
+	}
+	result, err := featureClient.UpdatePortfolioItem(context.Background(), update)
+	if err != nil {
+		t.Fatalf("UpdatePortfolioItem failed unexpectedly: %v", err)
+	}
+	if result.Milestones == nil || result.Milestones.Count != 1 {
+		t.Errorf("unexpected Milestones on result: %+v", result.Milestones)
+	}
+
+	body, err := io.ReadAll(fakeClient.SpyRequest.Body)
+	if err != nil {
+		t.Fatalf("failed to read request body: %v", err)
+	}
+	if !strings.Contains(string(body), `"Milestones":[{"_ref":"/milestone/777"}]`) {
+		t.Errorf("expected Milestones written as a ref array, got %s", body)
+	}
+}
+
+func TestUpdatePortfolioItem_OmitsMilestoneSummary(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{
+		FakeResponse: &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       &fakes.FakeResponseBody{Reader: bytes.NewBufferString(`{"OperationalResult": {"Object": {"ObjectID": 42}}}`)},
+		},
+	}
+
+	rallyClient := New("abcdef", "http://myRallyUrl", fakeClient)
+	featureClient := NewPortfolioItem(rallyClient, "feature")
+
+	update := models.PortfolioItem{
+		PersistableObject: models.PersistableObject{ObjectID: 42},
+		Name:              "Renamed",
+		Milestones:        &models.Collection{Count: 2, Ref: "/PortfolioItem/Feature/42/Milestones"},
+	}
+	if _, err := featureClient.UpdatePortfolioItem(context.Background(), update); err != nil {
+		t.Fatalf("UpdatePortfolioItem failed unexpectedly: %v", err)
+	}
+
+	body, err := io.ReadAll(fakeClient.SpyRequest.Body)
+	if err != nil {
+		t.Fatalf("failed to read request body: %v", err)
+	}
+	if strings.Contains(string(body), "Milestones") {
+		t.Errorf("expected a read-back Milestones summary not to be written, got %s", body)
+	}
+}
+
+func TestRemoveMilestone_PortfolioItem(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{FakeResponse: collectionResponse()}
+
+	rallyClient := New("abcdef", "http://myRallyUrl", fakeClient)
+	featureClient := NewPortfolioItem(rallyClient, "feature")
+
+	if err := featureClient.RemoveMilestone(context.Background(), "42", "/milestone/777"); err != nil {
+		t.Fatalf("RemoveMilestone failed unexpectedly: %v", err)
+	}
+	if got := fakeClient.SpyRequest.URL.Path; got != "/portfolioitem/feature/42/Milestones/remove" {
+		t.Errorf("unexpected URL path: %s", got)
+	}
+}
+
+func TestDeletePortfolioItem_ValidObjectID(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{
+		FakeResponse: &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       &fakes.FakeResponseBody{Reader: bytes.NewBufferString(`{"OperationResult": {"Errors": [], "Warnings": []}}`)},
+		},
+	}
+
+	rallyClient := New("abcdef", "http://myRallyUrl", fakeClient)
+	featureClient := NewPortfolioItem(rallyClient, "feature")
+
+	if err := featureClient.DeletePortfolioItem(context.Background(), "42"); err != nil {
+		t.Fatalf("DeletePortfolioItem failed unexpectedly: %v", err)
+	}
+}
+
+func TestGetPortfolioItem_PlanningFields(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{
+		FakeResponse: &http.Response{
+			StatusCode: http.StatusOK,
+			Body: &fakes.FakeResponseBody{Reader: bytes.NewBufferString(`{"Feature": {
+				"ObjectID": 42,
+				"PreliminaryEstimate": {"_ref": "https://rally1.rallydev.com/slm/webservice/v2.0/preliminaryestimate/7", "_refObjectName": "M"},
+				"RefinedEstimate": 13,
+				"PercentDoneByStoryCount": 0.5,
+				"PercentDoneByStoryPlanEstimate": 0.65,
+				"InvestmentCategory": "Strategic",
+				"ValueScore": 8,
+				"RiskScore": 3,
+				"PlannedStartDate": "2016-04-01T06:00:00.000Z",
+				"PlannedEndDate": "2016-06-30T06:00:00.000Z"
+			}}`)},
+		},
+	}
+
+	rallyClient := New("abcdef", "http://myRallyUrl", fakeClient)
+	featureClient := NewPortfolioItem(rallyClient, "feature")
+
+	feature, err := featureClient.GetPortfolioItem(context.Background(), "42")
+	if err != nil {
+		t.Fatalf("GetPortfolioItem failed unexpectedly: %v", err)
+	}
+	if feature.PercentDoneByStoryPlanEstimate != 0.65 {
+		t.Errorf("expected PercentDoneByStoryPlanEstimate=0.65, got %v", feature.PercentDoneByStoryPlanEstimate)
+	}
+	if feature.PercentDoneByStoryCount != 0.5 {
+		t.Errorf("expected PercentDoneByStoryCount=0.5, got %v", feature.PercentDoneByStoryCount)
+	}
+	if feature.PreliminaryEstimate == nil || feature.PreliminaryEstimate.Ref != "https://rally1.rallydev.com/slm/webservice/v2.0/preliminaryestimate/7" {
+		t.Errorf("unexpected PreliminaryEstimate: %+v", feature.PreliminaryEstimate)
+	}
+	if feature.RefinedEstimate != 13 || feature.InvestmentCategory != "Strategic" || feature.ValueScore != 8 || feature.RiskScore != 3 {
+		t.Errorf("unexpected planning fields: %+v", feature)
+	}
+	if feature.PlannedStartDate == nil || feature.PlannedStartDate.UTC().Format("2006-01-02") != "2016-04-01" {
+		t.Errorf("unexpected PlannedStartDate: %v", feature.PlannedStartDate)
+	}
+	if feature.PlannedEndDate == nil || feature.PlannedEndDate.UTC().Format("2006-01-02") != "2016-06-30" {
+		t.Errorf("unexpected PlannedEndDate: %v", feature.PlannedEndDate)
+	}
+}
+
+func TestUpdatePortfolioItem_OmitsPercentDoneRollups(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{
+		FakeResponse: &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       &fakes.FakeResponseBody{Reader: bytes.NewBufferString(`{"OperationalResult": {"Object": {"ObjectID": 42}}}`)},
+		},
+	}
+
+	rallyClient := New("abcdef", "http://myRallyUrl", fakeClient)
+	featureClient := NewPortfolioItem(rallyClient, "feature")
+
+	update := models.PortfolioItem{
+		PersistableObject:              models.PersistableObject{ObjectID: 42},
+		RefinedEstimate:                21,
+		InvestmentCategory:             "Maintenance",
+		ValueScore:                     5,
+		PercentDoneByStoryCount:        0.5,
+		PercentDoneByStoryPlanEstimate: 0.65,
+	}
+	if _, err := featureClient.UpdatePortfolioItem(context.Background(), update); err != nil {
+		t.Fatalf("UpdatePortfolioItem failed unexpectedly: %v", err)
+	}
+
+	body, err := io.ReadAll(fakeClient.SpyRequest.Body)
+	if err != nil {
+		t.Fatalf("failed to read request body: %v", err)
+	}
+	if strings.Contains(string(body), "PercentDone") {
+		t.Errorf("expected read-only percent-done rollups not to be written, got %s", body)
+	}
+	for _, field := range []string{`"RefinedEstimate":21`, `"InvestmentCategory":"Maintenance"`, `"ValueScore":5`} {
+		if !strings.Contains(string(body), field) {
+			t.Errorf("expected %s to be written, got %s", field, body)
+		}
+	}
+}
+
+func TestWalkPortfolioHierarchy_DepthFirstWithCycle(t *testing.T) {
+	const base = "https://rally1.rallydev.com/slm/webservice/v2.0"
+	fakeClient := &fakes.FakeHTTPClient{
+		FakeResponses: []*http.Response{
+			jsonResponse(`{"Initiative": {"ObjectID": 1, "Name": "Roadmap", "Children": {"_ref": "` + base + `/PortfolioItem/Initiative/1/Children", "Count": 2}}}`),
+			jsonResponse(`{"QueryResult": {"TotalResultCount": 2, "StartIndex": 1, "Results": [
+				{"ObjectID": 2, "Name": "Checkout", "Children": {"_ref": "` + base + `/PortfolioItem/Feature/2/Children", "Count": 1}},
+				{"ObjectID": 3, "Name": "Search"}]}}`),
+			// A bad parent link makes the initiative its own grandchild.
+			jsonResponse(`{"QueryResult": {"TotalResultCount": 1, "StartIndex": 1, "Results": [
+				{"ObjectID": 1, "Name": "Roadmap", "Children": {"_ref": "` + base + `/PortfolioItem/Initiative/1/Children", "Count": 2}}]}}`),
+		},
+	}
+	piClient := NewPortfolioItem(New("abcdef", "http://myRallyUrl", fakeClient), "")
+
+	var visited []string
+	err := piClient.WalkPortfolioHierarchy(context.Background(), base+"/portfolioitem/initiative/1", func(item models.PortfolioItem, depth int) error {
+		visited = append(visited, fmt.Sprintf("%d:%s", depth, item.Name))
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("WalkPortfolioHierarchy failed unexpectedly: %v", err)
+	}
+	expected := "0:Roadmap 1:Checkout 1:Search"
+	if got := strings.Join(visited, " "); got != expected {
+		t.Errorf("expected visit order %q, got %q", expected, got)
+	}
+	if fakeClient.CallCount != 3 {
+		t.Errorf("expected 3 requests, got %d", fakeClient.CallCount)
+	}
+}
+
+func TestWalkPortfolioHierarchy_StopsOnCallbackError(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{
+		FakeResponse: jsonResponse(`{"Feature": {"ObjectID": 2, "Name": "Checkout", "Children": {"_ref": "/portfolioitem/feature/2/Children"}}}`),
+	}
+	piClient := NewPortfolioItem(New("abcdef", "http://myRallyUrl", fakeClient), "feature")
+
+	stop := errors.New("stop")
+	err := piClient.WalkPortfolioHierarchy(context.Background(), "/portfolioitem/feature/2", func(item models.PortfolioItem, depth int) error {
+		return stop
+	})
+	if !errors.Is(err, stop) {
+		t.Errorf("expected the callback error, got %v", err)
+	}
+	if fakeClient.CallCount != 1 {
+		t.Errorf("expected no children request after the callback failed, got %d calls", fakeClient.CallCount)
+	}
+}
+
+func TestWalkPortfolioHierarchy_ContextCancelled(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{
+		FakeResponse: jsonResponse(`{"Feature": {"ObjectID": 2, "Name": "Checkout"}}`),
+	}
+	piClient := NewPortfolioItem(New("abcdef", "http://myRallyUrl", fakeClient), "feature")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := piClient.WalkPortfolioHierarchy(ctx, "/portfolioitem/feature/2", func(item models.PortfolioItem, depth int) error {
+		t.Error("expected no visit after cancellation")
+		return nil
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestGetPortfolioItem_ResponseKey(t *testing.T) {
+	tests := []struct {
+		name     string
+		itemType string
+		body     string
+	}{
+		{"multi-word type", "BusinessInitiative", `{"BusinessInitiative": {"ObjectID": 42, "Name": "Grow"}}`},
+		{"lower-cased type", "businessinitiative", `{"BusinessInitiative": {"ObjectID": 42, "Name": "Grow"}}`},
+		{"any type", "", `{"Feature": {"ObjectID": 42, "Name": "Grow"}}`},
+	}
+
+	for _, tt := range tests {
+		fakeClient := &fakes.FakeHTTPClient{
+			FakeResponse: &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       &fakes.FakeResponseBody{Reader: bytes.NewBufferString(tt.body)},
+			},
+		}
+		piClient := NewPortfolioItem(New("abcdef", "http://myRallyUrl", fakeClient), tt.itemType)
+
+		result, err := piClient.GetPortfolioItem(context.Background(), "42")
+		if err != nil {
+			t.Fatalf("%s: GetPortfolioItem failed unexpectedly: %v", tt.name, err)
+		}
+		if result.ObjectID != 42 || result.Name != "Grow" {
+			t.Errorf("%s: unexpected portfolio item: %+v", tt.name, result)
+		}
+	}
+}
+
+func TestGetPortfolioItem_MissingKey(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{
+		FakeResponse: &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       &fakes.FakeResponseBody{Reader: bytes.NewBufferString(`{"Initiative": {"ObjectID": 42}}`)},
+		},
+	}
+	featureClient := NewPortfolioItem(New("abcdef", "http://myRallyUrl", fakeClient), "feature")
+
+	if _, err := featureClient.GetPortfolioItem(context.Background(), "42"); err == nil {
+		t.Error("expected an error for a response without a Feature object")
+	}
+}
+
+func TestCreatePortfolioItem_KeepsTypeName(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{
+		FakeResponse: &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       &fakes.FakeResponseBody{Reader: bytes.NewBufferString(`{"CreateResult": {"Object": {"ObjectID": 42}}}`)},
+		},
+	}
+	piClient := NewPortfolioItem(New("abcdef", "http://myRallyUrl", fakeClient), "BusinessInitiative")
+
+	if _, err := piClient.CreatePortfolioItem(context.Background(), models.PortfolioItem{Name: "Grow"}); err != nil {
+		t.Fatalf("CreatePortfolioItem failed unexpectedly: %v", err)
+	}
+	var body map[string]json.RawMessage
+	if err := json.NewDecoder(fakeClient.SpyRequest.Body).Decode(&body); err != nil {
+		t.Fatalf("failed to decode request body: %v", err)
+	}
+	if _, ok := body["BusinessInitiative"]; !ok {
+		t.Errorf("expected a BusinessInitiative envelope, got %v", body)
+	}
+	if got := fakeClient.SpyRequest.URL.Path; got != "/portfolioitem/businessinitiative/create" {
+		t.Errorf("expected path /portfolioitem/businessinitiative/create, got %s", got)
+	}
+}
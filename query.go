@@ -0,0 +1,342 @@
+/**
+* Copyright 2014 Comcast Cable Communications Management, LLC
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+* http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package rallyresttoolkit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/aleksofficial/go-rally-rest-toolkit/models"
+)
+
+// MaxPageSize is the largest page Rally will return for a single query.
+const MaxPageSize = 2000
+
+// ErrInvalidPageSize is returned when QueryOptions.PageSize is negative or
+// larger than MaxPageSize, or a default page size is outside 1 to MaxPageSize.
+var ErrInvalidPageSize = errors.New("invalid page size")
+
+// ErrReservedParam is returned when QueryOptions.Extra sets a parameter that
+// QueryOptions controls itself, such as query or pagesize.
+var ErrReservedParam = errors.New("reserved query parameter")
+
+// ErrInvalidFetch is returned when a FetchMode and the fields passed with it
+// do not agree, e.g. FetchFields with no fields.
+var ErrInvalidFetch = errors.New("invalid fetch")
+
+// FetchMode controls how much of each object a query or get returns.
+type FetchMode int
+
+const (
+	// FetchFull returns every field (fetch=true), or only the Fetch fields
+	// when some are listed. It is the default.
+	FetchFull FetchMode = iota
+	// FetchFields returns only the listed Fetch fields, which must not be
+	// empty.
+	FetchFields
+	// FetchNone sends no fetch parameter, so Rally returns shallow ref
+	// objects with _ref, _refObjectName and _type; the fastest option for
+	// ID-only scans.
+	FetchNone
+)
+
+// fetchParam returns the fetch parameter for mode and fields, and false when
+// none should be sent.
+func fetchParam(mode FetchMode, fields []string) (string, bool, error) {
+	switch mode {
+	case FetchNone:
+		if len(fields) > 0 {
+			return "", false, fmt.Errorf("%w: FetchNone with fields %v", ErrInvalidFetch, fields)
+		}
+		return "", false, nil
+	case FetchFields:
+		if len(fields) == 0 {
+			return "", false, fmt.Errorf("%w: FetchFields without fields", ErrInvalidFetch)
+		}
+		return strings.Join(fields, ","), true, nil
+	case FetchFull:
+		if len(fields) > 0 {
+			return strings.Join(fields, ","), true, nil
+		}
+		return "true", true, nil
+	default:
+		return "", false, fmt.Errorf("%w: unknown FetchMode %d", ErrInvalidFetch, mode)
+	}
+}
+
+// reservedParams are the lower-cased parameters QueryOptions and its callers
+// set, which Extra may not override.
+var reservedParams = map[string]bool{
+	"query":              true,
+	"fetch":              true,
+	"start":              true,
+	"pagesize":           true,
+	"order":              true,
+	"types":              true,
+	"project":            true,
+	"workspace":          true,
+	"projectscopeup":     true,
+	"projectscopedown":   true,
+	"includepermissions": true,
+	"search":             true,
+}
+
+// QueryOptions holds optional parameters for QueryRequestWithOptions.
+type QueryOptions struct {
+	// Order is the raw order clause, e.g. "DragAndDropRank" to return results
+	// in backlog order or "Priority desc,CreationDate".
+	Order string
+	// OrderBy is a structured alternative to Order for one or more sort keys,
+	// e.g. OrderBy{}.Desc("Priority").Asc("CreationDate"). Set one or the
+	// other, not both.
+	OrderBy OrderBy
+	// Start is the 1-based index of the first result to return (optional,
+	// defaults to 1)
+	Start int
+	// PageSize is the number of results per page, up to 2000 (optional,
+	// defaults to Rally's page size of 20)
+	PageSize int
+	// Workspace is the ref of the workspace to query within, e.g.
+	// "/workspace/12345" (optional, defaults to Config.DefaultWorkspace, then
+	// the user's default)
+	Workspace string
+	// Project is the ref of the project to query within, e.g. "/project/12345"
+	// (optional, defaults to Config.DefaultProject, then the user's default)
+	Project string
+	// ProjectScopeUp includes parent projects of Project when true (optional;
+	// nil leaves Rally's default). Set it with models.Bool.
+	ProjectScopeUp *bool
+	// ProjectScopeDown includes child projects of Project when true (optional;
+	// nil leaves Rally's default). Set it with models.Bool.
+	ProjectScopeDown *bool
+	// Fetch lists the fields to return for each result (optional, defaults to
+	// all fields)
+	Fetch []string
+	// FetchMode selects between all fields, only the Fetch fields and shallow
+	// refs (optional, defaults to FetchFull)
+	FetchMode FetchMode
+	// Types limits a query against an abstract type such as "artifact" to
+	// these concrete types, e.g. {"defect", "hierarchicalrequirement"}
+	// (optional)
+	Types []string
+	// IncludePermissions asks Rally to return the user's permissions on each
+	// result (optional)
+	IncludePermissions bool
+	// Extra holds further WSAPI parameters to send as-is, e.g. toggles this
+	// package has no option for yet. Setting a parameter that another option
+	// controls, such as query or pagesize, fails with ErrReservedParam.
+	Extra map[string]string
+	// KeepDuplicates turns off the ObjectID de-duplication done while paging
+	// with QueryAllWithOptions or QueryStream, delivering a result again when
+	// a later page repeats it (optional)
+	KeepDuplicates bool
+}
+
+// validate checks the options before a request is built.
+func (o QueryOptions) validate() error {
+	if o.PageSize < 0 || o.PageSize > MaxPageSize {
+		return fmt.Errorf("%w: %d (must be between 0 and %d)", ErrInvalidPageSize, o.PageSize, MaxPageSize)
+	}
+	if !o.OrderBy.IsZero() {
+		if o.Order != "" {
+			return fmt.Errorf("%w: set Order or OrderBy, not both", ErrInvalidOrder)
+		}
+		if err := o.OrderBy.Validate(); err != nil {
+			return err
+		}
+	}
+	if _, _, err := fetchParam(o.FetchMode, o.Fetch); err != nil {
+		return err
+	}
+	for name := range o.Extra {
+		if reservedParams[strings.ToLower(name)] {
+			return fmt.Errorf("%w: %s is set by QueryOptions", ErrReservedParam, name)
+		}
+	}
+	return nil
+}
+
+// encode builds the URL parameters for a query.
+func (o QueryOptions) encode(query map[string]string) url.Values {
+	params := url.Values{}
+	if fetch, ok, _ := fetchParam(o.FetchMode, o.Fetch); ok {
+		params.Set("fetch", fetch)
+	}
+	if len(o.Types) > 0 {
+		params.Set("types", strings.Join(o.Types, ","))
+	}
+	if len(query) > 0 {
+		params.Set("query", andQuery(query))
+	}
+	if o.Order != "" {
+		params.Set("order", o.Order)
+	}
+	if !o.OrderBy.IsZero() {
+		params.Set("order", o.OrderBy.String())
+	}
+	if o.Start > 0 {
+		params.Set("start", strconv.Itoa(o.Start))
+	}
+	if o.PageSize > 0 {
+		params.Set("pagesize", strconv.Itoa(o.PageSize))
+	}
+	if o.Workspace != "" {
+		params.Set("workspace", o.Workspace)
+	}
+	if o.Project != "" {
+		params.Set("project", o.Project)
+	}
+	if o.ProjectScopeUp != nil {
+		params.Set("projectScopeUp", strconv.FormatBool(*o.ProjectScopeUp))
+	}
+	if o.ProjectScopeDown != nil {
+		params.Set("projectScopeDown", strconv.FormatBool(*o.ProjectScopeDown))
+	}
+	if o.IncludePermissions {
+		params.Set("includePermissions", "true")
+	}
+	for name, value := range o.Extra {
+		params.Set(name, value)
+	}
+	return params
+}
+
+// andQuery builds a single query expression matching every condition in
+// query. Rally only honors one query parameter and its AND is binary, so three
+// or more conditions are nested: ((( A = 1 ) AND ( B = 2 )) AND ( C = 3 )).
+// Fields are sorted to keep the expression stable.
+func andQuery(query map[string]string) string {
+	fields := make([]string, 0, len(query))
+	for field := range query {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	var expr Expr
+	for _, field := range fields {
+		expr = expr.And(Expr{text: fmt.Sprintf("( %s = %s )", field, quoteQueryValue(query[field]))})
+	}
+	return expr.String()
+}
+
+// queryValueSpecials are the characters that make Rally's query parser
+// misread an unquoted value. "%" is included so a value that looks
+// percent-encoded, e.g. "100%25", is sent as literal text.
+const queryValueSpecials = " \t\r\n()\"'\\/:,=<>!~&|%"
+
+// Null is the query value that matches a field with no value, e.g.
+// map[string]string{"Owner": Null} queries ( Owner = null ) to find unowned
+// artifacts. It is sent unquoted, whereas an empty string is sent as "" and
+// only matches fields set to the empty string. To match the literal string
+// "null", pass it already quoted. Query expressions use IsNull and IsNotNull.
+const Null = "null"
+
+// quoteQueryValue wraps a query value in double quotes, escaping embedded
+// quotes and backslashes, when it contains whitespace or characters Rally's
+// query parser treats specially, and quotes the empty string. Plain values
+// such as "Open", "US123", Null or relative date tokens such as "today-7",
+// short refs such as "/iteration/12345", and values the caller has already
+// quoted, are passed through unchanged.
+func quoteQueryValue(val string) string {
+	if val == "" {
+		return `""`
+	}
+	if isQuotedQueryValue(val) || isShortRef(val) {
+		return val
+	}
+	if !strings.ContainsAny(val, queryValueSpecials) {
+		return val
+	}
+	escaped := strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(val)
+	return `"` + escaped + `"`
+}
+
+// isShortRef reports whether val is a "/type/id" ref with a numeric ObjectID,
+// which Rally expects unquoted.
+func isShortRef(val string) bool {
+	if !strings.HasPrefix(val, "/") || strings.ContainsAny(val, " \t\r\n()\"'\\:,=<>!~&|.") {
+		return false
+	}
+	_, objectID, err := parseRef(val)
+	return err == nil && strings.Trim(objectID, "0123456789") == ""
+}
+
+// isQuotedQueryValue reports whether val is a single double-quoted string whose
+// embedded quotes are all escaped, e.g. `"say \"hi\""` but not `"a" OR "b"`.
+func isQuotedQueryValue(val string) bool {
+	if len(val) < 2 || val[0] != '"' || val[len(val)-1] != '"' {
+		return false
+	}
+	escaped := false
+	for _, c := range val[1 : len(val)-1] {
+		switch {
+		case escaped:
+			escaped = false
+		case c == '\\':
+			escaped = true
+		case c == '"':
+			return false
+		}
+	}
+	return !escaped
+}
+
+// Page is one page of query results. It embeds the full QueryResult envelope,
+// so paging metadata, Errors and Warnings are all available.
+type Page[T any] struct {
+	models.QueryResult[T]
+}
+
+// HasMore reports whether there are results beyond this page.
+func (p Page[T]) HasMore() bool {
+	return len(p.Results) > 0 && p.NextStart() <= p.TotalResultCount
+}
+
+// Meta returns the page's envelope metadata without its results.
+func (p Page[T]) Meta() QueryMeta {
+	return QueryMeta{
+		TotalResultCount: p.TotalResultCount,
+		StartIndex:       p.StartIndex,
+		PageSize:         p.PageSize,
+		Errors:           p.Errors,
+		Warnings:         p.Warnings,
+	}
+}
+
+// NextStart returns the Start to request the page after this one.
+func (p Page[T]) NextStart() int {
+	return p.StartIndex + len(p.Results)
+}
+
+// queryPage runs a query and decodes one page of results of type T.
+func queryPage[T any](ctx context.Context, client *RallyClient, query map[string]string, queryType string, opts QueryOptions) (Page[T], error) {
+	var response models.QueryResponse[T]
+	err := client.QueryRequestWithOptions(ctx, query, queryType, opts, &response)
+	return Page[T]{response.QueryResult}, err
+}
+
+// queryRawPage runs a raw query and decodes one page of results of type T.
+func queryRawPage[T any](ctx context.Context, client *RallyClient, rawQuery string, queryType string, opts QueryOptions) (Page[T], error) {
+	var response models.QueryResponse[T]
+	err := client.QueryRequestRaw(ctx, rawQuery, queryType, opts, &response)
+	return Page[T]{response.QueryResult}, err
+}
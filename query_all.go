@@ -0,0 +1,302 @@
+/**
+* Copyright 2014 Comcast Cable Communications Management, LLC
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+* http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package rallyresttoolkit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+)
+
+// QueryAll - runs a query page by page, following StartIndex, PageSize and
+// TotalResultCount, and passes every result to callback in order. pageSize
+// may be 0 for Config.DefaultPageSize, or else Rally's default. It returns how
+// many results were delivered; if a page fails, the context is cancelled or
+// callback returns an error, the error is a *PaginationError recording where
+// paging stopped.
+//
+// Rally pages are not snapshots, so an object created or re-ranked while
+// paging can appear on two pages. Results whose ObjectID was already delivered
+// are dropped, unless QueryAllWithOptions sets QueryOptions.KeepDuplicates,
+// and unless the query sets an order, pages are ordered by ObjectID to keep
+// such shifts rare.
+func (s *RallyClient) QueryAll(ctx context.Context, query map[string]string, queryType string, pageSize int, callback func(json.RawMessage) error) (int, error) {
+	delivered, _, err := queryAll[json.RawMessage](ctx, s, query, queryType, QueryOptions{PageSize: pageSize}, callback)
+	return delivered, err
+}
+
+// QueryAllWithOptions - like QueryAll, but with the paging, ordering and
+// fetch settings of opts. Paging begins at opts.Start, e.g. the Start of a
+// *PaginationError to resume an earlier run.
+func (s *RallyClient) QueryAllWithOptions(ctx context.Context, query map[string]string, queryType string, opts QueryOptions, callback func(json.RawMessage) error) (int, error) {
+	delivered, _, err := queryAll[json.RawMessage](ctx, s, query, queryType, opts, callback)
+	return delivered, err
+}
+
+// QueryAllResults - like QueryAll, but accumulates every result into a slice
+// of typed models, e.g. QueryAllResults[models.Defect](ctx, client, query,
+// "defect", 200). On error the results delivered so far are returned along
+// with a *PaginationError.
+func QueryAllResults[T any](ctx context.Context, client *RallyClient, query map[string]string, queryType string, pageSize int) ([]T, error) {
+	results, _, err := QueryAllResultsWithMeta[T](ctx, client, query, queryType, pageSize)
+	return results, err
+}
+
+// QueryAllResultsWithMeta - like QueryAllResults, but also returns the
+// metadata of the last page, with Duplicates counting the results dropped
+// because an earlier page had already returned them. Callers that need an
+// exact snapshot can re-run the query when it is not zero.
+func QueryAllResultsWithMeta[T any](ctx context.Context, client *RallyClient, query map[string]string, queryType string, pageSize int) ([]T, QueryMeta, error) {
+	var results []T
+	_, meta, err := queryAll[T](ctx, client, query, queryType, QueryOptions{PageSize: pageSize}, func(result T) error {
+		results = append(results, result)
+		return nil
+	})
+	return results, meta, err
+}
+
+// QueryAllConcurrent - like QueryAll, but once the first page has reported
+// TotalResultCount the remaining pages are requested up to parallelism at a
+// time. Results are still passed to callback in order, and at most
+// parallelism pages are held in memory waiting for it. A failed page cancels
+// the requests still in flight. pageSize may be 0 for Config.DefaultPageSize,
+// or else Rally's default of 20.
+func (s *RallyClient) QueryAllConcurrent(ctx context.Context, query map[string]string, queryType string, pageSize int, parallelism int, callback func(json.RawMessage) error) (int, error) {
+	return queryAllConcurrent[json.RawMessage](ctx, s, query, queryType, pageSize, parallelism, callback)
+}
+
+// defaultPageSize is the page size Rally uses when a query does not set one.
+const defaultPageSize = 20
+
+// stableOrder orders by ObjectID when opts sets no order, so objects created
+// while paging land on the last page instead of shifting earlier ones.
+func stableOrder(opts QueryOptions) QueryOptions {
+	if opts.Order == "" && opts.OrderBy.IsZero() {
+		opts.Order = "ObjectID"
+	}
+	return opts
+}
+
+// dedupe tracks the ObjectIDs delivered while paging.
+type dedupe struct {
+	seen       map[int64]bool
+	duplicates int
+	keep       bool
+}
+
+func newDedupe() *dedupe {
+	return &dedupe{seen: make(map[int64]bool)}
+}
+
+// decodeUnique decodes a page of raw results into T with the client's
+// decoder, dropping those whose ObjectID was already seen. Results without an
+// ObjectID are always kept, as is every result when d.keep is set.
+func decodeUnique[T any](client *RallyClient, d *dedupe, raws []json.RawMessage) ([]T, error) {
+	results := make([]T, 0, len(raws))
+	for _, raw := range raws {
+		var id struct{ ObjectID int64 }
+		if !d.keep && json.Unmarshal(raw, &id) == nil && id.ObjectID != 0 {
+			if d.seen[id.ObjectID] {
+				d.duplicates++
+				continue
+			}
+			d.seen[id.ObjectID] = true
+		}
+		var result T
+		if err := client.decode(raw, &result); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal result: %w", err)
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// fetchedPage is the outcome of one page request made by queryAllConcurrent.
+type fetchedPage struct {
+	results []json.RawMessage
+	err     error
+}
+
+func queryAllConcurrent[T any](ctx context.Context, client *RallyClient, query map[string]string, queryType string, pageSize int, parallelism int, callback func(T) error) (int, error) {
+	if pageSize <= 0 {
+		pageSize = client.pageSize(defaultPageSize)
+	}
+	if parallelism < 1 {
+		parallelism = 1
+	}
+	opts := stableOrder(QueryOptions{Start: 1, PageSize: pageSize})
+	seen := newDedupe()
+	delivered := 0
+
+	// deliver passes one page of results to callback, skipping duplicates.
+	deliver := func(start int, raws []json.RawMessage) error {
+		results, err := decodeUnique[T](client, seen, raws)
+		if err != nil {
+			return &PaginationError{Start: start, Delivered: delivered, Duplicates: seen.duplicates, Err: err}
+		}
+		for _, result := range results {
+			if err := callback(result); err != nil {
+				return &PaginationError{Start: start, Delivered: delivered, Duplicates: seen.duplicates, Err: err}
+			}
+			delivered++
+		}
+		return nil
+	}
+
+	first, err := queryPage[json.RawMessage](ctx, client, query, queryType, opts)
+	if err != nil {
+		return 0, &PaginationError{Start: 1, Err: err}
+	}
+	if err := deliver(1, first.Results); err != nil {
+		return delivered, err
+	}
+
+	var starts []int
+	for start := 1 + pageSize; start <= first.TotalResultCount; start += pageSize {
+		starts = append(starts, start)
+	}
+	if len(starts) == 0 {
+		return delivered, nil
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	defer cancel()
+
+	// A slot is taken before a page is requested and given back once its
+	// results have been delivered, bounding both requests and buffered pages.
+	slots := make(chan struct{}, parallelism)
+	pages := make([]chan fetchedPage, len(starts))
+	for i := range pages {
+		pages[i] = make(chan fetchedPage, 1)
+	}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i, start := range starts {
+			select {
+			case slots <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+			wg.Add(1)
+			go func(i int, opts QueryOptions) {
+				defer wg.Done()
+				page, err := queryPage[json.RawMessage](ctx, client, query, queryType, opts)
+				pages[i] <- fetchedPage{results: page.Results, err: err}
+			}(i, QueryOptions{Start: start, PageSize: pageSize, Order: opts.Order})
+		}
+	}()
+
+	for i, start := range starts {
+		var page fetchedPage
+		select {
+		case page = <-pages[i]:
+		case <-ctx.Done():
+			return delivered, &PaginationError{Start: start, Delivered: delivered, Duplicates: seen.duplicates, Err: ctx.Err()}
+		}
+		if page.err != nil {
+			return delivered, &PaginationError{Start: start, Delivered: delivered, Duplicates: seen.duplicates, Err: page.err}
+		}
+		if err := deliver(start, page.results); err != nil {
+			return delivered, err
+		}
+		<-slots
+	}
+	return delivered, nil
+}
+
+// queryAll drives the paging loop shared by QueryAll, QueryAllResults,
+// QueryStream and the iterators, starting at opts.Start or 1. It returns how
+// many results were delivered and the last page's metadata, with Duplicates
+// set.
+func queryAll[T any](ctx context.Context, client *RallyClient, query map[string]string, queryType string, opts QueryOptions, callback func(T) error) (int, QueryMeta, error) {
+	fetch := func(opts QueryOptions) (Page[json.RawMessage], error) {
+		return queryPage[json.RawMessage](ctx, client, query, queryType, opts)
+	}
+	return queryPages(ctx, client, fetch, opts, callback)
+}
+
+// queryAllExprResults pages through a query expression and returns every
+// result of type T.
+func queryAllExprResults[T any](ctx context.Context, client *RallyClient, expr Expr, queryType string) ([]T, error) {
+	if err := expr.Validate(); err != nil {
+		return nil, err
+	}
+	fetch := func(opts QueryOptions) (Page[json.RawMessage], error) {
+		return queryRawPage[json.RawMessage](ctx, client, expr.String(), queryType, opts)
+	}
+	var results []T
+	_, _, err := queryPages(ctx, client, fetch, QueryOptions{}, func(result T) error {
+		results = append(results, result)
+		return nil
+	})
+	return results, err
+}
+
+// queryPages is the paging loop behind queryAll, requesting each page with
+// fetch.
+func queryPages[T any](ctx context.Context, client *RallyClient, fetch func(QueryOptions) (Page[json.RawMessage], error), opts QueryOptions, callback func(T) error) (int, QueryMeta, error) {
+	if opts.Start < 1 {
+		opts.Start = 1
+	}
+	opts = stableOrder(opts)
+	seen := newDedupe()
+	seen.keep = opts.KeepDuplicates
+	delivered := 0
+	var meta QueryMeta
+
+	fail := func(err error) (int, QueryMeta, error) {
+		meta.Duplicates = seen.duplicates
+		return delivered, meta, &PaginationError{Start: opts.Start, Delivered: delivered, Duplicates: seen.duplicates, Err: err}
+	}
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return fail(err)
+		}
+
+		page, err := fetch(opts)
+		if err != nil {
+			return fail(err)
+		}
+		if page.StartIndex == 0 {
+			page.StartIndex = opts.Start
+		}
+		meta = page.Meta()
+
+		results, err := decodeUnique[T](client, seen, page.Results)
+		if err != nil {
+			return fail(err)
+		}
+		for _, result := range results {
+			if err := callback(result); err != nil {
+				return fail(err)
+			}
+			delivered++
+		}
+
+		if !page.HasMore() {
+			meta.Duplicates = seen.duplicates
+			return delivered, meta, nil
+		}
+		opts.Start = page.NextStart()
+	}
+}
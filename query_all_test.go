@@ -0,0 +1,455 @@
+/**
+* Copyright 2014 Comcast Cable Communications Management, LLC
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+* http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package rallyresttoolkit_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/aleksofficial/go-rally-rest-toolkit"
+	"github.com/aleksofficial/go-rally-rest-toolkit/fakes"
+	"github.com/aleksofficial/go-rally-rest-toolkit/models"
+)
+
+// queryPageResponse builds a fake QueryResult page of count defects whose
+// ObjectIDs start at startIndex.
+func queryPageResponse(startIndex, count, total int) *http.Response {
+	results := make([]string, count)
+	for i := range results {
+		results[i] = fmt.Sprintf(`{"ObjectID": %d}`, startIndex+i)
+	}
+	body := fmt.Sprintf(`{"QueryResult": {"TotalResultCount": %d, "StartIndex": %d, "PageSize": 20, "Results": [%s]}}`,
+		total, startIndex, strings.Join(results, ","))
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Body:       &fakes.FakeResponseBody{Reader: bytes.NewBufferString(body)},
+	}
+}
+
+func TestQueryAll_ThreePages(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{
+		FakeResponses: []*http.Response{
+			queryPageResponse(1, 20, 45),
+			queryPageResponse(21, 20, 45),
+			queryPageResponse(41, 5, 45),
+		},
+	}
+	rallyClient := New("abcdef", "http://myRallyUrl", fakeClient)
+
+	var ids []int
+	delivered, err := rallyClient.QueryAll(context.Background(), map[string]string{"State": "Open"}, "defect", 20, func(raw json.RawMessage) error {
+		var defect models.Defect
+		if err := json.Unmarshal(raw, &defect); err != nil {
+			return err
+		}
+		ids = append(ids, defect.ObjectID)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("QueryAll failed unexpectedly: %v", err)
+	}
+	if delivered != 45 || len(ids) != 45 {
+		t.Errorf("expected 45 results, got delivered=%d len=%d", delivered, len(ids))
+	}
+	for i, id := range ids {
+		if id != i+1 {
+			t.Fatalf("expected ObjectID %d at position %d, got %d", i+1, i, id)
+		}
+	}
+	if fakeClient.CallCount != 3 {
+		t.Errorf("expected 3 HTTP calls, got %d", fakeClient.CallCount)
+	}
+	if got := fakeClient.SpyRequest.URL.Query().Get("start"); got != "41" {
+		t.Errorf("expected last request with start=41, got %q", got)
+	}
+}
+
+func TestQueryAllResults_Typed(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{
+		FakeResponses: []*http.Response{
+			queryPageResponse(1, 20, 45),
+			queryPageResponse(21, 20, 45),
+			queryPageResponse(41, 5, 45),
+		},
+	}
+	rallyClient := New("abcdef", "http://myRallyUrl", fakeClient)
+
+	defects, err := QueryAllResults[models.Defect](context.Background(), rallyClient, map[string]string{}, "defect", 20)
+	if err != nil {
+		t.Fatalf("QueryAllResults failed unexpectedly: %v", err)
+	}
+	if len(defects) != 45 || defects[44].ObjectID != 45 {
+		t.Errorf("expected 45 defects ending with ObjectID 45, got %d", len(defects))
+	}
+}
+
+func TestQueryAll_PartialFailure(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{
+		FakeResponses: []*http.Response{
+			queryPageResponse(1, 20, 45),
+			{
+				StatusCode: http.StatusBadRequest,
+				Body:       &fakes.FakeResponseBody{Reader: bytes.NewBufferString(`{"QueryResult": {"Errors": ["Invalid start index"]}}`)},
+			},
+		},
+	}
+	rallyClient := New("abcdef", "http://myRallyUrl", fakeClient)
+
+	defects, err := QueryAllResults[models.Defect](context.Background(), rallyClient, map[string]string{}, "defect", 20)
+
+	var pageErr *PaginationError
+	if !errors.As(err, &pageErr) {
+		t.Fatalf("expected *PaginationError, got %v", err)
+	}
+	if pageErr.Delivered != 20 || pageErr.Start != 21 {
+		t.Errorf("expected Delivered=20 Start=21, got Delivered=%d Start=%d", pageErr.Delivered, pageErr.Start)
+	}
+	if !errors.Is(err, ErrRallyAPI) {
+		t.Errorf("expected the underlying RallyAPIError to be unwrappable, got %v", err)
+	}
+	if len(defects) != 20 {
+		t.Errorf("expected the 20 delivered defects to be returned, got %d", len(defects))
+	}
+}
+
+func TestQueryAll_StopsWhenContextCancelled(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{
+		FakeResponses: []*http.Response{
+			queryPageResponse(1, 20, 45),
+			queryPageResponse(21, 20, 45),
+		},
+	}
+	rallyClient := New("abcdef", "http://myRallyUrl", fakeClient)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	delivered, err := rallyClient.QueryAll(ctx, map[string]string{}, "defect", 20, func(json.RawMessage) error {
+		cancel()
+		return nil
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if delivered != 20 {
+		t.Errorf("expected 20 delivered, got %d", delivered)
+	}
+	if fakeClient.CallCount != 1 {
+		t.Errorf("expected 1 HTTP call, got %d", fakeClient.CallCount)
+	}
+}
+
+// pageServer answers query pages concurrently from a result set of total
+// defects, failing the page that starts at failStart with a 400, and records
+// the start parameter of every request.
+type pageServer struct {
+	total     int
+	failStart int
+
+	mu     sync.Mutex
+	starts []int
+}
+
+func (s *pageServer) Do(req *http.Request) (*http.Response, error) {
+	params := req.URL.Query()
+	start, _ := strconv.Atoi(params.Get("start"))
+	pageSize, _ := strconv.Atoi(params.Get("pagesize"))
+
+	s.mu.Lock()
+	s.starts = append(s.starts, start)
+	s.mu.Unlock()
+
+	if start == s.failStart {
+		return &http.Response{
+			StatusCode: http.StatusBadRequest,
+			Body:       io.NopCloser(bytes.NewBufferString(`{"QueryResult": {"Errors": ["bad page"]}}`)),
+		}, nil
+	}
+	// Later pages answer first, so in-order delivery has to wait for them.
+	time.Sleep(time.Duration(s.total-start) * 50 * time.Microsecond)
+
+	count := min(pageSize, s.total-start+1)
+	return queryPageResponse(start, count, s.total), nil
+}
+
+func TestQueryAllConcurrent_InOrder(t *testing.T) {
+	server := &pageServer{total: 95}
+	rallyClient := New("abcdef", "http://myRallyUrl", server)
+
+	var ids []int
+	delivered, err := rallyClient.QueryAllConcurrent(context.Background(), nil, "defect", 10, 4, func(raw json.RawMessage) error {
+		var defect models.Defect
+		if err := json.Unmarshal(raw, &defect); err != nil {
+			return err
+		}
+		ids = append(ids, defect.ObjectID)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("QueryAllConcurrent failed unexpectedly: %v", err)
+	}
+	if delivered != 95 || len(ids) != 95 {
+		t.Fatalf("expected 95 results, got delivered=%d len=%d", delivered, len(ids))
+	}
+	for i, id := range ids {
+		if id != i+1 {
+			t.Fatalf("expected ObjectID %d at position %d, got %d", i+1, i, id)
+		}
+	}
+
+	sort.Ints(server.starts)
+	expected := []int{1, 11, 21, 31, 41, 51, 61, 71, 81, 91}
+	if !reflect.DeepEqual(server.starts, expected) {
+		t.Errorf("expected starts %v, got %v", expected, server.starts)
+	}
+}
+
+func TestQueryAllConcurrent_FailingPage(t *testing.T) {
+	server := &pageServer{total: 95, failStart: 41}
+	rallyClient := New("abcdef", "http://myRallyUrl", server)
+
+	delivered, err := rallyClient.QueryAllConcurrent(context.Background(), nil, "defect", 10, 3, func(raw json.RawMessage) error {
+		return nil
+	})
+	var pageErr *PaginationError
+	if !errors.As(err, &pageErr) {
+		t.Fatalf("expected *PaginationError, got %v", err)
+	}
+	if pageErr.Start != 41 || delivered != 40 || pageErr.Delivered != 40 {
+		t.Errorf("expected failure at start 41 after 40 results, got start=%d delivered=%d", pageErr.Start, delivered)
+	}
+	if !errors.Is(err, ErrRallyAPI) {
+		t.Errorf("expected the page's Rally error to be wrapped, got %v", err)
+	}
+	// With three pages in flight at most, paging cannot have reached the end.
+	for _, start := range server.starts {
+		if start > 71 {
+			t.Errorf("expected no request past start 71 after the failure, got %d", start)
+		}
+	}
+}
+
+// overlapServer answers each page by its start parameter with the listed
+// ObjectIDs, modelling pages that shifted between requests, and records the
+// order parameter of every request.
+type overlapServer struct {
+	total int
+	pages map[int][]int
+
+	mu     sync.Mutex
+	orders []string
+}
+
+func (s *overlapServer) Do(req *http.Request) (*http.Response, error) {
+	params := req.URL.Query()
+	start, _ := strconv.Atoi(params.Get("start"))
+
+	s.mu.Lock()
+	s.orders = append(s.orders, params.Get("order"))
+	s.mu.Unlock()
+
+	results := make([]string, len(s.pages[start]))
+	for i, id := range s.pages[start] {
+		results[i] = fmt.Sprintf(`{"ObjectID": %d}`, id)
+	}
+	body := fmt.Sprintf(`{"QueryResult": {"TotalResultCount": %d, "StartIndex": %d, "PageSize": 4, "Results": [%s]}}`,
+		s.total, start, strings.Join(results, ","))
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Body:       io.NopCloser(bytes.NewBufferString(body)),
+	}, nil
+}
+
+// shiftedPages has object 4 and 7 pushed onto the next page by inserts made
+// while paging.
+func shiftedPages() *overlapServer {
+	return &overlapServer{total: 10, pages: map[int][]int{
+		1: {1, 2, 3, 4},
+		5: {4, 5, 6, 7},
+		9: {7, 8},
+	}}
+}
+
+func TestQueryAllResultsWithMeta_DropsDuplicates(t *testing.T) {
+	server := shiftedPages()
+	rallyClient := New("abcdef", "http://myRallyUrl", server)
+
+	defects, meta, err := QueryAllResultsWithMeta[models.Defect](context.Background(), rallyClient, nil, "defect", 4)
+	if err != nil {
+		t.Fatalf("QueryAllResultsWithMeta failed unexpectedly: %v", err)
+	}
+	var ids []int
+	for _, defect := range defects {
+		ids = append(ids, defect.ObjectID)
+	}
+	if expected := []int{1, 2, 3, 4, 5, 6, 7, 8}; !reflect.DeepEqual(ids, expected) {
+		t.Errorf("expected %v, got %v", expected, ids)
+	}
+	if meta.Duplicates != 2 || meta.TotalResultCount != 10 {
+		t.Errorf("expected 2 duplicates of 10 results, got %+v", meta)
+	}
+	if expected := []string{"ObjectID", "ObjectID", "ObjectID"}; !reflect.DeepEqual(server.orders, expected) {
+		t.Errorf("expected pages ordered by ObjectID, got %v", server.orders)
+	}
+}
+
+func TestQueryAllWithOptions_KeepDuplicates(t *testing.T) {
+	rallyClient := New("abcdef", "http://myRallyUrl", shiftedPages())
+
+	var ids []int
+	delivered, err := rallyClient.QueryAllWithOptions(context.Background(), nil, "defect", QueryOptions{PageSize: 4, KeepDuplicates: true}, func(raw json.RawMessage) error {
+		var defect models.Defect
+		if err := json.Unmarshal(raw, &defect); err != nil {
+			return err
+		}
+		ids = append(ids, defect.ObjectID)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("QueryAllWithOptions failed unexpectedly: %v", err)
+	}
+	if expected := []int{1, 2, 3, 4, 4, 5, 6, 7, 7, 8}; delivered != 10 || !reflect.DeepEqual(ids, expected) {
+		t.Errorf("expected %v, got delivered=%d %v", expected, delivered, ids)
+	}
+}
+
+func TestQueryStream_DropsDuplicatesAndKeepsOrder(t *testing.T) {
+	server := shiftedPages()
+	rallyClient := New("abcdef", "http://myRallyUrl", server)
+
+	results, errs := QueryStreamResults[models.Defect](context.Background(), rallyClient, nil, "defect", QueryOptions{PageSize: 4, Order: "DragAndDropRank"})
+	var ids []int
+	for defect := range results {
+		ids = append(ids, defect.ObjectID)
+	}
+	if err, ok := <-errs; ok {
+		t.Fatalf("QueryStreamResults failed unexpectedly: %v", err)
+	}
+	if expected := []int{1, 2, 3, 4, 5, 6, 7, 8}; !reflect.DeepEqual(ids, expected) {
+		t.Errorf("expected %v, got %v", expected, ids)
+	}
+	for _, order := range server.orders {
+		if order != "DragAndDropRank" {
+			t.Errorf("expected the caller's order to be kept, got %q", order)
+		}
+	}
+}
+
+func TestQueryAllConcurrent_DropsDuplicates(t *testing.T) {
+	server := shiftedPages()
+	rallyClient := New("abcdef", "http://myRallyUrl", server)
+
+	var ids []int
+	delivered, err := rallyClient.QueryAllConcurrent(context.Background(), nil, "defect", 4, 2, func(raw json.RawMessage) error {
+		var defect models.Defect
+		if err := json.Unmarshal(raw, &defect); err != nil {
+			return err
+		}
+		ids = append(ids, defect.ObjectID)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("QueryAllConcurrent failed unexpectedly: %v", err)
+	}
+	if expected := []int{1, 2, 3, 4, 5, 6, 7, 8}; delivered != 8 || !reflect.DeepEqual(ids, expected) {
+		t.Errorf("expected %v, got delivered=%d %v", expected, delivered, ids)
+	}
+}
+
+func TestQueryAll_RetriesOnlyTheFailedPage(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{
+		FakeResponses: []*http.Response{
+			queryPageResponse(1, 20, 45),
+			errorResponse(http.StatusInternalServerError),
+			queryPageResponse(21, 20, 45),
+			queryPageResponse(41, 5, 45),
+		},
+	}
+	clock := &fakes.FakeClock{}
+	rallyClient, err := NewClient(WithHTTPClient(fakeClient), WithRetries(2, time.Second), WithJitter(JitterNone), WithClock(clock))
+	if err != nil {
+		t.Fatalf("NewClient failed unexpectedly: %v", err)
+	}
+
+	defects, err := QueryAllResults[models.Defect](context.Background(), rallyClient, nil, "defect", 20)
+	if err != nil {
+		t.Fatalf("QueryAllResults failed unexpectedly: %v", err)
+	}
+	if len(defects) != 45 || defects[44].ObjectID != 45 {
+		t.Errorf("expected 45 defects ending with ObjectID 45, got %d", len(defects))
+	}
+	if fakeClient.CallCount != 4 {
+		t.Errorf("expected 3 pages plus 1 retry, got %d calls", fakeClient.CallCount)
+	}
+	if expected := []time.Duration{time.Second}; !reflect.DeepEqual(clock.Sleeps, expected) {
+		t.Errorf("expected a single retry wait, got %v", clock.Sleeps)
+	}
+}
+
+func TestQueryAllWithOptions_ResumesFromFailedPage(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{
+		FakeResponses: []*http.Response{
+			queryPageResponse(1, 20, 45),
+			errorResponse(http.StatusInternalServerError),
+			queryPageResponse(21, 20, 45),
+			queryPageResponse(41, 5, 45),
+		},
+	}
+	rallyClient, err := NewClient(WithHTTPClient(fakeClient), WithRetries(0, time.Second))
+	if err != nil {
+		t.Fatalf("NewClient failed unexpectedly: %v", err)
+	}
+
+	var ids []int
+	collect := func(raw json.RawMessage) error {
+		var defect models.Defect
+		if err := json.Unmarshal(raw, &defect); err != nil {
+			return err
+		}
+		ids = append(ids, defect.ObjectID)
+		return nil
+	}
+	_, err = rallyClient.QueryAllWithOptions(context.Background(), nil, "defect", QueryOptions{PageSize: 20}, collect)
+	var pageErr *PaginationError
+	if !errors.As(err, &pageErr) || pageErr.Start != 21 {
+		t.Fatalf("expected a *PaginationError at start 21, got %v", err)
+	}
+
+	delivered, err := rallyClient.QueryAllWithOptions(context.Background(), nil, "defect", QueryOptions{PageSize: 20, Start: pageErr.Start}, collect)
+	if err != nil {
+		t.Fatalf("resumed QueryAllWithOptions failed unexpectedly: %v", err)
+	}
+	if delivered != 25 || len(ids) != 45 || ids[44] != 45 {
+		t.Errorf("expected the resumed run to deliver the last 25 of 45, got delivered=%d total=%d", delivered, len(ids))
+	}
+	if got := fakeClient.SpyRequest.URL.Query().Get("start"); got != "41" {
+		t.Errorf("expected last request with start=41, got %q", got)
+	}
+	if fakeClient.CallCount != 4 {
+		t.Errorf("expected no page to be fetched twice, got %d calls", fakeClient.CallCount)
+	}
+}
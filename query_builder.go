@@ -0,0 +1,141 @@
+/**
+* Copyright 2014 Comcast Cable Communications Management, LLC
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+* http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package rallyresttoolkit
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/aleksofficial/go-rally-rest-toolkit/models"
+)
+
+// Operator builds a condition from a field and a value. Eq, Ne, Gt, Ge, Lt, Le
+// and Contains are Operators, so they can be passed to QueryBuilder.Where.
+type Operator func(field string, value string) Expr
+
+// QueryBuilder assembles a query expression together with its order and
+// paging options, e.g.
+//
+//	NewQuery().Where("ScheduleState", Eq, "Accepted").
+//		And("Owner.DisplayName", Contains, "Smith").
+//		OrderBy("Rank", Asc).
+//		PageSize(200)
+//
+// Mistakes are reported by Build rather than by each call.
+type QueryBuilder struct {
+	expr  Expr
+	opts  QueryOptions
+	order OrderBy
+	err   error
+}
+
+// NewQuery returns an empty QueryBuilder, which matches everything.
+func NewQuery() *QueryBuilder {
+	return &QueryBuilder{}
+}
+
+// Where adds a condition. Further conditions are ANDed with it, so Where and
+// And are interchangeable.
+func (b *QueryBuilder) Where(field string, op Operator, value string) *QueryBuilder {
+	return b.And(field, op, value)
+}
+
+// And requires a condition in addition to everything added so far.
+func (b *QueryBuilder) And(field string, op Operator, value string) *QueryBuilder {
+	if cond, ok := b.condition(field, op, value); ok {
+		b.expr = b.expr.And(cond)
+	}
+	return b
+}
+
+// Or accepts a condition as an alternative to everything added so far.
+func (b *QueryBuilder) Or(field string, op Operator, value string) *QueryBuilder {
+	if cond, ok := b.condition(field, op, value); ok {
+		b.expr = b.expr.Or(cond)
+	}
+	return b
+}
+
+// condition applies op, recording the first mistake for Build.
+func (b *QueryBuilder) condition(field string, op Operator, value string) (Expr, bool) {
+	if b.err != nil {
+		return Expr{}, false
+	}
+	if strings.TrimSpace(field) == "" {
+		b.err = fmt.Errorf("%w: empty field name", ErrInvalidQuery)
+		return Expr{}, false
+	}
+	if op == nil {
+		b.err = fmt.Errorf("%w: unsupported operator for field %s", ErrInvalidQuery, field)
+		return Expr{}, false
+	}
+	cond := op(field, value)
+	if err := cond.Validate(); err != nil {
+		b.err = err
+		return Expr{}, false
+	}
+	return cond, true
+}
+
+// OrderBy adds a sort key. Keys apply in the order they are added.
+func (b *QueryBuilder) OrderBy(field string, direction SortDirection) *QueryBuilder {
+	b.order = b.order.By(field, direction)
+	return b
+}
+
+// Start sets the 1-based index of the first result.
+func (b *QueryBuilder) Start(start int) *QueryBuilder {
+	b.opts.Start = start
+	return b
+}
+
+// PageSize sets the number of results per page, up to MaxPageSize.
+func (b *QueryBuilder) PageSize(pageSize int) *QueryBuilder {
+	b.opts.PageSize = pageSize
+	return b
+}
+
+// Build validates the query and returns the expression and the options to
+// pass to QueryRequestExpr.
+func (b *QueryBuilder) Build() (Expr, QueryOptions, error) {
+	if b.err != nil {
+		return Expr{}, QueryOptions{}, b.err
+	}
+	opts := b.opts
+	opts.OrderBy = b.order
+	if err := opts.validate(); err != nil {
+		return Expr{}, QueryOptions{}, err
+	}
+	return b.expr, opts, nil
+}
+
+// QueryRequestWith - QueryRequestExpr for a QueryBuilder.
+func (s *RallyClient) QueryRequestWith(ctx context.Context, b *QueryBuilder, queryType string, output interface{}) error {
+	expr, opts, err := b.Build()
+	if err != nil {
+		return err
+	}
+	return s.QueryRequestExpr(ctx, expr, queryType, opts, output)
+}
+
+// queryWithPage runs a built query and decodes one page of results of type T.
+func queryWithPage[T any](ctx context.Context, client *RallyClient, b *QueryBuilder, queryType string) (Page[T], error) {
+	var response models.QueryResponse[T]
+	err := client.QueryRequestWith(ctx, b, queryType, &response)
+	return Page[T]{response.QueryResult}, err
+}
@@ -0,0 +1,150 @@
+/**
+* Copyright 2014 Comcast Cable Communications Management, LLC
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+* http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package rallyresttoolkit_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	. "github.com/aleksofficial/go-rally-rest-toolkit"
+	"github.com/aleksofficial/go-rally-rest-toolkit/fakes"
+)
+
+func TestQueryBuilder_Rendering(t *testing.T) {
+	tests := []struct {
+		name     string
+		builder  *QueryBuilder
+		query    string
+		order    string
+		start    int
+		pageSize int
+	}{
+		{"empty", NewQuery(), "", "", 0, 0},
+		{"single where", NewQuery().Where("ScheduleState", Eq, "Accepted"), "( ScheduleState = Accepted )", "", 0, 0},
+		{
+			"where and",
+			NewQuery().Where("ScheduleState", Eq, "Accepted").And("Owner.DisplayName", Contains, "Smith"),
+			"(( ScheduleState = Accepted ) AND ( Owner.DisplayName contains Smith ))", "", 0, 0,
+		},
+		{
+			"where or",
+			NewQuery().Where("ScheduleState", Eq, "Accepted").Or("ScheduleState", Eq, "Completed"),
+			"(( ScheduleState = Accepted ) OR ( ScheduleState = Completed ))", "", 0, 0,
+		},
+		{
+			"or then and nests left",
+			NewQuery().Where("Priority", Eq, "High").Or("Priority", Eq, "Urgent").And("State", Ne, "Closed"),
+			"((( Priority = High ) OR ( Priority = Urgent )) AND ( State != Closed ))", "", 0, 0,
+		},
+		{
+			"every operator",
+			NewQuery().Where("A", Eq, "1").And("B", Ne, "2").And("C", Gt, "3").And("D", Ge, "4").And("E", Lt, "5").And("F", Le, "6").And("G", Contains, "seven"),
+			"((((((( A = 1 ) AND ( B != 2 )) AND ( C > 3 )) AND ( D >= 4 )) AND ( E < 5 )) AND ( F <= 6 )) AND ( G contains seven ))", "", 0, 0,
+		},
+		{
+			"quoted value",
+			NewQuery().Where("Iteration.Name", Eq, "Sprint 3"),
+			`( Iteration.Name = "Sprint 3" )`, "", 0, 0,
+		},
+		{
+			"order and paging",
+			NewQuery().Where("ScheduleState", Eq, "Accepted").OrderBy("Rank", Asc).OrderBy("CreationDate", Desc).Start(201).PageSize(200),
+			"( ScheduleState = Accepted )", "Rank ASC,CreationDate DESC", 201, 200,
+		},
+	}
+
+	for _, tt := range tests {
+		expr, opts, err := tt.builder.Build()
+		if err != nil {
+			t.Errorf("%s: Build failed unexpectedly: %v", tt.name, err)
+			continue
+		}
+		if got := expr.String(); got != tt.query {
+			t.Errorf("%s: expected query %s, got %s", tt.name, tt.query, got)
+		}
+		if got := opts.OrderBy.String(); got != tt.order {
+			t.Errorf("%s: expected order %q, got %q", tt.name, tt.order, got)
+		}
+		if opts.Start != tt.start || opts.PageSize != tt.pageSize {
+			t.Errorf("%s: expected start=%d pagesize=%d, got start=%d pagesize=%d", tt.name, tt.start, tt.pageSize, opts.Start, opts.PageSize)
+		}
+	}
+}
+
+func TestQueryBuilder_BuildErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		builder *QueryBuilder
+		target  error
+	}{
+		{"empty field", NewQuery().Where("", Eq, "Accepted"), ErrInvalidQuery},
+		{"blank field after valid condition", NewQuery().Where("State", Eq, "Open").And("  ", Eq, "x"), ErrInvalidQuery},
+		{"nil operator", NewQuery().Where("State", nil, "Open"), ErrInvalidQuery},
+		{"empty order field", NewQuery().Where("State", Eq, "Open").OrderBy("", Asc), ErrInvalidOrder},
+		{"unknown direction", NewQuery().OrderBy("Rank", SortDirection("sideways")), ErrInvalidOrder},
+		{"page size too large", NewQuery().PageSize(MaxPageSize + 1), ErrInvalidPageSize},
+	}
+
+	for _, tt := range tests {
+		if _, _, err := tt.builder.Build(); !errors.Is(err, tt.target) {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.target, err)
+		}
+	}
+}
+
+func TestQueryHierarchicalRequirementWith(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{
+		FakeResponse: &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       &fakes.FakeResponseBody{Reader: bytes.NewBufferString(`{"QueryResult": { "TotalResultCount": 1, "StartIndex": 1, "Results": [{"FormattedID": "US1"}]}}`)},
+		},
+	}
+	hrClient := NewHierarchicalRequirement(New("abcdef", "http://myRallyUrl", fakeClient))
+
+	b := NewQuery().Where("ScheduleState", Eq, "Accepted").And("Owner.DisplayName", Contains, "Smith").OrderBy("Rank", Asc).PageSize(200)
+	page, err := hrClient.QueryHierarchicalRequirementWith(context.Background(), b)
+	if err != nil {
+		t.Fatalf("QueryHierarchicalRequirementWith failed unexpectedly: %v", err)
+	}
+	if len(page.Results) != 1 || page.Results[0].FormattedID != "US1" {
+		t.Errorf("unexpected results: %+v", page.Results)
+	}
+
+	values := fakeClient.SpyRequest.URL.Query()
+	if got := values.Get("query"); got != "(( ScheduleState = Accepted ) AND ( Owner.DisplayName contains Smith ))" {
+		t.Errorf("unexpected query %s", got)
+	}
+	if values.Get("order") != "Rank ASC" || values.Get("pagesize") != "200" {
+		t.Errorf("unexpected options %q", fakeClient.SpyRequest.URL.RawQuery)
+	}
+}
+
+func TestQueryDefectWith_InvalidBuilderSendsNothing(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{}
+	defectClient := NewDefect(New("abcdef", "http://myRallyUrl", fakeClient))
+
+	_, err := defectClient.QueryDefectWith(context.Background(), NewQuery().Where("State", nil, "Open"))
+	if !errors.Is(err, ErrInvalidQuery) {
+		t.Errorf("expected ErrInvalidQuery, got %v", err)
+	}
+	if fakeClient.CallCount != 0 {
+		t.Errorf("expected no HTTP call, got %d", fakeClient.CallCount)
+	}
+}
@@ -0,0 +1,61 @@
+/**
+* Copyright 2014 Comcast Cable Communications Management, LLC
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+* http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package rallyresttoolkit
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// QueryStream - runs a query page by page in a goroutine and sends every
+// result on the returned results channel, in order. The results channel is
+// unbuffered, so paging waits while the consumer is busy.
+//
+// The results channel is closed after the last result, or as soon as paging
+// fails. On failure, including ctx being cancelled, exactly one
+// *PaginationError is sent on the error channel; it is closed after the
+// results channel, without a value on success. A consumer that stops early
+// must cancel ctx so the goroutine exits.
+func (s *RallyClient) QueryStream(ctx context.Context, query map[string]string, queryType string, opts QueryOptions) (<-chan json.RawMessage, <-chan error) {
+	return QueryStreamResults[json.RawMessage](ctx, s, query, queryType, opts)
+}
+
+// QueryStreamResults - like QueryStream, but sends typed models, e.g.
+// QueryStreamResults[models.Defect](ctx, client, query, "defect", opts).
+func QueryStreamResults[T any](ctx context.Context, client *RallyClient, query map[string]string, queryType string, opts QueryOptions) (<-chan T, <-chan error) {
+	results := make(chan T)
+	errs := make(chan error, 1)
+
+	go func() {
+		defer close(errs)
+		defer close(results)
+
+		_, _, err := queryAll[T](ctx, client, query, queryType, opts, func(result T) error {
+			select {
+			case results <- result:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		})
+		if err != nil {
+			errs <- err
+		}
+	}()
+
+	return results, errs
+}
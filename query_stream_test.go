@@ -0,0 +1,133 @@
+/**
+* Copyright 2014 Comcast Cable Communications Management, LLC
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+* http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package rallyresttoolkit_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"runtime"
+	"testing"
+	"time"
+
+	. "github.com/aleksofficial/go-rally-rest-toolkit"
+	"github.com/aleksofficial/go-rally-rest-toolkit/fakes"
+	"github.com/aleksofficial/go-rally-rest-toolkit/models"
+)
+
+func TestQueryStream_AllPages(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{
+		FakeResponses: []*http.Response{
+			queryPageResponse(1, 20, 45),
+			queryPageResponse(21, 20, 45),
+			queryPageResponse(41, 5, 45),
+		},
+	}
+	rallyClient := New("abcdef", "http://myRallyUrl", fakeClient)
+
+	results, errs := QueryStreamResults[models.Defect](context.Background(), rallyClient, nil, "defect", QueryOptions{PageSize: 20})
+	count := 0
+	for defect := range results {
+		count++
+		if defect.ObjectID != count {
+			t.Fatalf("expected ObjectID %d, got %d", count, defect.ObjectID)
+		}
+	}
+	if count != 45 {
+		t.Errorf("expected 45 results, got %d", count)
+	}
+	if err, ok := <-errs; ok {
+		t.Errorf("expected the error channel to close without a value, got %v", err)
+	}
+}
+
+func TestQueryStream_FailingPage(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{
+		FakeResponses: []*http.Response{
+			queryPageResponse(1, 20, 45),
+			{
+				StatusCode: http.StatusBadRequest,
+				Body:       &fakes.FakeResponseBody{Reader: bytes.NewBufferString(`{"QueryResult": {"Errors": ["bad page"]}}`)},
+			},
+		},
+	}
+	rallyClient := New("abcdef", "http://myRallyUrl", fakeClient)
+
+	results, errs := rallyClient.QueryStream(context.Background(), nil, "defect", QueryOptions{PageSize: 20})
+	count := 0
+	for range results {
+		count++
+	}
+	if count != 20 {
+		t.Errorf("expected the first page's 20 results, got %d", count)
+	}
+
+	var received []error
+	for err := range errs {
+		received = append(received, err)
+	}
+	if len(received) != 1 {
+		t.Fatalf("expected exactly one error, got %v", received)
+	}
+	var pageErr *PaginationError
+	if !errors.As(received[0], &pageErr) || pageErr.Start != 21 {
+		t.Errorf("expected a *PaginationError at start 21, got %v", received[0])
+	}
+}
+
+func TestQueryStream_CancelDoesNotLeak(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{
+		FakeResponses: []*http.Response{
+			queryPageResponse(1, 20, 45),
+			queryPageResponse(21, 20, 45),
+			queryPageResponse(41, 5, 45),
+		},
+	}
+	rallyClient := New("abcdef", "http://myRallyUrl", fakeClient)
+	before := runtime.NumGoroutine()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	results, errs := rallyClient.QueryStream(ctx, nil, "defect", QueryOptions{PageSize: 20})
+	for i := 0; i < 5; i++ {
+		<-results
+	}
+	// Stop reading mid-page; the producer is blocked sending the sixth result.
+	cancel()
+
+	err := <-errs
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+	if _, ok := <-errs; ok {
+		t.Error("expected the error channel to be closed after its one error")
+	}
+	if _, ok := <-results; ok {
+		t.Error("expected the results channel to be closed")
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for runtime.NumGoroutine() > before && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if n := runtime.NumGoroutine(); n > before {
+		t.Errorf("expected the producer goroutine to exit, %d goroutines remain (started with %d)", n, before)
+	}
+	if fakeClient.CallCount != 1 {
+		t.Errorf("expected no further pages after cancellation, got %d requests", fakeClient.CallCount)
+	}
+}
@@ -0,0 +1,599 @@
+/**
+* Copyright 2014 Comcast Cable Communications Management, LLC
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+* http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package rallyresttoolkit_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	. "github.com/aleksofficial/go-rally-rest-toolkit"
+	"github.com/aleksofficial/go-rally-rest-toolkit/fakes"
+	"github.com/aleksofficial/go-rally-rest-toolkit/models"
+)
+
+func TestQueryRequestWithOptions_OrderByRank(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{
+		FakeResponse: &http.Response{
+			StatusCode: http.StatusOK,
+			Body: &fakes.FakeResponseBody{Reader: bytes.NewBufferString(`{"QueryResult": { "TotalResultCount": 3, "Results": [
+				{"FormattedID": "US3", "DragAndDropRank": "O~zzzz"},
+				{"FormattedID": "US1", "DragAndDropRank": "O~!!!!"},
+				{"FormattedID": "US2", "DragAndDropRank": "O~PPPP"}
+			]}}`)},
+		},
+	}
+
+	rallyClient := New("abcdef", "http://myRallyUrl", fakeClient)
+
+	output := new(QueryHierarchicalRequirementResponse)
+	opts := QueryOptions{Order: "DragAndDropRank"}
+	err := rallyClient.QueryRequestWithOptions(context.Background(), map[string]string{"ScheduleState": "Defined"}, "hierarchicalrequirement", opts, &output)
+	if err != nil {
+		t.Fatalf("QueryRequestWithOptions failed unexpectedly: %v", err)
+	}
+	if got := fakeClient.SpyRequest.URL.Query().Get("order"); got != "DragAndDropRank" {
+		t.Errorf("expected order=DragAndDropRank, got %q", got)
+	}
+
+	stories := output.QueryResult.Results
+	models.SortByRank(stories)
+	for i, want := range []string{"US1", "US2", "US3"} {
+		if stories[i].FormattedID != want {
+			t.Errorf("expected %s at position %d, got %s", want, i, stories[i].FormattedID)
+		}
+	}
+}
+
+func TestQueryRequest_NoOrderByDefault(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{
+		FakeResponse: &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       &fakes.FakeResponseBody{Reader: bytes.NewBufferString(`{"QueryResult": { "TotalResultCount": 0, "Results": []}}`)},
+		},
+	}
+
+	rallyClient := New("abcdef", "http://myRallyUrl", fakeClient)
+
+	fakeOutput := new(fakes.FakeOutput)
+	if err := rallyClient.QueryRequest(context.Background(), map[string]string{}, "defect", &fakeOutput); err != nil {
+		t.Fatalf("QueryRequest failed unexpectedly: %v", err)
+	}
+	if _, ok := fakeClient.SpyRequest.URL.Query()["order"]; ok {
+		t.Errorf("expected no order parameter, got %q", fakeClient.SpyRequest.URL.RawQuery)
+	}
+}
+
+func TestQueryRequestWithOptions_StartAndPageSize(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{
+		FakeResponse: &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       &fakes.FakeResponseBody{Reader: bytes.NewBufferString(`{"QueryResult": { "TotalResultCount": 0, "Results": []}}`)},
+		},
+	}
+
+	rallyClient := New("abcdef", "http://myRallyUrl", fakeClient)
+
+	fakeOutput := new(fakes.FakeOutput)
+	opts := QueryOptions{Start: 201, PageSize: 200}
+	if err := rallyClient.QueryRequestWithOptions(context.Background(), map[string]string{"State": "Open"}, "defect", opts, &fakeOutput); err != nil {
+		t.Fatalf("QueryRequestWithOptions failed unexpectedly: %v", err)
+	}
+
+	expected := "fetch=true&pagesize=200&query=%28+State+%3D+Open+%29&start=201"
+	if got := fakeClient.SpyRequest.URL.RawQuery; got != expected {
+		t.Errorf("expected query string %q, got %q", expected, got)
+	}
+}
+
+func TestQueryRequest_NoPagingParamsByDefault(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{
+		FakeResponse: &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       &fakes.FakeResponseBody{Reader: bytes.NewBufferString(`{"QueryResult": { "TotalResultCount": 0, "Results": []}}`)},
+		},
+	}
+
+	rallyClient := New("abcdef", "http://myRallyUrl", fakeClient)
+
+	fakeOutput := new(fakes.FakeOutput)
+	if err := rallyClient.QueryRequest(context.Background(), map[string]string{"State": "Open"}, "defect", &fakeOutput); err != nil {
+		t.Fatalf("QueryRequest failed unexpectedly: %v", err)
+	}
+
+	expected := "fetch=true&query=%28+State+%3D+Open+%29"
+	if got := fakeClient.SpyRequest.URL.RawQuery; got != expected {
+		t.Errorf("expected query string %q, got %q", expected, got)
+	}
+}
+
+func TestQueryRequestWithOptions_InvalidPageSize(t *testing.T) {
+	for _, size := range []int{-1, MaxPageSize + 1} {
+		fakeClient := &fakes.FakeHTTPClient{}
+		rallyClient := New("abcdef", "http://myRallyUrl", fakeClient)
+
+		fakeOutput := new(fakes.FakeOutput)
+		err := rallyClient.QueryRequestWithOptions(context.Background(), map[string]string{}, "defect", QueryOptions{PageSize: size}, &fakeOutput)
+		if !errors.Is(err, ErrInvalidPageSize) {
+			t.Errorf("PageSize %d: expected ErrInvalidPageSize, got %v", size, err)
+		}
+		if fakeClient.CallCount != 0 {
+			t.Errorf("PageSize %d: expected no HTTP call, got %d", size, fakeClient.CallCount)
+		}
+	}
+}
+
+func TestQueryRequest_QuotesSpecialValues(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		expected string
+	}{
+		{"plain", "Open", `( Name = Open )`},
+		{"spaces", "login page", `( Name = "login page" )`},
+		{"parentheses", "Feature: login (v2)", `( Name = "Feature: login (v2)" )`},
+		{"quotes", `say "hi"`, `( Name = "say \"hi\"" )`},
+		{"slashes", "client/server", `( Name = "client/server" )`},
+		{"short ref", "/iteration/12345", `( Name = /iteration/12345 )`},
+		{"portfolio item ref", "/portfolioitem/feature/678", `( Name = /portfolioitem/feature/678 )`},
+		{"path that is not a ref", "/usr/local", `( Name = "/usr/local" )`},
+		{"backslash", `C:\temp`, `( Name = "C:\\temp" )`},
+		{"single quote", "Bob's bug", `( Name = "Bob's bug" )`},
+		{"tab and newline", "line one\tline\ntwo", "( Name = \"line one\tline\ntwo\" )"},
+		{"unicode", "Überprüfung", `( Name = Überprüfung )`},
+		{"unicode with spaces", "日本語 の 名前", `( Name = "日本語 の 名前" )`},
+		{"already quoted", `"login page"`, `( Name = "login page" )`},
+		{"already quoted with escapes", `"say \"hi\""`, `( Name = "say \"hi\"" )`},
+		{"quoted at both ends only", `"a" OR "b"`, `( Name = "\"a\" OR \"b\"" )`},
+		{"trailing escaped quote", `"ends with \"`, `( Name = "\"ends with \\\"" )`},
+		{"null", Null, `( Name = null )`},
+		{"empty", "", `( Name = "" )`},
+		{"ampersand and equals", "A=B & C", `( Name = "A=B & C" )`},
+		{"percent", "100%", `( Name = "100%" )`},
+		{"percent-encoded text", "a%26b", `( Name = "a%26b" )`},
+	}
+
+	for _, tt := range tests {
+		fakeClient := &fakes.FakeHTTPClient{
+			FakeResponse: &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       &fakes.FakeResponseBody{Reader: bytes.NewBufferString(`{"QueryResult": { "TotalResultCount": 0, "Results": []}}`)},
+			},
+		}
+		rallyClient := New("abcdef", "http://myRallyUrl", fakeClient)
+
+		fakeOutput := new(fakes.FakeOutput)
+		if err := rallyClient.QueryRequest(context.Background(), map[string]string{"Name": tt.value}, "defect", &fakeOutput); err != nil {
+			t.Fatalf("%s: QueryRequest failed unexpectedly: %v", tt.name, err)
+		}
+		if got := fakeClient.SpyRequest.URL.Query().Get("query"); got != tt.expected {
+			t.Errorf("%s: expected query %s, got %s", tt.name, tt.expected, got)
+		}
+	}
+}
+
+func TestQueryDefectPage_OrderBy(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{
+		FakeResponse: &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       &fakes.FakeResponseBody{Reader: bytes.NewBufferString(`{"QueryResult": { "TotalResultCount": 0, "Results": []}}`)},
+		},
+	}
+	defectClient := NewDefect(New("abcdef", "http://myRallyUrl", fakeClient))
+
+	opts := QueryOptions{OrderBy: OrderBy{}.Desc("Priority").Asc("CreationDate")}
+	if _, err := defectClient.QueryDefectPage(context.Background(), map[string]string{}, opts); err != nil {
+		t.Fatalf("QueryDefectPage failed unexpectedly: %v", err)
+	}
+
+	if got := fakeClient.SpyRequest.URL.Query().Get("order"); got != "Priority DESC,CreationDate ASC" {
+		t.Errorf("expected order=Priority DESC,CreationDate ASC, got %q", got)
+	}
+	if !strings.Contains(fakeClient.SpyRequest.URL.RawQuery, "order=Priority+DESC%2CCreationDate+ASC") {
+		t.Errorf("expected encoded order parameter, got %q", fakeClient.SpyRequest.URL.RawQuery)
+	}
+}
+
+func TestQueryRequestWithOptions_RejectsInvalidOrderBy(t *testing.T) {
+	tests := []struct {
+		name string
+		opts QueryOptions
+	}{
+		{"empty field name", QueryOptions{OrderBy: OrderBy{}.Asc("Priority").Desc(" ")}},
+		{"both Order and OrderBy", QueryOptions{Order: "Rank", OrderBy: OrderBy{}.Asc("Priority")}},
+	}
+
+	for _, tt := range tests {
+		fakeClient := &fakes.FakeHTTPClient{}
+		rallyClient := New("abcdef", "http://myRallyUrl", fakeClient)
+
+		fakeOutput := new(fakes.FakeOutput)
+		err := rallyClient.QueryRequestWithOptions(context.Background(), map[string]string{}, "defect", tt.opts, &fakeOutput)
+		if !errors.Is(err, ErrInvalidOrder) {
+			t.Errorf("%s: expected ErrInvalidOrder, got %v", tt.name, err)
+		}
+		if fakeClient.CallCount != 0 {
+			t.Errorf("%s: expected no HTTP call, got %d", tt.name, fakeClient.CallCount)
+		}
+	}
+}
+
+func TestQueryRequest_ANDsConditions(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    map[string]string
+		expected string
+	}{
+		{
+			"one condition",
+			map[string]string{"ScheduleState": "Accepted"},
+			"query=%28+ScheduleState+%3D+Accepted+%29",
+		},
+		{
+			"two conditions",
+			map[string]string{"ScheduleState": "Accepted", "Iteration.Name": "Sprint 3"},
+			"query=%28%28+Iteration.Name+%3D+%22Sprint+3%22+%29+AND+%28+ScheduleState+%3D+Accepted+%29%29",
+		},
+		{
+			"four conditions",
+			map[string]string{"D": "4", "B": "2", "A": "1", "C": "3"},
+			"query=%28%28%28%28+A+%3D+1+%29+AND+%28+B+%3D+2+%29%29+AND+%28+C+%3D+3+%29%29+AND+%28+D+%3D+4+%29%29",
+		},
+	}
+
+	for _, tt := range tests {
+		fakeClient := &fakes.FakeHTTPClient{
+			FakeResponse: &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       &fakes.FakeResponseBody{Reader: bytes.NewBufferString(`{"QueryResult": { "TotalResultCount": 0, "Results": []}}`)},
+			},
+		}
+		rallyClient := New("abcdef", "http://myRallyUrl", fakeClient)
+
+		fakeOutput := new(fakes.FakeOutput)
+		if err := rallyClient.QueryRequest(context.Background(), tt.query, "hierarchicalrequirement", &fakeOutput); err != nil {
+			t.Fatalf("%s: QueryRequest failed unexpectedly: %v", tt.name, err)
+		}
+
+		values := fakeClient.SpyRequest.URL.Query()
+		if len(values["query"]) != 1 {
+			t.Errorf("%s: expected exactly one query parameter, got %d", tt.name, len(values["query"]))
+		}
+		if got := fakeClient.SpyRequest.URL.RawQuery; got != "fetch=true&"+tt.expected {
+			t.Errorf("%s: expected %q, got %q", tt.name, "fetch=true&"+tt.expected, got)
+		}
+	}
+}
+
+func TestQueryRequestRaw_SendsQueryVerbatim(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{
+		FakeResponse: &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       &fakes.FakeResponseBody{Reader: bytes.NewBufferString(`{"QueryResult": { "TotalResultCount": 0, "Results": []}}`)},
+		},
+	}
+	rallyClient := New("abcdef", "http://myRallyUrl", fakeClient)
+
+	raw := `(((Name contains "A=B & C") OR (Name = "say \"hi\" (v2)")) AND ((Owner.DisplayName != null) AND (PlanEstimate >= 5)))`
+	opts := QueryOptions{Order: "Rank", Start: 21, PageSize: 20}
+	fakeOutput := new(fakes.FakeOutput)
+	if err := rallyClient.QueryRequestRaw(context.Background(), raw, "hierarchicalrequirement", opts, &fakeOutput); err != nil {
+		t.Fatalf("QueryRequestRaw failed unexpectedly: %v", err)
+	}
+
+	values := fakeClient.SpyRequest.URL.Query()
+	if got := values.Get("query"); got != raw {
+		t.Errorf("expected query %s, got %s", raw, got)
+	}
+	if values.Get("fetch") != "true" || values.Get("order") != "Rank" || values.Get("start") != "21" || values.Get("pagesize") != "20" {
+		t.Errorf("expected structured options alongside the raw query, got %q", fakeClient.SpyRequest.URL.RawQuery)
+	}
+}
+
+func TestQueryDefectRaw(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{
+		FakeResponse: &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       &fakes.FakeResponseBody{Reader: bytes.NewBufferString(`{"QueryResult": { "TotalResultCount": 1, "StartIndex": 1, "Results": [{"ObjectID": 7}]}}`)},
+		},
+	}
+	defectClient := NewDefect(New("abcdef", "http://myRallyUrl", fakeClient))
+
+	page, err := defectClient.QueryDefectRaw(context.Background(), `(State != "Closed")`, QueryOptions{})
+	if err != nil {
+		t.Fatalf("QueryDefectRaw failed unexpectedly: %v", err)
+	}
+	if len(page.Results) != 1 || page.Results[0].ObjectID != 7 {
+		t.Errorf("unexpected results: %+v", page.Results)
+	}
+	if got := fakeClient.SpyRequest.URL.Query().Get("query"); got != `(State != "Closed")` {
+		t.Errorf("unexpected query %s", got)
+	}
+}
+
+func TestQueryRequestWithOptions_IncludePermissionsAndExtra(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{
+		FakeResponse: &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       &fakes.FakeResponseBody{Reader: bytes.NewBufferString(`{"QueryResult": { "TotalResultCount": 0, "Results": []}}`)},
+		},
+	}
+	rallyClient := New("abcdef", "http://myRallyUrl", fakeClient)
+
+	fakeOutput := new(fakes.FakeOutput)
+	opts := QueryOptions{IncludePermissions: true, Extra: map[string]string{"compact": "true"}}
+	if err := rallyClient.QueryRequestWithOptions(context.Background(), map[string]string{}, "defect", opts, &fakeOutput); err != nil {
+		t.Fatalf("QueryRequestWithOptions failed unexpectedly: %v", err)
+	}
+
+	expected := "compact=true&fetch=true&includePermissions=true"
+	if got := fakeClient.SpyRequest.URL.RawQuery; got != expected {
+		t.Errorf("expected query string %q, got %q", expected, got)
+	}
+}
+
+func TestQueryRequestWithOptions_ExtraCannotOverrideCoreParams(t *testing.T) {
+	for _, name := range []string{"query", "fetch", "start", "PageSize"} {
+		fakeClient := &fakes.FakeHTTPClient{}
+		rallyClient := New("abcdef", "http://myRallyUrl", fakeClient)
+
+		fakeOutput := new(fakes.FakeOutput)
+		opts := QueryOptions{Extra: map[string]string{name: "1"}}
+		err := rallyClient.QueryRequestWithOptions(context.Background(), map[string]string{}, "defect", opts, &fakeOutput)
+		if !errors.Is(err, ErrReservedParam) {
+			t.Errorf("%s: expected ErrReservedParam, got %v", name, err)
+		}
+		if fakeClient.CallCount != 0 {
+			t.Errorf("%s: expected no HTTP call, got %d", name, fakeClient.CallCount)
+		}
+	}
+}
+
+func TestFetchModes(t *testing.T) {
+	shallow := `{"_ref": "https://rally1.rallydev.com/slm/webservice/v2.0/defect/7", "_refObjectName": "Crash", "_type": "Defect"}`
+	tests := []struct {
+		name     string
+		mode     FetchMode
+		fields   []string
+		expected string
+	}{
+		{"full", FetchFull, nil, "fetch=true"},
+		{"full with fields", FetchFull, []string{"ObjectID", "FormattedID"}, "fetch=ObjectID%2CFormattedID"},
+		{"fields", FetchFields, []string{"ObjectID", "FormattedID"}, "fetch=ObjectID%2CFormattedID"},
+		{"none", FetchNone, nil, ""},
+	}
+
+	for _, tt := range tests {
+		fakeClient := &fakes.FakeHTTPClient{
+			FakeResponse: &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       &fakes.FakeResponseBody{Reader: bytes.NewBufferString(`{"QueryResult": {"TotalResultCount": 1, "Results": [` + shallow + `]}}`)},
+			},
+		}
+		rallyClient := New("abcdef", "http://myRallyUrl", fakeClient)
+
+		var queryResponse models.QueryResponse[models.Defect]
+		opts := QueryOptions{FetchMode: tt.mode, Fetch: tt.fields}
+		if err := rallyClient.QueryRequestWithOptions(context.Background(), nil, "defect", opts, &queryResponse); err != nil {
+			t.Fatalf("%s: QueryRequestWithOptions failed unexpectedly: %v", tt.name, err)
+		}
+		if got := fakeClient.SpyRequest.URL.RawQuery; got != tt.expected {
+			t.Errorf("%s: expected query string %q, got %q", tt.name, tt.expected, got)
+		}
+		if results := queryResponse.QueryResult.Results; len(results) != 1 || results[0].RefObjectName != "Crash" {
+			t.Errorf("%s: expected the shallow defect to decode, got %+v", tt.name, results)
+		}
+
+		fakeClient.FakeResponse = &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       &fakes.FakeResponseBody{Reader: bytes.NewBufferString(`{"Defect": ` + shallow + `}`)},
+		}
+		var getResponse GetDefectResponse
+		if err := rallyClient.GetRequestWithFetch(context.Background(), "7", "defect", tt.mode, tt.fields, &getResponse); err != nil {
+			t.Fatalf("%s: GetRequestWithFetch failed unexpectedly: %v", tt.name, err)
+		}
+		if got := fakeClient.SpyRequest.URL.RawQuery; got != tt.expected {
+			t.Errorf("%s: expected get query string %q, got %q", tt.name, tt.expected, got)
+		}
+		if getResponse.Defect.Type != "Defect" {
+			t.Errorf("%s: expected the shallow defect to decode, got %+v", tt.name, getResponse.Defect)
+		}
+	}
+}
+
+func TestFetchModes_Invalid(t *testing.T) {
+	tests := map[string]QueryOptions{
+		"fields without fields": {FetchMode: FetchFields},
+		"none with fields":      {FetchMode: FetchNone, Fetch: []string{"Name"}},
+		"unknown mode":          {FetchMode: FetchMode(9)},
+	}
+
+	for name, opts := range tests {
+		fakeClient := &fakes.FakeHTTPClient{}
+		rallyClient := New("abcdef", "http://myRallyUrl", fakeClient)
+
+		fakeOutput := new(fakes.FakeOutput)
+		if err := rallyClient.QueryRequestWithOptions(context.Background(), nil, "defect", opts, &fakeOutput); !errors.Is(err, ErrInvalidFetch) {
+			t.Errorf("%s: expected ErrInvalidFetch from query, got %v", name, err)
+		}
+		if err := rallyClient.GetRequestWithFetch(context.Background(), "7", "defect", opts.FetchMode, opts.Fetch, &fakeOutput); !errors.Is(err, ErrInvalidFetch) {
+			t.Errorf("%s: expected ErrInvalidFetch from get, got %v", name, err)
+		}
+		if fakeClient.CallCount != 0 {
+			t.Errorf("%s: expected no HTTP call, got %d", name, fakeClient.CallCount)
+		}
+	}
+}
+
+func TestQueryRequestWithOptions_ProjectScoping(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     QueryOptions
+		expected string
+	}{
+		{"unset", QueryOptions{}, "fetch=true"},
+		{"project only", QueryOptions{Project: "/project/123"}, "fetch=true&project=%2Fproject%2F123"},
+		{"scope up true", QueryOptions{Project: "/project/123", ProjectScopeUp: models.Bool(true)}, "fetch=true&project=%2Fproject%2F123&projectScopeUp=true"},
+		{"scope up false", QueryOptions{Project: "/project/123", ProjectScopeUp: models.Bool(false)}, "fetch=true&project=%2Fproject%2F123&projectScopeUp=false"},
+		{"scope down true", QueryOptions{Project: "/project/123", ProjectScopeDown: models.Bool(true)}, "fetch=true&project=%2Fproject%2F123&projectScopeDown=true"},
+		{"scope down false", QueryOptions{Project: "/project/123", ProjectScopeDown: models.Bool(false)}, "fetch=true&project=%2Fproject%2F123&projectScopeDown=false"},
+		{
+			"up false down true",
+			QueryOptions{Project: "/project/123", ProjectScopeUp: models.Bool(false), ProjectScopeDown: models.Bool(true)},
+			"fetch=true&project=%2Fproject%2F123&projectScopeDown=true&projectScopeUp=false",
+		},
+		{
+			"up true down false",
+			QueryOptions{Project: "/project/123", ProjectScopeUp: models.Bool(true), ProjectScopeDown: models.Bool(false)},
+			"fetch=true&project=%2Fproject%2F123&projectScopeDown=false&projectScopeUp=true",
+		},
+		{"scopes without project", QueryOptions{ProjectScopeUp: models.Bool(true), ProjectScopeDown: models.Bool(true)}, "fetch=true&projectScopeDown=true&projectScopeUp=true"},
+	}
+
+	for _, tt := range tests {
+		fakeClient := &fakes.FakeHTTPClient{
+			FakeResponse: &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       &fakes.FakeResponseBody{Reader: bytes.NewBufferString(`{"QueryResult": { "TotalResultCount": 0, "Results": []}}`)},
+			},
+		}
+		rallyClient := New("abcdef", "http://myRallyUrl", fakeClient)
+
+		fakeOutput := new(fakes.FakeOutput)
+		if err := rallyClient.QueryRequestWithOptions(context.Background(), map[string]string{}, "defect", tt.opts, &fakeOutput); err != nil {
+			t.Fatalf("%s: QueryRequestWithOptions failed unexpectedly: %v", tt.name, err)
+		}
+		if got := fakeClient.SpyRequest.URL.RawQuery; got != tt.expected {
+			t.Errorf("%s: expected %q, got %q", tt.name, tt.expected, got)
+		}
+	}
+}
+
+func TestQueryDefectPage_DefaultProject(t *testing.T) {
+	newClient := func() (*fakes.FakeHTTPClient, *Defect) {
+		fakeClient := &fakes.FakeHTTPClient{
+			FakeResponse: &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       &fakes.FakeResponseBody{Reader: bytes.NewBufferString(`{"QueryResult": { "TotalResultCount": 0, "Results": []}}`)},
+			},
+		}
+		rallyClient, err := NewClient(WithHTTPClient(fakeClient), WithDefaultProject("/project/123"))
+		if err != nil {
+			t.Fatalf("NewClient failed unexpectedly: %v", err)
+		}
+		return fakeClient, NewDefect(rallyClient)
+	}
+
+	fakeClient, defectClient := newClient()
+	if _, err := defectClient.QueryDefect(context.Background(), map[string]string{}); err != nil {
+		t.Fatalf("QueryDefect failed unexpectedly: %v", err)
+	}
+	if got := fakeClient.SpyRequest.URL.Query().Get("project"); got != "/project/123" {
+		t.Errorf("expected the default project, got %q", got)
+	}
+
+	fakeClient, defectClient = newClient()
+	if _, err := defectClient.QueryDefectPage(context.Background(), map[string]string{}, QueryOptions{Project: "/project/456"}); err != nil {
+		t.Fatalf("QueryDefectPage failed unexpectedly: %v", err)
+	}
+	if got := fakeClient.SpyRequest.URL.Query().Get("project"); got != "/project/456" {
+		t.Errorf("expected the per-query project to win, got %q", got)
+	}
+}
+
+func TestPage_HasMore(t *testing.T) {
+	tests := []struct {
+		name  string
+		page  Page[int]
+		more  bool
+		start int
+	}{
+		{"first of three pages", Page[int]{QueryResult: models.QueryResult[int]{Results: make([]int, 20), TotalResultCount: 45, StartIndex: 1}}, true, 21},
+		{"last partial page", Page[int]{QueryResult: models.QueryResult[int]{Results: make([]int, 5), TotalResultCount: 45, StartIndex: 41}}, false, 46},
+		{"exactly full", Page[int]{QueryResult: models.QueryResult[int]{Results: make([]int, 20), TotalResultCount: 20, StartIndex: 1}}, false, 21},
+		{"empty", Page[int]{QueryResult: models.QueryResult[int]{TotalResultCount: 10, StartIndex: 1}}, false, 1},
+	}
+
+	for _, tt := range tests {
+		if got := tt.page.HasMore(); got != tt.more {
+			t.Errorf("%s: HasMore() = %v, expected %v", tt.name, got, tt.more)
+		}
+		if got := tt.page.NextStart(); got != tt.start {
+			t.Errorf("%s: NextStart() = %d, expected %d", tt.name, got, tt.start)
+		}
+	}
+}
+
+func TestQueryRequest_EncodesReservedCharacters(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		expected string
+	}{
+		{"ampersand and equals", "A=B & C", `( Name = "A=B & C" )`},
+		{"bare ampersand", "R&D", `( Name = "R&D" )`},
+		{"bare equals", "x=y", `( Name = "x=y" )`},
+		{"percent", "50% done", `( Name = "50% done" )`},
+		{"percent escape", "%26", `( Name = "%26" )`},
+		{"plus", "C++", `( Name = C++ )`},
+		{"hash and question mark", "#1?", `( Name = #1? )`},
+	}
+
+	for _, tt := range tests {
+		fakeClient := &fakes.FakeHTTPClient{
+			FakeResponse: &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       &fakes.FakeResponseBody{Reader: bytes.NewBufferString(`{"QueryResult": { "TotalResultCount": 0, "Results": []}}`)},
+			},
+		}
+		rallyClient := New("abcdef", "http://myRallyUrl", fakeClient)
+
+		query := map[string]string{"Name": tt.value, "State": "Open"}
+		fakeOutput := new(fakes.FakeOutput)
+		if err := rallyClient.QueryRequestWithOptions(context.Background(), query, "defect", QueryOptions{Order: "Rank"}, &fakeOutput); err != nil {
+			t.Fatalf("%s: QueryRequestWithOptions failed unexpectedly: %v", tt.name, err)
+		}
+
+		// Every "&" and "=" on the wire must be a parameter separator, and the
+		// server must decode the query back to the exact expression.
+		rawQuery := fakeClient.SpyRequest.URL.RawQuery
+		params := strings.Split(rawQuery, "&")
+		if len(params) != 3 {
+			t.Errorf("%s: expected fetch, order and query parameters, got %q", tt.name, rawQuery)
+		}
+		for _, param := range params {
+			if strings.Count(param, "=") != 1 {
+				t.Errorf("%s: unescaped separator in parameter %q", tt.name, param)
+			}
+		}
+		values, err := url.ParseQuery(rawQuery)
+		if err != nil {
+			t.Fatalf("%s: failed to parse %q: %v", tt.name, rawQuery, err)
+		}
+		expected := "(" + tt.expected + " AND ( State = Open ))"
+		if got := values.Get("query"); got != expected {
+			t.Errorf("%s: expected query %s, got %s", tt.name, expected, got)
+		}
+		if got := values.Get("order"); got != "Rank" {
+			t.Errorf("%s: expected order Rank, got %q", tt.name, got)
+		}
+	}
+}
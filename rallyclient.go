@@ -22,49 +22,58 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"math/rand"
 	"net/http"
 	"net/url"
 	"strings"
+	"sync"
 	"time"
 )
 
-//RallyClient - struct
+// RallyClient - struct
 type RallyClient struct {
-	apikey string
-	apiurl string
-	client ClientDoer
-	config *Config
+	apikey  string
+	apiurl  string
+	client  ClientDoer
+	config  *Config
+	limiter RateLimiter
+	// limiterFromConfig is set when limiter was built from the config rather
+	// than passed in, so SetConfig may replace it
+	limiterFromConfig bool
+	logger            Logger
+	clock             Clock
+	// closed is closed by Close
+	closed    chan struct{}
+	closeOnce sync.Once
 }
 
-//ClientDoer - interface
+// ClientDoer - interface
 type ClientDoer interface {
 	Do(*http.Request) (*http.Response, error)
 }
 
 // New - creates a new RallyClient
 func New(apikey string, apiurl string, client ClientDoer) *RallyClient {
-	return &RallyClient{
-		apikey: apikey,
-		apiurl: apiurl,
-		client: client,
-	}
+	// None of these options can fail.
+	s, _ := newClient(WithAPIKey(apikey), WithBaseURL(apiurl), WithHTTPClient(client))
+	return s
 }
 
-//HTTPClient - returns the internal client object
+// HTTPClient - returns the internal client object
 func (s *RallyClient) HTTPClient() ClientDoer {
 	return s.client
 }
 
-// SetConfig sets the configuration for the RallyClient
+// SetConfig sets the configuration for the RallyClient, including its rate
+// limit unless one was set with WithRateLimit or WithRateLimiter
 func (s *RallyClient) SetConfig(config *Config) {
 	s.config = config
+	s.applyConfigRateLimit()
 }
 
 // isRetryableStatusCode returns true if the HTTP status code indicates a transient error
-// that should be retried (5xx server errors)
+// that should be retried (5xx server errors and 429 throttling)
 func isRetryableStatusCode(statusCode int) bool {
-	return statusCode >= 500 && statusCode < 600
+	return statusCode == http.StatusTooManyRequests || (statusCode >= 500 && statusCode < 600)
 }
 
 // isRetryableError returns true if the error is a transient error that should be retried
@@ -85,59 +94,90 @@ func isRetryableError(err error) bool {
 		strings.Contains(errStr, "temporary failure")
 }
 
-// doWithRetry executes an HTTP request with retry logic and exponential backoff
+// isCreateRequest reports whether req is a CreateRequest, i.e. a POST to a
+// .../create endpoint.
+func isCreateRequest(req *http.Request) bool {
+	return req.Method == "POST" && strings.HasSuffix(req.URL.Path, "/create")
+}
+
+// doWithRetry executes an HTTP request with retry logic and backoff (exponential by default)
 // It retries on 5xx errors and transient network errors, but not on 4xx errors
 func (s *RallyClient) doWithRetry(req *http.Request, body []byte) (*http.Response, error) {
-	maxRetries := DefaultMaxRetries
-	retryDelay := DefaultRetryDelay
-	if s.config != nil {
-		maxRetries = s.config.MaxRetries
-		retryDelay = s.config.RetryDelay
+	maxRetries := s.maxRetries(req.Context())
+	retryCreates := s.config != nil && s.config.RetryCreatesOnTransportError
+	retryAllowed := func() bool { return s.retryAllowed(req.Method, req.URL.Path) }
+	// A create whose response was lost may still have been applied, so
+	// retrying it after a transport error can create a duplicate.
+	unsafeRetry := isCreateRequest(req) && !retryCreates
+
+	stats := callStatsFromContext(req.Context())
+	if stats != nil {
+		*stats = CallStats{}
+		start := s.clock.Now()
+		defer func() { stats.TotalDuration = s.clock.Now().Sub(start) }()
 	}
 
 	var lastErr error
-	var lastResp *http.Response
 
 	for attempt := 0; attempt <= maxRetries; attempt++ {
+		var retryAfter time.Duration
+		hasRetryAfter := false
 		// If this is a retry and we have a body, we need to reset the request body
 		if attempt > 0 && body != nil {
 			req.Body = io.NopCloser(bytes.NewReader(body))
 		}
 
+		if s.limiter != nil {
+			if err := s.limiter.Wait(req.Context()); err != nil {
+				return nil, fmt.Errorf("rate limiter: %w", err)
+			}
+		}
+
 		resp, err := s.client.Do(req)
+		if err != nil {
+			stats.record(nil)
+		} else {
+			stats.record(resp)
+		}
 
 		if err != nil {
+			// A ClientDoer may hand back a response alongside an error; it is
+			// never returned to the caller, so release it here.
+			if resp != nil && resp.Body != nil {
+				resp.Body.Close()
+			}
 			lastErr = err
 			// Check if the error is retryable
-			if !isRetryableError(err) || attempt == maxRetries {
+			if !isRetryableError(err) || unsafeRetry || attempt == maxRetries || !retryAllowed() {
 				return nil, err
 			}
 		} else {
 			// Check if we should retry based on status code
-			if !isRetryableStatusCode(resp.StatusCode) || attempt == maxRetries {
+			if !isRetryableStatusCode(resp.StatusCode) || attempt == maxRetries || !retryAllowed() {
 				return resp, nil
 			}
 			// Close the response body before retrying to avoid resource leak
 			resp.Body.Close()
-			lastResp = resp
 			lastErr = fmt.Errorf("server returned status %d", resp.StatusCode)
+			retryAfter, hasRetryAfter = s.retryAfter(resp)
 		}
 
-		// Calculate delay with exponential backoff: delay * 2^attempt
-		delay := time.Duration(retryDelay) * time.Millisecond * (1 << attempt)
+		delay := s.retryDelay(req.Context(), attempt)
+		if hasRetryAfter {
+			delay = retryAfter
+		}
 
-		// Add jitter: random value between 0 and 50% of the delay to prevent thundering herd
-		jitter := time.Duration(rand.Int63n(int64(delay / 2)))
-		delay += jitter
+		if s.logger != nil {
+			s.logger.Printf("rally: retrying %s %s in %v (retry %d of %d): %v", req.Method, req.URL.Path, delay, attempt+1, maxRetries, lastErr)
+		}
 
 		// Wait before retrying, respecting context cancellation
 		select {
 		case <-req.Context().Done():
-			if lastResp != nil {
-				return nil, fmt.Errorf("context cancelled after %d retries: %w", attempt, req.Context().Err())
-			}
 			return nil, fmt.Errorf("context cancelled after %d retries: %w", attempt, req.Context().Err())
-		case <-time.After(delay):
+		case <-s.closed:
+			return nil, fmt.Errorf("%w after %d retries", ErrClientClosed, attempt)
+		case <-s.clock.After(delay):
 			// Continue to next retry attempt
 		}
 	}
@@ -145,152 +185,224 @@ func (s *RallyClient) doWithRetry(req *http.Request, body []byte) (*http.Respons
 	return nil, fmt.Errorf("request failed after %d retries: %w", maxRetries, lastErr)
 }
 
-// QueryRequest - function to search for an object.
-func (s *RallyClient) QueryRequest(ctx context.Context, query map[string]string, queryType string, output interface{}) error {
-	baseURL, err := url.Parse(strings.Join([]string{s.apiurl, queryType}, "/"))
-	if err != nil {
-		return fmt.Errorf("failed to parse URL: %w", err)
+// maxRetries returns the retry limit for a request made with ctx.
+func (s *RallyClient) maxRetries(ctx context.Context) int {
+	maxRetries := DefaultMaxRetries
+	if s.config != nil {
+		maxRetries = s.config.MaxRetries
 	}
+	if override, ok := retryOverrideFromContext(ctx); ok && override.maxRetries >= 0 {
+		maxRetries = override.maxRetries
+	}
+	return maxRetries
+}
 
-	params := url.Values{}
-	params.Add("fetch", "true")
-	for idx, val := range query {
-		params.Add("query", fmt.Sprintf("( %s = %s )", idx, val))
+// retryAllowed spends from the retry budget; it is only called once a retry
+// is otherwise due.
+func (s *RallyClient) retryAllowed(method, path string) bool {
+	if s.config == nil || s.config.RetryBudget == nil || s.config.RetryBudget.allow(s.clock.Now()) {
+		return true
 	}
-	baseURL.RawQuery = params.Encode()
+	if s.logger != nil {
+		s.logger.Printf("rally: retry budget exhausted, not retrying %s %s", method, path)
+	}
+	return false
+}
 
-	urlStr := baseURL.String()
+// QueryRequest - function to search for an object.
+func (s *RallyClient) QueryRequest(ctx context.Context, query map[string]string, queryType string, output interface{}) error {
+	return s.QueryRequestWithOptions(ctx, query, queryType, QueryOptions{}, output)
+}
 
-	req, err := http.NewRequestWithContext(ctx, "GET", urlStr, nil)
-	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
+// QueryRequestWithOptions - QueryRequest with optional parameters such as order.
+func (s *RallyClient) QueryRequestWithOptions(ctx context.Context, query map[string]string, queryType string, opts QueryOptions, output interface{}) error {
+	opts = s.queryDefaults(opts)
+	if err := opts.validate(); err != nil {
+		return err
 	}
-	req.Header.Add("ZSESSIONID", s.apikey)
 
-	rallyResponse, err := s.doWithRetry(req, nil)
+	baseURL, err := s.endpoint(queryType)
 	if err != nil {
-		return fmt.Errorf("failed to execute request: %w", err)
+		return err
 	}
-	defer rallyResponse.Body.Close()
+	baseURL.RawQuery = opts.encode(query).Encode()
 
-	content, err := io.ReadAll(rallyResponse.Body)
-	if err != nil {
-		return fmt.Errorf("failed to read response body: %w", err)
+	return s.execute(ctx, "GET", baseURL, nil, output)
+}
+
+// QueryRequestRaw - QueryRequestWithOptions for a query clause that is sent
+// verbatim, e.g. one copied from Rally's query box. It is URL-encoded but not
+// otherwise quoted or wrapped. An empty rawQuery sends no query.
+func (s *RallyClient) QueryRequestRaw(ctx context.Context, rawQuery string, queryType string, opts QueryOptions, output interface{}) error {
+	opts = s.queryDefaults(opts)
+	if err := opts.validate(); err != nil {
+		return err
 	}
 
-	if rallyResponse.StatusCode < 200 || rallyResponse.StatusCode >= 300 {
-		return parseRallyError(rallyResponse.StatusCode, content)
+	baseURL, err := s.endpoint(queryType)
+	if err != nil {
+		return err
 	}
+	params := opts.encode(nil)
+	if rawQuery != "" {
+		params.Set("query", rawQuery)
+	}
+	baseURL.RawQuery = params.Encode()
 
-	if err := json.Unmarshal(content, output); err != nil {
-		return fmt.Errorf("failed to unmarshal response: %w", err)
+	return s.execute(ctx, "GET", baseURL, nil, output)
+}
+
+// queryDefaults fills in client-wide defaults the query options leave unset.
+func (s *RallyClient) queryDefaults(opts QueryOptions) QueryOptions {
+	if opts.Workspace == "" && s.config != nil {
+		opts.Workspace = s.config.DefaultWorkspace
 	}
+	if opts.Project == "" && s.config != nil {
+		opts.Project = s.config.DefaultProject
+	}
+	if opts.PageSize == 0 && s.config != nil {
+		opts.PageSize = s.config.DefaultPageSize
+	}
+	return opts
+}
 
-	return nil
+// pageSize returns Config.DefaultPageSize if set, or fallback, for paging
+// helpers that need a page size before a request is made.
+func (s *RallyClient) pageSize(fallback int) int {
+	if s.config != nil && s.config.DefaultPageSize != 0 {
+		return s.config.DefaultPageSize
+	}
+	return fallback
 }
 
 // GetRequest - Function to perform GET requests when objectID is known.
 func (s *RallyClient) GetRequest(ctx context.Context, objectID string, queryType string, output interface{}) error {
-	baseURL, err := url.Parse(strings.Join([]string{s.apiurl, queryType, objectID}, "/"))
+	return s.GetRequestWithFetch(ctx, objectID, queryType, FetchFull, nil, output)
+}
+
+// GetRequestWithFetch - GetRequest that returns the fields selected by mode
+// and fields, as QueryOptions.FetchMode and Fetch do for queries.
+func (s *RallyClient) GetRequestWithFetch(ctx context.Context, objectID string, queryType string, mode FetchMode, fields []string, output interface{}) error {
+	fetch, ok, err := fetchParam(mode, fields)
 	if err != nil {
-		return fmt.Errorf("failed to parse URL: %w", err)
+		return err
+	}
+
+	baseURL, err := s.endpoint(queryType, objectID)
+	if err != nil {
+		return err
 	}
 
 	params := url.Values{}
-	params.Add("fetch", "true")
+	if ok {
+		params.Add("fetch", fetch)
+	}
 	baseURL.RawQuery = params.Encode()
 
-	urlStr := baseURL.String()
+	return s.execute(ctx, "GET", baseURL, nil, output)
+}
 
-	req, err := http.NewRequestWithContext(ctx, "GET", urlStr, nil)
-	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
+func (s *RallyClient) CreateRequest(ctx context.Context, queryType string, input interface{}, output interface{}) error {
+	if s.config != nil && s.config.StrictFields {
+		if err := ValidateCreate(queryType, input); err != nil {
+			return err
+		}
 	}
-	req.Header.Add("ZSESSIONID", s.apikey)
 
-	rallyResponse, err := s.doWithRetry(req, nil)
+	baseURL, err := s.endpoint(queryType, "create")
 	if err != nil {
-		return fmt.Errorf("failed to execute request: %w", err)
+		return err
 	}
-	defer rallyResponse.Body.Close()
 
-	content, err := io.ReadAll(rallyResponse.Body)
+	inputByteArray, err := json.Marshal(input)
 	if err != nil {
-		return fmt.Errorf("failed to read response body: %w", err)
+		return fmt.Errorf("failed to marshal request body: %w", err)
 	}
 
-	if rallyResponse.StatusCode < 200 || rallyResponse.StatusCode >= 300 {
-		return parseRallyError(rallyResponse.StatusCode, content)
+	if output != nil && s.config != nil && s.config.FetchAfterCreate {
+		return s.createAndFetch(ctx, queryType, baseURL, inputByteArray, output)
 	}
+	return s.execute(ctx, "POST", baseURL, inputByteArray, output)
+}
 
-	if err := json.Unmarshal(content, output); err != nil {
-		return fmt.Errorf("failed to unmarshal response: %w", err)
+func (s *RallyClient) UpdateRequest(ctx context.Context, objectID string, queryType string, input interface{}, output interface{}) error {
+	if s.config != nil && s.config.StrictFields {
+		if err := ValidateCreate(queryType, input); err != nil {
+			return err
+		}
 	}
 
-	return nil
-}
-
-func (s *RallyClient) CreateRequest(ctx context.Context, queryType string, input interface{}, output interface{}) error {
-	baseURL, err := url.Parse(strings.Join([]string{s.apiurl, queryType, "create"}, "/"))
+	baseURL, err := s.endpoint(queryType, objectID)
 	if err != nil {
-		return fmt.Errorf("failed to parse URL: %w", err)
+		return err
 	}
 
-	urlStr := baseURL.String()
-
 	inputByteArray, err := json.Marshal(input)
 	if err != nil {
 		return fmt.Errorf("failed to marshal request body: %w", err)
 	}
 
-	req, err := http.NewRequestWithContext(ctx, "POST", urlStr, bytes.NewReader(inputByteArray))
-	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
+	refresh := func(ctx context.Context, body []byte) ([]byte, error) {
+		return s.refreshVersion(ctx, queryType, objectID, body)
 	}
-	req.Header.Add("ZSESSIONID", s.apikey)
+	return s.executeRetryingMessages(ctx, "POST", baseURL, inputByteArray, output, refresh)
+}
 
-	rallyResponse, err := s.doWithRetry(req, inputByteArray)
+func (s *RallyClient) DeleteRequest(ctx context.Context, objectID string, queryType string, output interface{}) error {
+	baseURL, err := s.endpoint(queryType, objectID)
 	if err != nil {
-		return fmt.Errorf("failed to execute request: %w", err)
+		return err
 	}
-	defer rallyResponse.Body.Close()
 
-	content, err := io.ReadAll(rallyResponse.Body)
-	if err != nil {
-		return fmt.Errorf("failed to read response body: %w", err)
-	}
+	params := url.Values{}
+	params.Add("fetch", "true")
+	baseURL.RawQuery = params.Encode()
 
-	if rallyResponse.StatusCode < 200 || rallyResponse.StatusCode >= 300 {
-		return parseRallyError(rallyResponse.StatusCode, content)
-	}
+	return s.execute(ctx, "DELETE", baseURL, nil, output)
+}
 
-	if err := json.Unmarshal(content, output); err != nil {
-		return fmt.Errorf("failed to unmarshal response: %w", err)
+// endpoint builds the URL for a path below the API base URL.
+func (s *RallyClient) endpoint(path ...string) (*url.URL, error) {
+	if len(path) > 0 && s.config != nil && s.config.ValidateEntityTypes && !isKnownEntityType(path[0]) {
+		return nil, fmt.Errorf("%w %q", ErrUnknownEntityType, path[0])
+	}
+	baseURL, err := url.Parse(strings.Join(append([]string{s.apiurl}, path...), "/"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse URL: %w", err)
 	}
+	return baseURL, nil
+}
 
-	return nil
+// execute sends a request with the API key, retrying transient failures, and
+// unmarshals a successful response into output, unless output is nil. Non-2xx responses, and POSTs
+// whose result envelope reports errors, are returned as a *RallyAPIError.
+func (s *RallyClient) execute(ctx context.Context, method string, u *url.URL, body []byte, output interface{}) error {
+	return s.executeRetryingMessages(ctx, method, u, body, output, nil)
 }
 
-func (s *RallyClient) UpdateRequest(ctx context.Context, objectID string, queryType string, input interface{}, output interface{}) error {
-	baseURL, err := url.Parse(strings.Join([]string{s.apiurl, queryType, objectID}, "/"))
-	if err != nil {
-		return fmt.Errorf("failed to parse URL: %w", err)
+// executeOnce is execute without retries on Config.RetryableErrorMessages.
+func (s *RallyClient) executeOnce(ctx context.Context, method string, u *url.URL, body []byte, output interface{}) error {
+	if s.isClosed() {
+		return ErrClientClosed
 	}
 
-	urlStr := baseURL.String()
-
-	inputByteArray, err := json.Marshal(input)
-	if err != nil {
-		return fmt.Errorf("failed to marshal request body: %w", err)
+	var reqBody io.Reader
+	if body != nil {
+		reqBody = bytes.NewReader(body)
 	}
 
-	req, err := http.NewRequestWithContext(ctx, "POST", urlStr, bytes.NewReader(inputByteArray))
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), reqBody)
 	if err != nil {
 		return fmt.Errorf("failed to create request: %w", err)
 	}
-	req.Header.Add("ZSESSIONID", s.apikey)
+	if s.config != nil {
+		for name, value := range s.config.Headers {
+			req.Header.Set(name, value)
+		}
+	}
+	req.Header.Set("ZSESSIONID", s.apikey)
 
-	rallyResponse, err := s.doWithRetry(req, inputByteArray)
+	rallyResponse, err := s.doWithRetry(req, body)
 	if err != nil {
 		return fmt.Errorf("failed to execute request: %w", err)
 	}
@@ -302,52 +414,44 @@ func (s *RallyClient) UpdateRequest(ctx context.Context, objectID string, queryT
 	}
 
 	if rallyResponse.StatusCode < 200 || rallyResponse.StatusCode >= 300 {
-		return parseRallyError(rallyResponse.StatusCode, content)
+		apiErr := parseRallyError(rallyResponse.StatusCode, content)
+		if isRetryableStatusCode(rallyResponse.StatusCode) {
+			apiErr.RetryAfter, _ = parseRetryAfter(rallyResponse.Header.Get("Retry-After"), s.clock.Now())
+		}
+		return apiErr
 	}
 
-	if err := json.Unmarshal(content, output); err != nil {
-		return fmt.Errorf("failed to unmarshal response: %w", err)
+	if err := checkJSONResponse(rallyResponse.Header.Get("Content-Type"), content); err != nil {
+		return err
 	}
 
-	return nil
-}
-
-func (s *RallyClient) DeleteRequest(ctx context.Context, objectID string, queryType string, output interface{}) error {
-	baseURL, err := url.Parse(strings.Join([]string{s.apiurl, queryType, objectID}, "/"))
-	if err != nil {
-		return fmt.Errorf("failed to parse URL: %w", err)
+	// Creates and updates can be rejected with a 2xx and a populated Errors array.
+	if method == "POST" {
+		if apiErr := parseSoftError(rallyResponse.StatusCode, content); apiErr != nil {
+			return apiErr
+		}
 	}
 
-	params := url.Values{}
-	params.Add("fetch", "true")
-	baseURL.RawQuery = params.Encode()
-
-	urlStr := baseURL.String()
-
-	req, err := http.NewRequestWithContext(ctx, "DELETE", urlStr, nil)
-	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
+	if output == nil {
+		return nil
 	}
-	req.Header.Add("ZSESSIONID", s.apikey)
-
-	rallyResponse, err := s.doWithRetry(req, nil)
-	if err != nil {
-		return fmt.Errorf("failed to execute request: %w", err)
+	if err := s.decode(content, output); err != nil {
+		return fmt.Errorf("failed to unmarshal response: %w", err)
 	}
-	defer rallyResponse.Body.Close()
 
-	content, err := io.ReadAll(rallyResponse.Body)
-	if err != nil {
-		return fmt.Errorf("failed to read response body: %w", err)
-	}
+	return nil
+}
 
-	if rallyResponse.StatusCode < 200 || rallyResponse.StatusCode >= 300 {
-		return parseRallyError(rallyResponse.StatusCode, content)
+// decode unmarshals a response body into output with the configured Decoder,
+// or json.Unmarshal if there is none, rejecting unknown fields if configured.
+func (s *RallyClient) decode(content []byte, output interface{}) error {
+	if s.config != nil && s.config.Decoder != nil {
+		return s.config.Decoder(bytes.NewReader(content), output)
 	}
-
-	if err := json.Unmarshal(content, output); err != nil {
-		return fmt.Errorf("failed to unmarshal response: %w", err)
+	if s.config != nil && s.config.DisallowUnknownFields {
+		decoder := json.NewDecoder(bytes.NewReader(content))
+		decoder.DisallowUnknownFields()
+		return decoder.Decode(output)
 	}
-
-	return nil
+	return json.Unmarshal(content, output)
 }
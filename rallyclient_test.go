@@ -21,6 +21,7 @@ import (
 	"context"
 	"errors"
 	"net/http"
+	"strings"
 	"testing"
 
 	. "github.com/aleksofficial/go-rally-rest-toolkit"
@@ -159,6 +160,112 @@ func TestUpdateRequest_ValidUpdateWithValidAPIKey(t *testing.T) {
 	}
 }
 
+func TestCreateRequest_SoftFailureOn200(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{
+		FakeResponse: &http.Response{
+			StatusCode: http.StatusOK,
+			Body: &fakes.FakeResponseBody{Reader: bytes.NewBufferString(
+				`{"CreateResult": {"Errors": ["Could not set value for Name: Cannot be null"], "Warnings": ["Ignored JSON element FakeItem.Foo"]}}`)},
+		},
+	}
+	rallyClient := New("abcdef", "http://myRallyUrl", fakeClient)
+
+	fakeOutput := new(fakes.FakeCreateResponse)
+	err := rallyClient.CreateRequest(context.Background(), "hierarchicalrequirement", &fakes.FakeCreateRequest{}, &fakeOutput)
+	if err == nil {
+		t.Fatal("expected an error for a 200 with CreateResult.Errors, got nil")
+	}
+
+	var apiErr *RallyAPIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("expected *RallyAPIError, got %T", err)
+	}
+	if apiErr.StatusCode != http.StatusOK {
+		t.Errorf("expected StatusCode=200, got %d", apiErr.StatusCode)
+	}
+	if len(apiErr.Errors) != 1 || apiErr.Errors[0] != "Could not set value for Name: Cannot be null" {
+		t.Errorf("unexpected Errors: %v", apiErr.Errors)
+	}
+	if len(apiErr.Warnings) != 1 {
+		t.Errorf("expected 1 warning, got %v", apiErr.Warnings)
+	}
+}
+
+func TestUpdateRequest_SoftFailureOn200(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{
+		FakeResponse: &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       &fakes.FakeResponseBody{Reader: bytes.NewBufferString(`{"OperationResult": {"Errors": ["Concurrency conflict"]}}`)},
+		},
+	}
+	rallyClient := New("abcdef", "http://myRallyUrl", fakeClient)
+
+	fakeOutput := new(fakes.FakeUpdateResponse)
+	err := rallyClient.UpdateRequest(context.Background(), "12345", "hierarchicalrequirement", &fakes.FakeCreateRequest{}, &fakeOutput)
+	if !errors.Is(err, ErrRallyAPI) {
+		t.Fatalf("expected a RallyAPIError, got %v", err)
+	}
+}
+
+func TestQueryRequest_HTMLLoginPageOn200(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{
+		FakeResponse: &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Content-Type": []string{"text/html; charset=utf-8"}},
+			Body:       &fakes.FakeResponseBody{Reader: bytes.NewBufferString(`<!DOCTYPE html><html><body>Sign in to Rally</body></html>`)},
+		},
+	}
+	rallyClient := New("abcdef", "http://myRallyUrl", fakeClient)
+
+	fakeOutput := new(fakes.FakeOutput)
+	err := rallyClient.QueryRequest(context.Background(), map[string]string{}, "defect", &fakeOutput)
+	if !errors.Is(err, ErrNonJSONResponse) {
+		t.Fatalf("expected ErrNonJSONResponse, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "text/html") || !strings.Contains(err.Error(), "API key") {
+		t.Errorf("expected the error to name the content type and hint at the API key, got %q", err)
+	}
+}
+
+func TestGetDefect_DisallowUnknownFields(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		wantErr bool
+	}{
+		{
+			"modeled fields",
+			`{"Defect": {"_rallyAPIMajor": "2", "_rallyAPIMinor": "0", "_ref": "https://rally1.rallydev.com/slm/webservice/v2.0/defect/1", "_refObjectName": "Crash", "ObjectID": 1, "Name": "Crash"}}`,
+			false,
+		},
+		{
+			"field Rally added",
+			`{"Defect": {"ObjectID": 1, "Name": "Crash", "RootCauseAnalysis": "Race"}}`,
+			true,
+		},
+	}
+
+	for _, tt := range tests {
+		fakeClient := &fakes.FakeHTTPClient{
+			FakeResponse: &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       &fakes.FakeResponseBody{Reader: bytes.NewBufferString(tt.body)},
+			},
+		}
+		rallyClient := New("abcdef", "http://myRallyUrl", fakeClient)
+		rallyClient.SetConfig(&Config{DisallowUnknownFields: true})
+
+		_, err := NewDefect(rallyClient).GetDefect(context.Background(), "1")
+		if tt.wantErr {
+			if err == nil || !strings.Contains(err.Error(), "RootCauseAnalysis") {
+				t.Errorf("%s: expected an unknown field error naming RootCauseAnalysis, got %v", tt.name, err)
+			}
+		} else if err != nil {
+			t.Errorf("%s: GetDefect failed unexpectedly: %v", tt.name, err)
+		}
+	}
+}
+
 func TestDeleteRequest_ValidDeleteWithValidAPIKey(t *testing.T) {
 	fakeClient := &fakes.FakeHTTPClient{
 		FakeResponse: &http.Response{
@@ -334,3 +441,230 @@ func TestQueryRequest_MaxRetriesExceeded(t *testing.T) {
 		t.Errorf("expected 4 calls (1 initial + 3 retries), got %d", fakeClient.CallCount)
 	}
 }
+
+func TestQueryRequest_RetryThenNonRetryableErrorClosesBodiesOnce(t *testing.T) {
+	// 503 is retried, then a non-retryable transport error ends the request.
+	// The 503 body must be closed exactly once and nothing else handed back.
+	unavailable := &fakes.FakeResponseBody{Reader: bytes.NewBufferString(`{}`)}
+	fakeClient := &fakes.FakeHTTPClient{
+		FakeResponses: []*http.Response{
+			{StatusCode: http.StatusServiceUnavailable, Body: unavailable},
+			nil,
+		},
+		FakeErrors: []error{
+			nil,
+			errors.New("x509: certificate signed by unknown authority"),
+		},
+	}
+
+	rallyClient := New("abcdef", "http://myRallyUrl", fakeClient)
+	rallyClient.SetConfig(&Config{
+		MaxRetries: 3,
+		RetryDelay: 1,
+	})
+
+	fakeOutput := new(fakes.FakeOutput)
+	err := rallyClient.QueryRequest(context.Background(), map[string]string{"FormattedID": "US624340"}, "hierarchicalrequirement", &fakeOutput)
+	if err == nil || !strings.Contains(err.Error(), "unknown authority") {
+		t.Fatalf("expected the non-retryable transport error, got %v", err)
+	}
+	if fakeClient.CallCount != 2 {
+		t.Errorf("expected 2 calls, got %d", fakeClient.CallCount)
+	}
+	if unavailable.CloseCount != 1 {
+		t.Errorf("expected the 503 body to be closed once, got %d", unavailable.CloseCount)
+	}
+}
+
+func TestQueryRequest_MixedRetriesCloseEveryBodyOnce(t *testing.T) {
+	// 500, transport timeout, 502, then success: every body is closed exactly
+	// once, including the successful one after it has been decoded.
+	bodies := []*fakes.FakeResponseBody{
+		{Reader: bytes.NewBufferString(`{}`)},
+		{Reader: bytes.NewBufferString(`{}`)},
+		{Reader: bytes.NewBufferString(`{"QueryResult": { "TotalResultCount": 1, "Results": [{"FakeValue": "fakeresponse"}]}}`)},
+	}
+	fakeClient := &fakes.FakeHTTPClient{
+		FakeResponses: []*http.Response{
+			{StatusCode: http.StatusInternalServerError, Body: bodies[0]},
+			nil,
+			{StatusCode: http.StatusBadGateway, Body: bodies[1]},
+			{StatusCode: http.StatusOK, Body: bodies[2]},
+		},
+		FakeErrors: []error{
+			nil,
+			errors.New("i/o timeout"),
+		},
+	}
+
+	rallyClient := New("abcdef", "http://myRallyUrl", fakeClient)
+	rallyClient.SetConfig(&Config{
+		MaxRetries: 3,
+		RetryDelay: 1,
+	})
+
+	fakeOutput := new(fakes.FakeOutput)
+	err := rallyClient.QueryRequest(context.Background(), map[string]string{"FormattedID": "US624340"}, "hierarchicalrequirement", &fakeOutput)
+	if err != nil {
+		t.Fatalf("QueryRequest should have succeeded after retries: %v", err)
+	}
+	if fakeClient.CallCount != 4 {
+		t.Errorf("expected 4 calls, got %d", fakeClient.CallCount)
+	}
+	for i, body := range bodies {
+		if body.CloseCount != 1 {
+			t.Errorf("body %d: expected 1 close, got %d", i, body.CloseCount)
+		}
+	}
+}
+
+func TestQueryRequest_ResponseWithErrorIsClosed(t *testing.T) {
+	// A ClientDoer that returns a response alongside an error must not leak it.
+	leaked := &fakes.FakeResponseBody{Reader: bytes.NewBufferString(`{}`)}
+	fakeClient := &fakes.FakeHTTPClient{
+		FakeResponses: []*http.Response{
+			{StatusCode: http.StatusFound, Body: leaked},
+		},
+		FakeErrors: []error{
+			errors.New("stopped after 10 redirects"),
+		},
+	}
+
+	rallyClient := New("abcdef", "http://myRallyUrl", fakeClient)
+	rallyClient.SetConfig(&Config{
+		MaxRetries: 3,
+		RetryDelay: 1,
+	})
+
+	fakeOutput := new(fakes.FakeOutput)
+	err := rallyClient.QueryRequest(context.Background(), map[string]string{"FormattedID": "US624340"}, "hierarchicalrequirement", &fakeOutput)
+	if err == nil {
+		t.Fatal("QueryRequest should have failed")
+	}
+	if leaked.CloseCount != 1 {
+		t.Errorf("expected the response body to be closed once, got %d", leaked.CloseCount)
+	}
+}
+
+func TestCreateRequest_NoRetryOnTransportErrorByDefault(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{
+		FakeResponses: []*http.Response{
+			nil,
+			{
+				StatusCode: http.StatusOK,
+				Body:       &fakes.FakeResponseBody{Reader: bytes.NewBufferString(`{"CreateResult": {"Object": {"ObjectID": 1}}}`)},
+			},
+		},
+		FakeErrors: []error{
+			errors.New("read tcp: i/o timeout"),
+			nil,
+		},
+	}
+
+	rallyClient := New("abcdef", "http://myRallyUrl", fakeClient)
+	rallyClient.SetConfig(&Config{
+		MaxRetries: 3,
+		RetryDelay: 1,
+	})
+
+	fakeInput := fakes.FakeCreateRequest{FakeItem: fakes.FakeItem{Field1: "fake"}}
+	fakeOutput := new(fakes.FakeCreateResponse)
+	err := rallyClient.CreateRequest(context.Background(), "defect", fakeInput, &fakeOutput)
+	if err == nil {
+		t.Fatal("CreateRequest should have failed without retrying")
+	}
+	if fakeClient.CallCount != 1 {
+		t.Errorf("expected 1 call (no retry of a create after a transport error), got %d", fakeClient.CallCount)
+	}
+}
+
+func TestCreateRequest_RetryOnTransportErrorWhenEnabled(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{
+		FakeResponses: []*http.Response{
+			nil,
+			{
+				StatusCode: http.StatusOK,
+				Body:       &fakes.FakeResponseBody{Reader: bytes.NewBufferString(`{"CreateResult": {"Object": {"ObjectID": 1}}}`)},
+			},
+		},
+		FakeErrors: []error{
+			errors.New("read tcp: i/o timeout"),
+			nil,
+		},
+	}
+
+	rallyClient := New("abcdef", "http://myRallyUrl", fakeClient)
+	rallyClient.SetConfig(&Config{
+		MaxRetries:                   3,
+		RetryDelay:                   1,
+		RetryCreatesOnTransportError: true,
+	})
+
+	fakeInput := fakes.FakeCreateRequest{FakeItem: fakes.FakeItem{Field1: "fake"}}
+	fakeOutput := new(fakes.FakeCreateResponse)
+	err := rallyClient.CreateRequest(context.Background(), "defect", fakeInput, &fakeOutput)
+	if err != nil {
+		t.Fatalf("CreateRequest should have succeeded after retry: %v", err)
+	}
+	if fakeClient.CallCount != 2 {
+		t.Errorf("expected 2 calls, got %d", fakeClient.CallCount)
+	}
+}
+
+func TestCreateRequest_RetryOn5xx(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{
+		FakeResponses: []*http.Response{
+			{StatusCode: http.StatusServiceUnavailable, Body: &fakes.FakeResponseBody{Reader: bytes.NewBufferString(`{}`)}},
+			{
+				StatusCode: http.StatusOK,
+				Body:       &fakes.FakeResponseBody{Reader: bytes.NewBufferString(`{"CreateResult": {"Object": {"ObjectID": 1}}}`)},
+			},
+		},
+	}
+
+	rallyClient := New("abcdef", "http://myRallyUrl", fakeClient)
+	rallyClient.SetConfig(&Config{
+		MaxRetries: 3,
+		RetryDelay: 1,
+	})
+
+	fakeInput := fakes.FakeCreateRequest{FakeItem: fakes.FakeItem{Field1: "fake"}}
+	fakeOutput := new(fakes.FakeCreateResponse)
+	if err := rallyClient.CreateRequest(context.Background(), "defect", fakeInput, &fakeOutput); err != nil {
+		t.Fatalf("CreateRequest should have succeeded after retrying a 503: %v", err)
+	}
+	if fakeClient.CallCount != 2 {
+		t.Errorf("expected 2 calls, got %d", fakeClient.CallCount)
+	}
+}
+
+func TestUpdateRequest_RetryOnTransportError(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{
+		FakeResponses: []*http.Response{
+			nil,
+			{
+				StatusCode: http.StatusOK,
+				Body:       &fakes.FakeResponseBody{Reader: bytes.NewBufferString(`{"OperationResult": {"Object": {"ObjectID": 1}}}`)},
+			},
+		},
+		FakeErrors: []error{
+			errors.New("connection reset by peer"),
+			nil,
+		},
+	}
+
+	rallyClient := New("abcdef", "http://myRallyUrl", fakeClient)
+	rallyClient.SetConfig(&Config{
+		MaxRetries: 3,
+		RetryDelay: 1,
+	})
+
+	fakeInput := fakes.FakeCreateRequest{FakeItem: fakes.FakeItem{Field1: "fake"}}
+	fakeOutput := new(fakes.FakeUpdateResponse)
+	if err := rallyClient.UpdateRequest(context.Background(), "1", "defect", fakeInput, &fakeOutput); err != nil {
+		t.Fatalf("UpdateRequest should have succeeded after retry: %v", err)
+	}
+	if fakeClient.CallCount != 2 {
+		t.Errorf("expected 2 calls (updates are idempotent), got %d", fakeClient.CallCount)
+	}
+}
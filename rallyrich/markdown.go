@@ -0,0 +1,177 @@
+/**
+* Copyright 2014 Comcast Cable Communications Management, LLC
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+* http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+// Package rallyrich converts Markdown to the HTML Rally stores in rich-text
+// fields such as Description and Notes.
+package rallyrich
+
+import (
+	"html"
+	"regexp"
+	"strings"
+)
+
+// AllowedTags are the only tags FromMarkdown emits, all of which Rally's
+// rich-text fields accept.
+var AllowedTags = []string{"p", "b", "i", "code", "pre", "ul", "ol", "li", "a"}
+
+var (
+	bulletItem   = regexp.MustCompile(`^\s*[-*+]\s+(.*)$`)
+	numberedItem = regexp.MustCompile(`^\s*\d+[.)]\s+(.*)$`)
+)
+
+// linkSchemes are the URL schemes FromMarkdown turns into links; other links
+// are rendered as their text.
+var linkSchemes = []string{"http://", "https://", "mailto:"}
+
+// FromMarkdown converts a safe subset of Markdown to Rally HTML, e.g.
+//
+//	defect.Description = rallyrich.FromMarkdown("Fails on **login**:\n\n- open `/login`\n- submit")
+//
+// It supports paragraphs, "-", "*" and "+" bullet lists, numbered lists,
+// fenced code blocks, **bold**, *italic*, `code` and [links](https://...).
+// Everything else, including raw HTML, is escaped and shown as text, so the
+// result only contains AllowedTags and is never rejected by Rally.
+func FromMarkdown(md string) string {
+	var out strings.Builder
+	var paragraph []string
+	listTag := ""
+
+	flushParagraph := func() {
+		if len(paragraph) > 0 {
+			out.WriteString("<p>" + inline(strings.Join(paragraph, " ")) + "</p>")
+			paragraph = nil
+		}
+	}
+	closeList := func() {
+		if listTag != "" {
+			out.WriteString("</" + listTag + ">")
+			listTag = ""
+		}
+	}
+	listItem := func(tag string, text string) {
+		flushParagraph()
+		if listTag != tag {
+			closeList()
+			out.WriteString("<" + tag + ">")
+			listTag = tag
+		}
+		out.WriteString("<li>" + inline(text) + "</li>")
+	}
+
+	lines := strings.Split(strings.ReplaceAll(md, "\r\n", "\n"), "\n")
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+
+		switch {
+		case strings.HasPrefix(trimmed, "```"):
+			flushParagraph()
+			closeList()
+			var code []string
+			for i++; i < len(lines) && !strings.HasPrefix(strings.TrimSpace(lines[i]), "```"); i++ {
+				code = append(code, lines[i])
+			}
+			out.WriteString("<pre><code>" + html.EscapeString(strings.Join(code, "\n")) + "</code></pre>")
+		case trimmed == "":
+			flushParagraph()
+			closeList()
+		case bulletItem.MatchString(line):
+			listItem("ul", bulletItem.FindStringSubmatch(line)[1])
+		case numberedItem.MatchString(line):
+			listItem("ol", numberedItem.FindStringSubmatch(line)[1])
+		default:
+			closeList()
+			paragraph = append(paragraph, trimmed)
+		}
+	}
+	flushParagraph()
+	closeList()
+	return out.String()
+}
+
+// inline converts the inline markup of one block of text, escaping the rest.
+func inline(text string) string {
+	var out strings.Builder
+	for len(text) > 0 {
+		switch {
+		case text[0] == '`':
+			if end := strings.IndexByte(text[1:], '`'); end >= 0 {
+				out.WriteString("<code>" + html.EscapeString(text[1:1+end]) + "</code>")
+				text = text[end+2:]
+				continue
+			}
+		case strings.HasPrefix(text, "**"):
+			if end := strings.Index(text[2:], "**"); end > 0 {
+				out.WriteString("<b>" + inline(text[2:2+end]) + "</b>")
+				text = text[end+4:]
+				continue
+			}
+		case text[0] == '*':
+			if end := strings.IndexByte(text[1:], '*'); end > 0 {
+				out.WriteString("<i>" + inline(text[1:1+end]) + "</i>")
+				text = text[end+2:]
+				continue
+			}
+		case text[0] == '[':
+			if label, target, rest, ok := cutLink(text); ok {
+				if hasLinkScheme(target) {
+					out.WriteString(`<a href="` + html.EscapeString(target) + `">` + inline(label) + "</a>")
+				} else {
+					out.WriteString(inline(label))
+				}
+				text = rest
+				continue
+			}
+		}
+		out.WriteString(html.EscapeString(text[:1]))
+		text = text[1:]
+	}
+	return out.String()
+}
+
+// cutLink splits text starting with "[label](target)" into its parts and the
+// text after it. Parentheses in target must be balanced.
+func cutLink(text string) (label string, target string, rest string, ok bool) {
+	mid := strings.Index(text, "](")
+	if mid < 0 {
+		return "", "", "", false
+	}
+	depth := 0
+	for i := mid + 2; i < len(text); i++ {
+		switch text[i] {
+		case '(':
+			depth++
+		case ')':
+			if depth == 0 {
+				return text[1:mid], strings.TrimSpace(text[mid+2 : i]), text[i+1:], true
+			}
+			depth--
+		}
+	}
+	return "", "", "", false
+}
+
+// hasLinkScheme reports whether target uses one of linkSchemes, ignoring case.
+func hasLinkScheme(target string) bool {
+	lower := strings.ToLower(target)
+	for _, scheme := range linkSchemes {
+		if strings.HasPrefix(lower, scheme) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,51 @@
+/**
+* Copyright 2014 Comcast Cable Communications Management, LLC
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+* http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package rallyrich_test
+
+import (
+	"testing"
+
+	. "github.com/aleksofficial/go-rally-rest-toolkit/rallyrich"
+)
+
+func TestFromMarkdown(t *testing.T) {
+	tests := []struct {
+		name     string
+		md       string
+		expected string
+	}{
+		{"paragraphs", "First line\nsame paragraph\n\nSecond", "<p>First line same paragraph</p><p>Second</p>"},
+		{"bold and italic", "a **bold** and *italic* word", "<p>a <b>bold</b> and <i>italic</i> word</p>"},
+		{"inline code", "run `go test ./...`", "<p>run <code>go test ./...</code></p>"},
+		{"link", "see [the docs](https://example.com/a?b=1&c=2)", `<p>see <a href="https://example.com/a?b=1&amp;c=2">the docs</a></p>`},
+		{"unsafe link", "[click](javascript:alert(1))", "<p>click</p>"},
+		{"bullet list", "Steps:\n- open\n* submit **form**", "<p>Steps:</p><ul><li>open</li><li>submit <b>form</b></li></ul>"},
+		{"numbered list", "1. one\n2) two\n\nafter", "<ol><li>one</li><li>two</li></ol><p>after</p>"},
+		{"list type change", "- a\n1. b", "<ul><li>a</li></ul><ol><li>b</li></ol>"},
+		{"code block", "```go\nif a < b {\n\t**x**\n}\n```", "<pre><code>if a &lt; b {\n\t**x**\n}</code></pre>"},
+		{"raw html escaped", `<script>alert("x")</script> & <b>`, "<p>&lt;script&gt;alert(&#34;x&#34;)&lt;/script&gt; &amp; &lt;b&gt;</p>"},
+		{"unclosed markup", "2 * 3 and `tick", "<p>2 * 3 and `tick</p>"},
+		{"windows line endings", "a\r\n\r\nb", "<p>a</p><p>b</p>"},
+		{"empty", "", ""},
+	}
+
+	for _, tt := range tests {
+		if got := FromMarkdown(tt.md); got != tt.expected {
+			t.Errorf("%s: expected %q, got %q", tt.name, tt.expected, got)
+		}
+	}
+}
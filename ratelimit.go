@@ -0,0 +1,140 @@
+/**
+* Copyright 2014 Comcast Cable Communications Management, LLC
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+* http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package rallyresttoolkit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// RateLimiter gates each request attempt, including retries. Wait blocks
+// until the attempt may proceed, or returns an error once ctx is done.
+// *rate.Limiter from golang.org/x/time/rate satisfies it.
+type RateLimiter interface {
+	Wait(ctx context.Context) error
+}
+
+// applyConfigRateLimit builds the limiter from Config.RequestsPerSecond and
+// Burst, or removes it when they are unset, unless the limiter was set with
+// WithRateLimit or WithRateLimiter.
+func (s *RallyClient) applyConfigRateLimit() {
+	if s.limiter != nil && !s.limiterFromConfig {
+		return
+	}
+	s.limiter, s.limiterFromConfig = nil, false
+	if s.config == nil || s.config.RequestsPerSecond <= 0 {
+		return
+	}
+
+	burst := s.config.Burst
+	if burst < 1 {
+		burst = 1
+	}
+	bucket := newTokenBucket(s.config.RequestsPerSecond, burst)
+	if s.clock != nil {
+		bucket.clock = s.clock
+	}
+	s.limiter, s.limiterFromConfig = bucket, true
+}
+
+// tokenBucket is a simple token bucket rate limiter. Tokens refill continuously
+// at rate per second up to burst.
+type tokenBucket struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+	clock  Clock
+}
+
+// newTokenBucket returns a full bucket. The refill clock starts on first use.
+func newTokenBucket(rate float64, burst int) *tokenBucket {
+	return &tokenBucket{
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		clock:  realClock{},
+	}
+}
+
+// Wait blocks until a token is available or the context is done.
+func (b *tokenBucket) Wait(ctx context.Context) error {
+	for {
+		b.mu.Lock()
+		b.refill(b.clock.Now())
+
+		if b.tokens >= 1 {
+			b.tokens--
+			b.mu.Unlock()
+			return nil
+		}
+		wait := time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+		b.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-b.clock.After(wait):
+		}
+	}
+}
+
+// tryTake takes a token if one is available at now, without waiting.
+func (b *tokenBucket) tryTake(now time.Time) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.refill(now)
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// refill adds the tokens accrued since the last call. b.mu must be held.
+func (b *tokenBucket) refill(now time.Time) {
+	if b.last.IsZero() {
+		b.last = now
+	}
+	b.tokens += now.Sub(b.last).Seconds() * b.rate
+	if b.tokens > b.burst {
+		b.tokens = b.burst
+	}
+	b.last = now
+}
+
+// RetryBudget caps retries across every request that shares it: each retry
+// spends a token, tokens refill at a steady rate, and when none are left
+// requests fail without retrying. This keeps a broad outage from multiplying
+// the load of a whole job by MaxRetries. It is safe for concurrent use and
+// can be shared by several clients.
+type RetryBudget struct {
+	bucket *tokenBucket
+}
+
+// NewRetryBudget returns a full budget of max retries that refills at
+// refillPerSecond retries per second.
+func NewRetryBudget(max int, refillPerSecond float64) *RetryBudget {
+	return &RetryBudget{bucket: newTokenBucket(refillPerSecond, max)}
+}
+
+// allow spends a retry token if one is left at now.
+func (r *RetryBudget) allow(now time.Time) bool {
+	return r.bucket.tryTake(now)
+}
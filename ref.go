@@ -0,0 +1,109 @@
+/**
+* Copyright 2014 Comcast Cable Communications Management, LLC
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+* http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package rallyresttoolkit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// ErrInvalidRef is returned when a _ref cannot be split into a type and an ID.
+var ErrInvalidRef = errors.New("invalid _ref")
+
+// parseRef splits a _ref such as
+// "https://rally1.rallydev.com/slm/webservice/v2.0/portfolioitem/feature/123"
+// or "/user/123" into its type path ("portfolioitem/feature", "user") and ID.
+func parseRef(ref string) (queryType string, objectID string, err error) {
+	segments, err := refSegments(ref)
+	if err != nil {
+		return "", "", err
+	}
+	if len(segments) < 2 || segments[0] == "" {
+		return "", "", fmt.Errorf("%w %q: expected <type>/<id>", ErrInvalidRef, ref)
+	}
+	objectID = strings.TrimSuffix(segments[len(segments)-1], ".js")
+	if objectID == "" {
+		return "", "", fmt.Errorf("%w %q: missing id", ErrInvalidRef, ref)
+	}
+	return strings.Join(segments[:len(segments)-1], "/"), objectID, nil
+}
+
+// refSegments returns the path segments of a _ref after the WSAPI prefix, e.g.
+// ["hierarchicalrequirement", "123", "Tasks"].
+func refSegments(ref string) ([]string, error) {
+	u, err := url.Parse(ref)
+	if err != nil {
+		return nil, fmt.Errorf("%w %q: %v", ErrInvalidRef, ref, err)
+	}
+
+	path := u.Path
+	if _, rest, ok := strings.Cut(path, "/webservice/"); ok {
+		// Drop the WSAPI version segment, e.g. "v2.0/".
+		_, path, _ = strings.Cut(rest, "/")
+	}
+	return strings.Split(strings.Trim(path, "/"), "/"), nil
+}
+
+// GetByRef fetches the object a _ref points at, such as the Owner of a story,
+// into a typed model, e.g. GetByRef[models.User](ctx, client, owner.Ref). The
+// request always goes to the client's base URL; only the type and ID are
+// taken from the ref.
+func GetByRef[T any](ctx context.Context, client *RallyClient, ref string) (T, error) {
+	queryType, objectID, err := parseRef(ref)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return Get[T](ctx, client, queryType, "", objectID)
+}
+
+// singleObject returns the object in a single-object response envelope such as
+// {"HierarchicalRequirement": {...}}. The key is matched against the type's
+// canonical name, then its last path segment, ignoring case, so lower-cased
+// refs like ".../hierarchicalrequirement/1" still find it. An abstract type
+// such as "portfolioitem" is keyed by the object's concrete type, e.g.
+// "Feature", so a lone object under any key is taken as the match.
+func singleObject(envelope map[string]json.RawMessage, queryType string) (json.RawMessage, error) {
+	candidates := []string{queryType[strings.LastIndex(queryType, "/")+1:]}
+	if e, ok := lookupEntity(queryType); ok {
+		candidates = append([]string{e.name}, candidates...)
+	}
+
+	for _, candidate := range candidates {
+		for key, body := range envelope {
+			if strings.EqualFold(key, candidate) {
+				return body, nil
+			}
+		}
+	}
+	if len(envelope) == 1 && isAbstractType(queryType) {
+		for _, body := range envelope {
+			return body, nil
+		}
+	}
+	return nil, fmt.Errorf("response has no %s object", queryType)
+}
+
+// isAbstractType reports whether queryType names a type whose objects are
+// returned under their concrete type's name.
+func isAbstractType(queryType string) bool {
+	return strings.EqualFold(queryType, "portfolioitem") || strings.EqualFold(queryType, "artifact")
+}
@@ -0,0 +1,107 @@
+/**
+* Copyright 2014 Comcast Cable Communications Management, LLC
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+* http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package rallyresttoolkit_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	. "github.com/aleksofficial/go-rally-rest-toolkit"
+	"github.com/aleksofficial/go-rally-rest-toolkit/fakes"
+	"github.com/aleksofficial/go-rally-rest-toolkit/models"
+)
+
+func refResponse(body string) *fakes.FakeHTTPClient {
+	return &fakes.FakeHTTPClient{
+		FakeResponse: &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       &fakes.FakeResponseBody{Reader: bytes.NewBufferString(body)},
+		},
+	}
+}
+
+func TestGetByRef_User(t *testing.T) {
+	fakeClient := refResponse(`{"User": {"ObjectID": 123, "UserName": "jdoe@example.com"}}`)
+	rallyClient := New("abcdef", "http://myRallyUrl", fakeClient)
+
+	user, err := GetByRef[models.User](context.Background(), rallyClient, "https://rally1.rallydev.com/slm/webservice/v2.0/user/123")
+	if err != nil {
+		t.Fatalf("GetByRef failed unexpectedly: %v", err)
+	}
+	if user.ObjectID != 123 || user.UserName != "jdoe@example.com" {
+		t.Errorf("unexpected user: %+v", user)
+	}
+	if got := fakeClient.SpyRequest.URL.String(); got != "http://myRallyUrl/user/123?fetch=true" {
+		t.Errorf("expected the request to go to the client's base URL, got %s", got)
+	}
+}
+
+func TestGetByRef_EnvelopeCasing(t *testing.T) {
+	fakeClient := refResponse(`{"HierarchicalRequirement": {"ObjectID": 29227987232, "FormattedID": "US624340"}}`)
+	rallyClient := New("abcdef", "http://myRallyUrl", fakeClient)
+
+	story, err := GetByRef[models.HierarchicalRequirement](context.Background(), rallyClient, "/hierarchicalrequirement/29227987232")
+	if err != nil {
+		t.Fatalf("GetByRef failed unexpectedly: %v", err)
+	}
+	if story.FormattedID != "US624340" {
+		t.Errorf("expected FormattedID=US624340, got %q", story.FormattedID)
+	}
+}
+
+func TestGetByRef_PortfolioItemType(t *testing.T) {
+	fakeClient := refResponse(`{"Feature": {"ObjectID": 42, "Name": "Single sign-on"}}`)
+	rallyClient := New("abcdef", "http://myRallyUrl", fakeClient)
+
+	feature, err := GetByRef[models.PortfolioItem](context.Background(), rallyClient, "https://rally1.rallydev.com/slm/webservice/v2.0/PortfolioItem/Feature/42")
+	if err != nil {
+		t.Fatalf("GetByRef failed unexpectedly: %v", err)
+	}
+	if feature.Name != "Single sign-on" {
+		t.Errorf("unexpected feature: %+v", feature)
+	}
+	if got := fakeClient.SpyRequest.URL.Path; got != "/PortfolioItem/Feature/42" {
+		t.Errorf("unexpected path: %s", got)
+	}
+}
+
+func TestGetByRef_InvalidRef(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{}
+	rallyClient := New("abcdef", "http://myRallyUrl", fakeClient)
+
+	for _, ref := range []string{"", "123", "https://rally1.rallydev.com/slm/webservice/v2.0/"} {
+		_, err := GetByRef[models.User](context.Background(), rallyClient, ref)
+		if !errors.Is(err, ErrInvalidRef) {
+			t.Errorf("ref %q: expected ErrInvalidRef, got %v", ref, err)
+		}
+	}
+	if fakeClient.CallCount != 0 {
+		t.Errorf("expected no requests for invalid refs, got %d", fakeClient.CallCount)
+	}
+}
+
+func TestGetByRef_MissingEnvelope(t *testing.T) {
+	fakeClient := refResponse(`{"Defect": {"ObjectID": 1}}`)
+	rallyClient := New("abcdef", "http://myRallyUrl", fakeClient)
+
+	if _, err := GetByRef[models.User](context.Background(), rallyClient, "/user/1"); err == nil {
+		t.Error("expected an error when the response has no User object")
+	}
+}
@@ -0,0 +1,120 @@
+/**
+* Copyright 2014 Comcast Cable Communications Management, LLC
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+* http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package rallyresttoolkit
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/aleksofficial/go-rally-rest-toolkit/models"
+)
+
+// Release - struct to hold client
+type Release struct {
+	client *RallyClient
+}
+
+// QueryReleaseResponse - struct to contain query response
+type QueryReleaseResponse = models.QueryResponse[models.Release]
+
+// GetReleaseResponse - Struct to contain response
+type GetReleaseResponse struct {
+	Release models.Release
+}
+
+type CreateReleaseResponse struct {
+	CreateResult releaseResult
+}
+
+type releaseResult struct {
+	Object models.Release
+}
+
+// OperationResponse - struct to contain response
+type releaseOperationResponse = models.OperationResponse[models.Release]
+
+// NewRelease - creates new Release
+func NewRelease(client *RallyClient) (de *Release) {
+	return &Release{
+		client: client,
+	}
+}
+
+// QueryRelease - abstraction for QueryRequest
+func (s *Release) QueryRelease(ctx context.Context, query map[string]string) (des []models.Release, err error) {
+	qdes := new(QueryReleaseResponse)
+	err = s.client.QueryRequest(ctx, query, "release", &qdes)
+	return qdes.QueryResult.Results, err
+}
+
+// QueryReleaseWithMeta - QueryRequest that also returns the envelope's paging metadata and Warnings
+func (s *Release) QueryReleaseWithMeta(ctx context.Context, query map[string]string) ([]models.Release, QueryMeta, error) {
+	return Query[models.Release](ctx, s.client, "release", query, QueryOptions{})
+}
+
+// QueryReleasePage - abstraction for QueryRequestWithOptions that keeps the paging metadata
+func (s *Release) QueryReleasePage(ctx context.Context, query map[string]string, opts QueryOptions) (Page[models.Release], error) {
+	return queryPage[models.Release](ctx, s.client, query, "release", opts)
+}
+
+// QueryReleaseRaw - abstraction for QueryRequestRaw, for query clauses written by hand
+func (s *Release) QueryReleaseRaw(ctx context.Context, rawQuery string, opts QueryOptions) (Page[models.Release], error) {
+	return queryRawPage[models.Release](ctx, s.client, rawQuery, "release", opts)
+}
+
+// QueryReleaseWith - abstraction for QueryRequestWith
+func (s *Release) QueryReleaseWith(ctx context.Context, b *QueryBuilder) (Page[models.Release], error) {
+	return queryWithPage[models.Release](ctx, s.client, b, "release")
+}
+
+// GetRelease - abstraction for GetRequest
+func (s *Release) GetRelease(ctx context.Context, objectID string) (de models.Release, err error) {
+	gde := new(GetReleaseResponse)
+	err = s.client.GetRequest(ctx, objectID, "release", &gde)
+	return gde.Release, err
+}
+
+// CreateRelease - abstraction for CreateRequest
+func (s *Release) CreateRelease(ctx context.Context, release models.Release) (der models.Release, err error) {
+	createRequest, err := writeRequest("Release", release)
+	if err != nil {
+		return der, err
+	}
+	ude := new(CreateReleaseResponse)
+	err = s.client.CreateRequest(ctx, "release", createRequest, &ude)
+	der = ude.CreateResult.Object
+	return der, err
+}
+
+// UpdateRelease - abstraction for UpdateRequest
+func (s *Release) UpdateRelease(ctx context.Context, release models.Release) (releaser models.Release, err error) {
+	updateRequest, err := writeRequest("Release", release)
+	if err != nil {
+		return releaser, err
+	}
+	ude := new(releaseOperationResponse)
+	err = s.client.UpdateRequest(ctx, strconv.Itoa(release.ObjectID), "release", updateRequest, &ude)
+	releaser = ude.OperationalResult.Object
+	return releaser, err
+}
+
+// DeleteRelease - abstraction for DeleteRequest
+func (s *Release) DeleteRelease(ctx context.Context, objectID string) (err error) {
+	ude := new(deOperationResponse)
+	err = s.client.DeleteRequest(ctx, objectID, "release", &ude)
+	return err
+}
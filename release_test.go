@@ -0,0 +1,135 @@
+/**
+* Copyright 2014 Comcast Cable Communications Management, LLC
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+* http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package rallyresttoolkit_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	. "github.com/aleksofficial/go-rally-rest-toolkit"
+	"github.com/aleksofficial/go-rally-rest-toolkit/fakes"
+	"github.com/aleksofficial/go-rally-rest-toolkit/models"
+)
+
+func TestGetRelease_ScheduleFields(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{
+		FakeResponse: &http.Response{
+			StatusCode: http.StatusOK,
+			Body: &fakes.FakeResponseBody{Reader: bytes.NewBufferString(`{"Release": {
+				"ObjectID": 80000000001,
+				"Name": "2016.Q2",
+				"ReleaseStartDate": "2016-04-01T00:00:00.000Z",
+				"ReleaseDate": "2016-06-30T23:59:59.000Z",
+				"State": "Active",
+				"Theme": "Payments",
+				"PlannedVelocity": 120,
+				"Version": "4.2",
+				"GrossEstimateConversionRatio": 1.5
+			}}`)},
+		},
+	}
+
+	rallyClient := New("abcdef", "http://myRallyUrl", fakeClient)
+	releaseClient := NewRelease(rallyClient)
+
+	release, err := releaseClient.GetRelease(context.Background(), "80000000001")
+	if err != nil {
+		t.Fatalf("GetRelease failed unexpectedly: %v", err)
+	}
+	if !release.ReleaseStartDate.Equal(time.Date(2016, 4, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected ReleaseStartDate: %v", release.ReleaseStartDate)
+	}
+	if !release.ReleaseDate.Equal(time.Date(2016, 6, 30, 23, 59, 59, 0, time.UTC)) {
+		t.Errorf("unexpected ReleaseDate: %v", release.ReleaseDate)
+	}
+	if release.State != "Active" || release.Theme != "Payments" || release.Version != "4.2" {
+		t.Errorf("unexpected State/Theme/Version: %q/%q/%q", release.State, release.Theme, release.Version)
+	}
+	if release.PlannedVelocity != 120 || release.GrossEstimateConversionRatio != 1.5 {
+		t.Errorf("unexpected PlannedVelocity/GrossEstimateConversionRatio: %v/%v", release.PlannedVelocity, release.GrossEstimateConversionRatio)
+	}
+}
+
+func TestUpdateRelease_OnlyReleaseDate(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{
+		FakeResponse: &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       &fakes.FakeResponseBody{Reader: bytes.NewBufferString(`{"OperationalResult": {"Object": {"ObjectID": 80000000001, "ReleaseDate": "2016-07-15T23:59:59.000Z"}}}`)},
+		},
+	}
+
+	rallyClient := New("abcdef", "http://myRallyUrl", fakeClient)
+	releaseClient := NewRelease(rallyClient)
+
+	slip := models.Release{
+		PersistableObject: models.PersistableObject{ObjectID: 80000000001},
+		ReleaseDate:       models.NewTime(time.Date(2016, 7, 15, 23, 59, 59, 0, time.UTC)),
+	}
+	result, err := releaseClient.UpdateRelease(context.Background(), slip)
+	if err != nil {
+		t.Fatalf("UpdateRelease failed unexpectedly: %v", err)
+	}
+	if !result.ReleaseDate.Equal(time.Date(2016, 7, 15, 23, 59, 59, 0, time.UTC)) {
+		t.Errorf("unexpected ReleaseDate: %v", result.ReleaseDate)
+	}
+
+	var body struct {
+		Release map[string]json.RawMessage
+	}
+	if err := json.NewDecoder(fakeClient.SpyRequest.Body).Decode(&body); err != nil {
+		t.Fatalf("failed to decode request body: %v", err)
+	}
+	if len(body.Release) != 1 {
+		t.Errorf("expected only ReleaseDate to be sent, got %v", body.Release)
+	}
+	if got := string(body.Release["ReleaseDate"]); got != `"2016-07-15T23:59:59.000Z"` {
+		t.Errorf("unexpected ReleaseDate: %s", got)
+	}
+}
+
+func TestCreateRelease_ValidRequest(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{
+		FakeResponse: &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       &fakes.FakeResponseBody{Reader: bytes.NewBufferString(`{"CreateResult": {"Object": {"ObjectID": 80000000001, "Name": "2016.Q2"}}}`)},
+		},
+	}
+
+	rallyClient := New("abcdef", "http://myRallyUrl", fakeClient)
+	releaseClient := NewRelease(rallyClient)
+
+	newRelease := models.Release{
+		Name:             "2016.Q2",
+		ReleaseStartDate: models.NewTime(time.Date(2016, 4, 1, 0, 0, 0, 0, time.UTC)),
+		ReleaseDate:      models.NewTime(time.Date(2016, 6, 30, 23, 59, 59, 0, time.UTC)),
+		State:            "Planning",
+	}
+	result, err := releaseClient.CreateRelease(context.Background(), newRelease)
+	if err != nil {
+		t.Fatalf("CreateRelease failed unexpectedly: %v", err)
+	}
+	if result.ObjectID != 80000000001 {
+		t.Errorf("expected ObjectID=80000000001, got %d", result.ObjectID)
+	}
+	if got := fakeClient.SpyRequest.URL.Path; got != "/release/create" {
+		t.Errorf("unexpected create path: %s", got)
+	}
+}
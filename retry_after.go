@@ -0,0 +1,64 @@
+/**
+* Copyright 2014 Comcast Cable Communications Management, LLC
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+* http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package rallyresttoolkit
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// retryAfter returns the delay a response's Retry-After header asks for,
+// capped at Config.MaxRetryAfter, and whether it had a usable one.
+func (s *RallyClient) retryAfter(resp *http.Response) (time.Duration, bool) {
+	delay, ok := parseRetryAfter(resp.Header.Get("Retry-After"), s.clock.Now())
+	if !ok {
+		return 0, false
+	}
+
+	maxRetryAfter := DefaultMaxRetryAfter
+	if s.config != nil && s.config.MaxRetryAfter > 0 {
+		maxRetryAfter = s.config.MaxRetryAfter
+	}
+	if max := time.Duration(maxRetryAfter) * time.Millisecond; delay > max {
+		delay = max
+	}
+	return delay, true
+}
+
+// parseRetryAfter parses a Retry-After header, either delay-seconds or an
+// HTTP-date, into a delay from now. A date in the past means no delay.
+func parseRetryAfter(header string, now time.Time) (time.Duration, bool) {
+	header = strings.TrimSpace(header)
+	if header == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(header); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(header); err == nil {
+		if delay := date.Sub(now); delay > 0 {
+			return delay, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
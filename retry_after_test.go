@@ -0,0 +1,133 @@
+/**
+* Copyright 2014 Comcast Cable Communications Management, LLC
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+* http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package rallyresttoolkit_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/aleksofficial/go-rally-rest-toolkit"
+	"github.com/aleksofficial/go-rally-rest-toolkit/fakes"
+)
+
+func throttledResponse(retryAfter string) *http.Response {
+	header := http.Header{}
+	if retryAfter != "" {
+		header.Set("Retry-After", retryAfter)
+	}
+	return &http.Response{
+		StatusCode: http.StatusTooManyRequests,
+		Header:     header,
+		Body:       &fakes.FakeResponseBody{Reader: bytes.NewBufferString(`{}`)},
+	}
+}
+
+func TestRetryAfter_Seconds(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{
+		FakeResponses: []*http.Response{throttledResponse("2"), okResponse()},
+	}
+	rallyClient, clock := newFakeClockClient(t, fakeClient)
+
+	fakeOutput := new(fakes.FakeOutput)
+	if err := rallyClient.GetRequest(context.Background(), "12345", "defect", &fakeOutput); err != nil {
+		t.Fatalf("GetRequest should have succeeded after the retry: %v", err)
+	}
+	if !reflect.DeepEqual(clock.Sleeps, []time.Duration{2 * time.Second}) {
+		t.Errorf("expected to wait the 2s Retry-After, got %v", clock.Sleeps)
+	}
+}
+
+func TestRetryAfter_HTTPDate(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{}
+	rallyClient, clock := newFakeClockClient(t, fakeClient)
+	retryAt := clock.Current.Add(5 * time.Second).Format(http.TimeFormat)
+	fakeClient.FakeResponses = []*http.Response{throttledResponse(retryAt), okResponse()}
+
+	fakeOutput := new(fakes.FakeOutput)
+	if err := rallyClient.GetRequest(context.Background(), "12345", "defect", &fakeOutput); err != nil {
+		t.Fatalf("GetRequest should have succeeded after the retry: %v", err)
+	}
+	if !reflect.DeepEqual(clock.Sleeps, []time.Duration{5 * time.Second}) {
+		t.Errorf("expected to wait until the Retry-After date, got %v", clock.Sleeps)
+	}
+}
+
+func TestRetryAfter_Capped(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{
+		FakeResponses: []*http.Response{throttledResponse("3600"), okResponse()},
+	}
+	rallyClient, clock := newFakeClockClient(t, fakeClient, WithMaxRetryAfter(10*time.Second))
+
+	fakeOutput := new(fakes.FakeOutput)
+	if err := rallyClient.GetRequest(context.Background(), "12345", "defect", &fakeOutput); err != nil {
+		t.Fatalf("GetRequest should have succeeded after the retry: %v", err)
+	}
+	if !reflect.DeepEqual(clock.Sleeps, []time.Duration{10 * time.Second}) {
+		t.Errorf("expected the wait capped at 10s, got %v", clock.Sleeps)
+	}
+}
+
+func TestRetryAfter_MissingOrInvalidUsesBackoff(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{
+		FakeResponses: []*http.Response{throttledResponse(""), throttledResponse("soon"), okResponse()},
+	}
+	rallyClient, clock := newFakeClockClient(t, fakeClient)
+
+	fakeOutput := new(fakes.FakeOutput)
+	if err := rallyClient.GetRequest(context.Background(), "12345", "defect", &fakeOutput); err != nil {
+		t.Fatalf("GetRequest should have succeeded after the retries: %v", err)
+	}
+	expected := []time.Duration{time.Second, 2 * time.Second}
+	if !reflect.DeepEqual(clock.Sleeps, expected) {
+		t.Errorf("expected the computed backoff %v, got %v", expected, clock.Sleeps)
+	}
+}
+
+func TestRetryAfter_ExhaustedReportsThrottle(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{
+		FakeResponses: []*http.Response{throttledResponse("2"), throttledResponse("2"), throttledResponse("2")},
+	}
+	rallyClient, _ := newFakeClockClient(t, fakeClient)
+
+	fakeOutput := new(fakes.FakeOutput)
+	err := rallyClient.GetRequest(context.Background(), "12345", "defect", &fakeOutput)
+	if !errors.Is(err, ErrThrottled) {
+		t.Fatalf("expected ErrThrottled, got %v", err)
+	}
+	var apiErr *RallyAPIError
+	if !errors.As(err, &apiErr) || apiErr.RetryAfter != 2*time.Second {
+		t.Errorf("expected RetryAfter=2s on the error, got %+v", apiErr)
+	}
+	if !strings.Contains(err.Error(), "retry after 2s") {
+		t.Errorf("expected the Retry-After in the message, got %q", err.Error())
+	}
+	if fakeClient.CallCount != 3 {
+		t.Errorf("expected 3 calls, got %d", fakeClient.CallCount)
+	}
+}
+
+func TestWithMaxRetryAfter_Invalid(t *testing.T) {
+	if _, err := NewClient(WithMaxRetryAfter(0)); err == nil {
+		t.Error("expected an error for a zero max retry after")
+	}
+}
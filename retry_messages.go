@@ -0,0 +1,135 @@
+/**
+* Copyright 2014 Comcast Cable Communications Management, LLC
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+* http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package rallyresttoolkit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/aleksofficial/go-rally-rest-toolkit/models"
+)
+
+// executeRetryingMessages runs executeOnce, retrying Rally errors that match
+// Config.RetryableErrorMessages with the usual retry limit, budget and
+// delays. refresh, if not nil, runs before each such retry and returns the
+// body to resend; updates use it to re-read the object they are about to
+// modify.
+func (s *RallyClient) executeRetryingMessages(ctx context.Context, method string, u *url.URL, body []byte, output interface{}, refresh func(context.Context, []byte) ([]byte, error)) error {
+	maxRetries := s.maxRetries(ctx)
+	for attempt := 0; ; attempt++ {
+		err := s.executeOnce(ctx, method, u, body, output)
+		if err == nil || !s.isRetryableMessage(err) || attempt == maxRetries || !s.retryAllowed(method, u.Path) {
+			return err
+		}
+
+		delay := s.retryDelay(ctx, attempt)
+		if s.logger != nil {
+			s.logger.Printf("rally: retrying %s %s in %v (retry %d of %d): %v", method, u.Path, delay, attempt+1, maxRetries, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("context cancelled after %d retries: %w", attempt, ctx.Err())
+		case <-s.closed:
+			return fmt.Errorf("%w after %d retries", ErrClientClosed, attempt)
+		case <-s.clock.After(delay):
+		}
+
+		if refresh != nil {
+			refreshed, refreshErr := refresh(ctx, body)
+			if refreshErr != nil {
+				return fmt.Errorf("failed to re-read before retrying %v: %w", err, refreshErr)
+			}
+			body = refreshed
+		}
+	}
+}
+
+// isRetryableMessage reports whether err is a *RallyAPIError with an error
+// containing one of Config.RetryableErrorMessages, ignoring case. Errors with
+// a retryable status have already been through doWithRetry and are not
+// retried again.
+func (s *RallyClient) isRetryableMessage(err error) bool {
+	if s.config == nil || len(s.config.RetryableErrorMessages) == 0 {
+		return false
+	}
+	var apiErr *RallyAPIError
+	if !errors.As(err, &apiErr) || isRetryableStatusCode(apiErr.StatusCode) {
+		return false
+	}
+	for _, message := range apiErr.Errors {
+		message = strings.ToLower(message)
+		for _, retryable := range s.config.RetryableErrorMessages {
+			if retryable != "" && strings.Contains(message, strings.ToLower(retryable)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// refreshVersion re-reads an object before a retried update and, if the
+// update body carries a VersionId, replaces it with the object's current one
+// so the retry is checked against the fresh read rather than the one that
+// conflicted. Other bodies are resent as they were. The read does not record
+// into the request's CallStats, which describe the update itself.
+func (s *RallyClient) refreshVersion(ctx context.Context, queryType string, objectID string, body []byte) ([]byte, error) {
+	baseURL, err := s.endpoint(queryType, objectID)
+	if err != nil {
+		return nil, err
+	}
+	params := url.Values{}
+	params.Add("fetch", "ObjectID,VersionId")
+	baseURL.RawQuery = params.Encode()
+
+	var envelope map[string]json.RawMessage
+	if err := s.executeOnce(WithCallStats(ctx, nil), "GET", baseURL, nil, &envelope); err != nil {
+		return nil, err
+	}
+	raw, err := singleObject(envelope, queryType)
+	if err != nil {
+		return nil, err
+	}
+	var current models.PersistableObject
+	if err := json.Unmarshal(raw, &current); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	var request map[string]map[string]json.RawMessage
+	if json.Unmarshal(body, &request) != nil {
+		return body, nil
+	}
+	for _, fields := range request {
+		if _, ok := fields["VersionId"]; !ok {
+			continue
+		}
+		fields["VersionId"], err = json.Marshal(current.VersionId)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal request body: %w", err)
+		}
+		refreshed, err := json.Marshal(request)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal request body: %w", err)
+		}
+		return refreshed, nil
+	}
+	return body, nil
+}
@@ -0,0 +1,210 @@
+/**
+* Copyright 2014 Comcast Cable Communications Management, LLC
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+* http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package rallyresttoolkit_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	. "github.com/aleksofficial/go-rally-rest-toolkit"
+	"github.com/aleksofficial/go-rally-rest-toolkit/fakes"
+)
+
+const conflictBody = `{"OperationResult": {"Errors": ["Concurrency conflict: [Object has been modified since being read for update in this context]"]}}`
+
+// scriptedDoer answers requests with bodies in turn, recording each request as
+// "METHOD path" and the bodies it sent.
+type scriptedDoer struct {
+	statuses []int
+	bodies   []string
+	calls    []string
+	sent     []string
+}
+
+func (d *scriptedDoer) Do(req *http.Request) (*http.Response, error) {
+	i := len(d.calls)
+	d.calls = append(d.calls, req.Method+" "+req.URL.Path)
+	if req.Body != nil {
+		sent, _ := io.ReadAll(req.Body)
+		d.sent = append(d.sent, string(sent))
+	}
+	if i >= len(d.bodies) {
+		i = len(d.bodies) - 1
+	}
+	return &http.Response{
+		StatusCode: d.statuses[i],
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(bytes.NewBufferString(d.bodies[i])),
+	}, nil
+}
+
+// newFakeClockClient returns a client sending through doer that retries
+// twice, starting at one second without jitter, on a fake clock that records
+// the delays.
+func newFakeClockClient(t *testing.T, doer ClientDoer, opts ...Option) (*RallyClient, *fakes.FakeClock) {
+	t.Helper()
+	clock := &fakes.FakeClock{Current: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	rallyClient, err := NewClient(append([]Option{
+		WithHTTPClient(doer),
+		WithRetries(2, time.Second),
+		WithJitter(JitterNone),
+		WithClock(clock),
+	}, opts...)...)
+	if err != nil {
+		t.Fatalf("NewClient failed unexpectedly: %v", err)
+	}
+	return rallyClient, clock
+}
+
+func TestRetryableErrorMessages_UpdateRetries(t *testing.T) {
+	doer := &scriptedDoer{
+		statuses: []int{http.StatusOK, http.StatusOK, http.StatusOK},
+		bodies: []string{
+			conflictBody,
+			`{"Defect": {"ObjectID": 12345, "VersionId": "8"}}`,
+			`{"OperationResult": {"Object": {"Name": "updated"}}}`,
+		},
+	}
+	rallyClient, clock := newFakeClockClient(t, doer, WithRetryableErrorMessages("object has been modified"))
+
+	var output map[string]interface{}
+	if err := rallyClient.UpdateRequest(context.Background(), "12345", "defect", map[string]string{"Name": "updated"}, &output); err != nil {
+		t.Fatalf("UpdateRequest should have succeeded after the retry: %v", err)
+	}
+
+	expected := []string{
+		"POST /slm/webservice/v2.0/defect/12345",
+		"GET /slm/webservice/v2.0/defect/12345",
+		"POST /slm/webservice/v2.0/defect/12345",
+	}
+	if !reflect.DeepEqual(doer.calls, expected) {
+		t.Errorf("expected calls %v, got %v", expected, doer.calls)
+	}
+	if doer.sent[len(doer.sent)-1] != `{"Name":"updated"}` {
+		t.Errorf("expected an unversioned update to be resent as it was, got %s", doer.sent[len(doer.sent)-1])
+	}
+	if !reflect.DeepEqual(clock.Sleeps, []time.Duration{time.Second}) {
+		t.Errorf("expected one retry delay, got %v", clock.Sleeps)
+	}
+}
+
+func TestRetryableErrorMessages_UpdateRefreshesVersion(t *testing.T) {
+	doer := &scriptedDoer{
+		statuses: []int{http.StatusOK, http.StatusOK, http.StatusOK},
+		bodies: []string{
+			conflictBody,
+			`{"Defect": {"ObjectID": 12345, "VersionId": "8"}}`,
+			`{"OperationResult": {"Object": {"Name": "updated"}}}`,
+		},
+	}
+	rallyClient, _ := newFakeClockClient(t, doer, WithRetryableErrorMessages("object has been modified"))
+
+	input := map[string]interface{}{"Defect": map[string]string{"Name": "updated", "VersionId": "7"}}
+	if err := rallyClient.UpdateRequest(context.Background(), "12345", "defect", input, nil); err != nil {
+		t.Fatalf("UpdateRequest should have succeeded after the retry: %v", err)
+	}
+	if expected := `{"Defect":{"Name":"updated","VersionId":"8"}}`; doer.sent[len(doer.sent)-1] != expected {
+		t.Errorf("expected the retry to carry the re-read VersionId, got %s", doer.sent[len(doer.sent)-1])
+	}
+}
+
+func TestRetryableErrorMessages_UpdateStopsWhenReReadFails(t *testing.T) {
+	doer := &scriptedDoer{
+		statuses: []int{http.StatusOK, http.StatusNotFound},
+		bodies:   []string{conflictBody, `{"OperationResult": {"Errors": ["Cannot find object to read"]}}`},
+	}
+	rallyClient, _ := newFakeClockClient(t, doer, WithRetryableErrorMessages("object has been modified"))
+
+	err := rallyClient.UpdateRequest(context.Background(), "12345", "defect", map[string]string{"Name": "updated"}, nil)
+	var apiErr *RallyAPIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusNotFound {
+		t.Errorf("expected the failed re-read to be returned, got %v", err)
+	}
+	if len(doer.calls) != 2 {
+		t.Errorf("expected no retry after the failed re-read, got %v", doer.calls)
+	}
+}
+
+func TestRetryableErrorMessages_NonSuccessStatus(t *testing.T) {
+	doer := &scriptedDoer{
+		statuses: []int{http.StatusBadRequest, http.StatusOK},
+		bodies:   []string{conflictBody, `{"CreateResult": {"Object": {"ObjectID": 1}}}`},
+	}
+	rallyClient, _ := newFakeClockClient(t, doer, WithRetryableErrorMessages("Concurrency conflict"))
+
+	var output map[string]interface{}
+	if err := rallyClient.CreateRequest(context.Background(), "defect", map[string]string{"Name": "new"}, &output); err != nil {
+		t.Fatalf("CreateRequest should have succeeded after the retry: %v", err)
+	}
+	if len(doer.calls) != 2 {
+		t.Errorf("expected 2 calls, got %v", doer.calls)
+	}
+}
+
+func TestRetryableErrorMessages_GivesUpAfterMaxRetries(t *testing.T) {
+	doer := &scriptedDoer{statuses: []int{http.StatusBadRequest}, bodies: []string{conflictBody}}
+	rallyClient, _ := newFakeClockClient(t, doer, WithRetryableErrorMessages("Concurrency conflict"))
+
+	err := rallyClient.GetRequest(context.Background(), "12345", "defect", nil)
+	var apiErr *RallyAPIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected the last RallyAPIError, got %v", err)
+	}
+	if len(doer.calls) != 3 {
+		t.Errorf("expected 3 calls, got %v", doer.calls)
+	}
+}
+
+func TestRetryableErrorMessages_DefaultNoRetry(t *testing.T) {
+	doer := &scriptedDoer{statuses: []int{http.StatusOK}, bodies: []string{conflictBody}}
+	rallyClient, _ := newFakeClockClient(t, doer)
+
+	err := rallyClient.UpdateRequest(context.Background(), "12345", "defect", map[string]string{"Name": "updated"}, nil)
+	if !errors.Is(err, ErrRallyAPI) {
+		t.Fatalf("expected a RallyAPIError, got %v", err)
+	}
+	if len(doer.calls) != 1 {
+		t.Errorf("expected no retries, got %v", doer.calls)
+	}
+}
+
+func TestRetryableErrorMessages_UnmatchedMessage(t *testing.T) {
+	doer := &scriptedDoer{
+		statuses: []int{http.StatusBadRequest},
+		bodies:   []string{`{"OperationResult": {"Errors": ["Could not read: Cannot find object"]}}`},
+	}
+	rallyClient, _ := newFakeClockClient(t, doer, WithRetryableErrorMessages("Concurrency conflict"))
+
+	if err := rallyClient.GetRequest(context.Background(), "12345", "defect", nil); err == nil {
+		t.Fatal("expected GetRequest to fail")
+	}
+	if len(doer.calls) != 1 {
+		t.Errorf("expected no retries, got %v", doer.calls)
+	}
+}
+
+func TestWithRetryableErrorMessages_RejectsEmpty(t *testing.T) {
+	if _, err := NewClient(WithRetryableErrorMessages("")); err == nil {
+		t.Error("expected an error for an empty message")
+	}
+}
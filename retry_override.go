@@ -0,0 +1,45 @@
+/**
+* Copyright 2014 Comcast Cable Communications Management, LLC
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+* http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package rallyresttoolkit
+
+import (
+	"context"
+	"time"
+)
+
+// retryOverride holds the per-request retry settings set by WithRetryOverride.
+type retryOverride struct {
+	maxRetries int
+	delay      time.Duration
+}
+
+type retryOverrideKey struct{}
+
+// WithRetryOverride returns a context that makes requests made with it use
+// maxRetries and delay instead of Config.MaxRetries and Config.RetryDelay, e.g.
+// to retry a known-flaky endpoint harder without a second client. delay is
+// the base delay fed to the backoff strategy; MaxRetryDelay and jitter still
+// apply. A negative maxRetries or delay keeps the configured value.
+func WithRetryOverride(ctx context.Context, maxRetries int, delay time.Duration) context.Context {
+	return context.WithValue(ctx, retryOverrideKey{}, retryOverride{maxRetries: maxRetries, delay: delay})
+}
+
+// retryOverrideFromContext returns the override attached to ctx, if any.
+func retryOverrideFromContext(ctx context.Context) (retryOverride, bool) {
+	override, ok := ctx.Value(retryOverrideKey{}).(retryOverride)
+	return override, ok
+}
@@ -0,0 +1,93 @@
+/**
+* Copyright 2014 Comcast Cable Communications Management, LLC
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+* http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package rallyresttoolkit_test
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	. "github.com/aleksofficial/go-rally-rest-toolkit"
+	"github.com/aleksofficial/go-rally-rest-toolkit/fakes"
+)
+
+func TestWithRetryOverride_ReplacesConfig(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{
+		FakeResponses: []*http.Response{
+			errorResponse(http.StatusServiceUnavailable),
+			errorResponse(http.StatusServiceUnavailable),
+			errorResponse(http.StatusServiceUnavailable),
+			okResponse(),
+		},
+	}
+	clock := &fakes.FakeClock{}
+	rallyClient, err := NewClient(
+		WithHTTPClient(fakeClient),
+		WithRetries(1, time.Second),
+		WithBackoff(ConstantBackoff{}),
+		WithJitter(JitterNone),
+		WithClock(clock),
+	)
+	if err != nil {
+		t.Fatalf("NewClient failed unexpectedly: %v", err)
+	}
+
+	ctx := WithRetryOverride(context.Background(), 3, 50*time.Millisecond)
+	fakeOutput := new(fakes.FakeOutput)
+	if err := rallyClient.GetRequest(ctx, "12345", "defect", &fakeOutput); err != nil {
+		t.Fatalf("GetRequest failed unexpectedly: %v", err)
+	}
+
+	expected := []time.Duration{50 * time.Millisecond, 50 * time.Millisecond, 50 * time.Millisecond}
+	if !reflect.DeepEqual(clock.Sleeps, expected) {
+		t.Errorf("expected sleeps %v, got %v", expected, clock.Sleeps)
+	}
+}
+
+func TestWithRetryOverride_NegativeKeepsConfig(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{
+		FakeResponses: []*http.Response{
+			errorResponse(http.StatusServiceUnavailable),
+			errorResponse(http.StatusServiceUnavailable),
+			okResponse(),
+		},
+	}
+	clock := &fakes.FakeClock{}
+	rallyClient, err := NewClient(
+		WithHTTPClient(fakeClient),
+		WithRetries(1, time.Second),
+		WithBackoff(ConstantBackoff{}),
+		WithJitter(JitterNone),
+		WithClock(clock),
+	)
+	if err != nil {
+		t.Fatalf("NewClient failed unexpectedly: %v", err)
+	}
+
+	ctx := WithRetryOverride(context.Background(), -1, -1)
+	fakeOutput := new(fakes.FakeOutput)
+	if err := rallyClient.GetRequest(ctx, "12345", "defect", &fakeOutput); err == nil {
+		t.Fatal("expected GetRequest to fail after the configured single retry")
+	}
+
+	expected := []time.Duration{time.Second}
+	if !reflect.DeepEqual(clock.Sleeps, expected) {
+		t.Errorf("expected sleeps %v, got %v", expected, clock.Sleeps)
+	}
+}
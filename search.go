@@ -0,0 +1,53 @@
+/**
+* Copyright 2014 Comcast Cable Communications Management, LLC
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+* http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package rallyresttoolkit
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/aleksofficial/go-rally-rest-toolkit/models"
+)
+
+// Search - full-text search for keywords across artifacts, returning one page
+// of matches in the relevance order Rally ranks them. Page through the results
+// with opts.Start and the page's NextStart. Order and OrderBy are ignored so
+// the ranking is kept; set opts.Types to limit the artifact types searched.
+func (s *RallyClient) Search(ctx context.Context, keywords string, opts QueryOptions) (Page[models.SearchResult], error) {
+	if strings.TrimSpace(keywords) == "" {
+		return Page[models.SearchResult]{}, fmt.Errorf("%w: empty search keywords", ErrInvalidQuery)
+	}
+	opts = s.queryDefaults(opts)
+	opts.Order = ""
+	opts.OrderBy = OrderBy{}
+	if err := opts.validate(); err != nil {
+		return Page[models.SearchResult]{}, err
+	}
+
+	baseURL, err := s.endpoint("search")
+	if err != nil {
+		return Page[models.SearchResult]{}, err
+	}
+	params := opts.encode(nil)
+	params.Set("search", keywords)
+	baseURL.RawQuery = params.Encode()
+
+	var response models.QueryResponse[models.SearchResult]
+	err = s.execute(ctx, "GET", baseURL, nil, &response)
+	return Page[models.SearchResult]{response.QueryResult}, err
+}
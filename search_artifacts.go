@@ -0,0 +1,70 @@
+/**
+* Copyright 2014 Comcast Cable Communications Management, LLC
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+* http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package rallyresttoolkit
+
+import (
+	"context"
+	"sync"
+
+	"github.com/aleksofficial/go-rally-rest-toolkit/models"
+)
+
+// DefaultSearchTypes are the types SearchArtifacts queries when none are given.
+var DefaultSearchTypes = []string{"hierarchicalrequirement", "defect", "task"}
+
+// SearchArtifacts - queries each of types for objects whose Name contains
+// keyword, concurrently and sharing the client's rate limit, for a global
+// search box. Results are merged into one slice, grouped in the order of
+// types; use each result's Type and Decode to tell them apart. opts applies to
+// every type, e.g. PageSize caps the results per type. A failed type does not
+// stop the others: its error is returned under its name in the map, which is
+// nil when every type succeeded.
+func (s *RallyClient) SearchArtifacts(ctx context.Context, keyword string, types []string, opts QueryOptions) ([]models.Artifact, map[string]error) {
+	if len(types) == 0 {
+		types = DefaultSearchTypes
+	}
+	expr := Contains("Name", keyword)
+
+	found := make([][]models.Artifact, len(types))
+	var errs map[string]error
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for i, queryType := range types {
+		wg.Add(1)
+		go func(i int, queryType string) {
+			defer wg.Done()
+			var response models.QueryResponse[models.Artifact]
+			if err := s.QueryRequestExpr(ctx, expr, queryType, opts, &response); err != nil {
+				mu.Lock()
+				if errs == nil {
+					errs = make(map[string]error)
+				}
+				errs[queryType] = err
+				mu.Unlock()
+				return
+			}
+			found[i] = response.QueryResult.Results
+		}(i, queryType)
+	}
+	wg.Wait()
+
+	var results []models.Artifact
+	for _, artifacts := range found {
+		results = append(results, artifacts...)
+	}
+	return results, errs
+}
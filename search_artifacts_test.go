@@ -0,0 +1,106 @@
+/**
+* Copyright 2014 Comcast Cable Communications Management, LLC
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+* http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package rallyresttoolkit_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	. "github.com/aleksofficial/go-rally-rest-toolkit"
+	"github.com/aleksofficial/go-rally-rest-toolkit/models"
+)
+
+// typeServer answers queries by the type in the request path and records the
+// query parameter of each.
+type typeServer struct {
+	bodies map[string]string
+
+	mu      sync.Mutex
+	queries map[string]string
+}
+
+func (s *typeServer) Do(req *http.Request) (*http.Response, error) {
+	queryType := req.URL.Path[strings.LastIndex(req.URL.Path, "/")+1:]
+	s.mu.Lock()
+	s.queries[queryType] = req.URL.Query().Get("query")
+	s.mu.Unlock()
+
+	body, ok := s.bodies[queryType]
+	if !ok {
+		return &http.Response{
+			StatusCode: http.StatusBadRequest,
+			Body:       io.NopCloser(bytes.NewBufferString(`{"QueryResult": {"Errors": ["Could not read: unknown type"]}}`)),
+		}, nil
+	}
+	return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewBufferString(body))}, nil
+}
+
+func TestSearchArtifacts_MergesTypesAndKeepsPartialResults(t *testing.T) {
+	server := &typeServer{
+		queries: map[string]string{},
+		bodies: map[string]string{
+			"hierarchicalrequirement": `{"QueryResult": {"TotalResultCount": 2, "Results": [
+				{"_type": "HierarchicalRequirement", "ObjectID": 1, "FormattedID": "US1", "Name": "Login page"},
+				{"_type": "HierarchicalRequirement", "ObjectID": 2, "FormattedID": "US2", "Name": "Login audit"}]}}`,
+			"task": `{"QueryResult": {"TotalResultCount": 1, "Results": [
+				{"_type": "Task", "ObjectID": 3, "FormattedID": "TA3", "Name": "Login copy"}]}}`,
+		},
+	}
+	rallyClient := New("abcdef", "http://myRallyUrl", server)
+
+	results, errs := rallyClient.SearchArtifacts(context.Background(), "Login", nil, QueryOptions{})
+
+	var ids []string
+	for _, artifact := range results {
+		ids = append(ids, artifact.Type+":"+artifact.FormattedID)
+	}
+	if got := strings.Join(ids, ","); got != "HierarchicalRequirement:US1,HierarchicalRequirement:US2,Task:TA3" {
+		t.Errorf("unexpected merged results %s", got)
+	}
+	if len(errs) != 1 || !errors.Is(errs["defect"], ErrRallyAPI) {
+		t.Errorf("expected only the defect search to fail, got %v", errs)
+	}
+	for _, queryType := range DefaultSearchTypes {
+		if got := server.queries[queryType]; got != "( Name contains Login )" {
+			t.Errorf("%s: unexpected query %q", queryType, got)
+		}
+	}
+
+	var task models.Task
+	if err := results[2].Decode(&task); err != nil || task.ObjectID != 3 {
+		t.Errorf("expected the task to decode, got %+v, %v", task, err)
+	}
+}
+
+func TestSearchArtifacts_NoErrors(t *testing.T) {
+	server := &typeServer{
+		queries: map[string]string{},
+		bodies:  map[string]string{"defect": `{"QueryResult": {"TotalResultCount": 0, "Results": []}}`},
+	}
+	rallyClient := New("abcdef", "http://myRallyUrl", server)
+
+	results, errs := rallyClient.SearchArtifacts(context.Background(), "crash", []string{"defect"}, QueryOptions{PageSize: 5})
+	if errs != nil || len(results) != 0 {
+		t.Errorf("expected no results and a nil error map, got %v, %v", results, errs)
+	}
+}
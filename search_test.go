@@ -0,0 +1,99 @@
+/**
+* Copyright 2014 Comcast Cable Communications Management, LLC
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+* http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package rallyresttoolkit_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	. "github.com/aleksofficial/go-rally-rest-toolkit"
+	"github.com/aleksofficial/go-rally-rest-toolkit/fakes"
+)
+
+func TestSearch_EncodesKeywords(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{
+		FakeResponse: jsonResponse(`{"QueryResult": {"TotalResultCount": 3, "StartIndex": 1, "Results": [
+			{"_type": "Defect", "ObjectID": 7, "FormattedID": "DE7", "Name": "Login timeout on SSO"},
+			{"_type": "HierarchicalRequirement", "ObjectID": 3, "FormattedID": "US3", "Name": "SSO login"}]}}`),
+	}
+	rallyClient := New("abcdef", "http://myRallyUrl", fakeClient)
+
+	opts := QueryOptions{PageSize: 2, Order: "Name"}
+	page, err := rallyClient.Search(context.Background(), "login timeout & SSO", opts)
+	if err != nil {
+		t.Fatalf("Search failed unexpectedly: %v", err)
+	}
+
+	req := fakeClient.SpyRequest
+	if req.URL.Path != "/search" {
+		t.Errorf("unexpected path %s", req.URL.Path)
+	}
+	if !strings.Contains(req.URL.RawQuery, "search=login+timeout+%26+SSO") {
+		t.Errorf("expected encoded search parameter, got %s", req.URL.RawQuery)
+	}
+	params := req.URL.Query()
+	if got := params.Get("search"); got != "login timeout & SSO" {
+		t.Errorf("unexpected search parameter %q", got)
+	}
+	if params.Has("query") || params.Has("order") {
+		t.Errorf("expected no query or order parameters, got %s", req.URL.RawQuery)
+	}
+
+	if len(page.Results) != 2 || page.Results[0].FormattedID != "DE7" || page.Results[1].FormattedID != "US3" {
+		t.Fatalf("expected results in response order, got %+v", page.Results)
+	}
+	if page.Results[0].Type != "Defect" || page.Results[1].Name != "SSO login" {
+		t.Errorf("unexpected results: %+v", page.Results)
+	}
+	if !page.HasMore() || page.NextStart() != 3 {
+		t.Errorf("expected another page starting at 3, got HasMore=%v NextStart=%d", page.HasMore(), page.NextStart())
+	}
+}
+
+func TestSearch_NextPage(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{
+		FakeResponse: jsonResponse(`{"QueryResult": {"TotalResultCount": 3, "StartIndex": 3, "Results": [
+			{"_type": "Task", "ObjectID": 9, "FormattedID": "TA9", "Name": "Fix SSO timeout"}]}}`),
+	}
+	rallyClient := New("abcdef", "http://myRallyUrl", fakeClient)
+
+	page, err := rallyClient.Search(context.Background(), "timeout", QueryOptions{Start: 3, PageSize: 2})
+	if err != nil {
+		t.Fatalf("Search failed unexpectedly: %v", err)
+	}
+	if got := fakeClient.SpyRequest.URL.Query().Get("start"); got != "3" {
+		t.Errorf("unexpected start parameter %q", got)
+	}
+	if page.HasMore() {
+		t.Error("expected the last page")
+	}
+}
+
+func TestSearch_EmptyKeywords(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{}
+	rallyClient := New("abcdef", "http://myRallyUrl", fakeClient)
+
+	_, err := rallyClient.Search(context.Background(), "  ", QueryOptions{})
+	if !errors.Is(err, ErrInvalidQuery) {
+		t.Fatalf("expected ErrInvalidQuery, got %v", err)
+	}
+	if fakeClient.SpyRequest != nil {
+		t.Error("expected no request to be sent")
+	}
+}
@@ -0,0 +1,105 @@
+/**
+* Copyright 2014 Comcast Cable Communications Management, LLC
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+* http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package rallyresttoolkit
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// writeRequest wraps a model in the {"<Type>": {...}} envelope Rally expects for
+// creates and updates, leaving out fields the model marks as read-only.
+func writeRequest(key string, model interface{}) (map[string]interface{}, error) {
+	fields, err := writableFields(model)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{key: fields}, nil
+}
+
+// writableFields converts a model into its JSON fields, dropping any field
+// tagged rally:"readonly" and any value that reports it is not writable, such
+// as a collection summary. Read-only fields stay populated on reads but are
+// never sent back to Rally, which rejects or warns about them.
+func writableFields(model interface{}) (map[string]json.RawMessage, error) {
+	content, err := json.Marshal(model)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request body: %w", err)
+	}
+
+	fields := map[string]json.RawMessage{}
+	if err := json.Unmarshal(content, &fields); err != nil {
+		return nil, fmt.Errorf("failed to marshal request body: %w", err)
+	}
+
+	for _, name := range unwritableFields(reflect.ValueOf(model)) {
+		delete(fields, name)
+	}
+	return fields, nil
+}
+
+// writable is implemented by field types that are only sent when set for
+// writing, such as *models.Collection.
+type writable interface {
+	IsWritable() bool
+}
+
+// unwritableFields returns the JSON names of the fields of a model that must
+// not be sent, including those promoted from embedded structs.
+func unwritableFields(v reflect.Value) []string {
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+
+	var names []string
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Anonymous {
+			names = append(names, unwritableFields(v.Field(i))...)
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if field.Tag.Get("rally") == "readonly" {
+			names = append(names, jsonFieldName(field))
+			continue
+		}
+		if w, ok := v.Field(i).Interface().(writable); ok && !w.IsWritable() {
+			names = append(names, jsonFieldName(field))
+		}
+	}
+	return names
+}
+
+// jsonFieldName returns the name encoding/json uses for a struct field.
+func jsonFieldName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "" {
+		return field.Name
+	}
+	return name
+}
@@ -0,0 +1,82 @@
+/**
+* Copyright 2014 Comcast Cable Communications Management, LLC
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+* http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package rallyresttoolkit
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/aleksofficial/go-rally-rest-toolkit/models"
+)
+
+func TestWriteRequest_TestCaseOmitsRollups(t *testing.T) {
+	lastRun := "2016-04-12T08:30:00.000Z"
+	testCase := models.TestCase{
+		Name:         "Login accepts valid password",
+		TestCaseType: "Acceptance",
+		Method:       "Automated",
+		Priority:     "Critical",
+		Risk:         "High",
+		LastVerdict:  "Pass",
+		LastRun:      &lastRun,
+		LastBuild:    "build-1432",
+	}
+
+	request, err := writeRequest("TestCase", testCase)
+	if err != nil {
+		t.Fatalf("writeRequest failed unexpectedly: %v", err)
+	}
+	fields, ok := request["TestCase"].(map[string]json.RawMessage)
+	if !ok {
+		t.Fatalf("expected a TestCase envelope, got %v", request)
+	}
+
+	for _, name := range []string{"LastVerdict", "LastRun", "LastBuild"} {
+		if _, ok := fields[name]; ok {
+			t.Errorf("expected read-only %s not to be written", name)
+		}
+	}
+	for _, name := range []string{"Name", "Type", "Method", "Priority", "Risk"} {
+		if _, ok := fields[name]; !ok {
+			t.Errorf("expected %s to be written", name)
+		}
+	}
+}
+
+func TestWriteRequest_UserOmitsReadOnlyFields(t *testing.T) {
+	user := models.User{
+		EmailAddress:  "jane.doe@example.com",
+		Disabled:      true,
+		LastLoginDate: models.NewTime(time.Date(2016, 2, 29, 14, 3, 11, 0, time.UTC)),
+	}
+
+	request, err := writeRequest("User", user)
+	if err != nil {
+		t.Fatalf("writeRequest failed unexpectedly: %v", err)
+	}
+	fields := request["User"].(map[string]json.RawMessage)
+	if _, ok := fields["Disabled"]; ok {
+		t.Error("expected read-only Disabled not to be written")
+	}
+	if _, ok := fields["LastLoginDate"]; ok {
+		t.Error("expected read-only LastLoginDate not to be written")
+	}
+	if _, ok := fields["EmailAddress"]; !ok {
+		t.Error("expected EmailAddress to be written")
+	}
+}
@@ -0,0 +1,46 @@
+/**
+* Copyright 2014 Comcast Cable Communications Management, LLC
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+* http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package rallyresttoolkit
+
+import (
+	"context"
+	"net/url"
+
+	"github.com/aleksofficial/go-rally-rest-toolkit/models"
+)
+
+// GetSubscriptionResponse - struct to contain response
+type GetSubscriptionResponse struct {
+	Subscription models.Subscription
+}
+
+// GetSubscription - reads the subscription the API key belongs to. Unlike
+// other types it is a singleton, so there is no ObjectID to pass.
+func (s *RallyClient) GetSubscription(ctx context.Context) (models.Subscription, error) {
+	baseURL, err := s.endpoint("subscription")
+	if err != nil {
+		return models.Subscription{}, err
+	}
+
+	params := url.Values{}
+	params.Add("fetch", "true")
+	baseURL.RawQuery = params.Encode()
+
+	gsub := new(GetSubscriptionResponse)
+	err = s.execute(ctx, "GET", baseURL, nil, gsub)
+	return gsub.Subscription, err
+}
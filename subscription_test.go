@@ -0,0 +1,85 @@
+/**
+* Copyright 2014 Comcast Cable Communications Management, LLC
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+* http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package rallyresttoolkit_test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"testing"
+
+	. "github.com/aleksofficial/go-rally-rest-toolkit"
+	"github.com/aleksofficial/go-rally-rest-toolkit/fakes"
+)
+
+func TestGetSubscription(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{
+		FakeResponse: &http.Response{
+			StatusCode: http.StatusOK,
+			Body: &fakes.FakeResponseBody{Reader: bytes.NewBufferString(`{"Subscription": {
+				"_ref": "https://rally1.rallydev.com/slm/webservice/v2.0/subscription/1234",
+				"_type": "Subscription",
+				"ObjectID": 1234,
+				"Name": "Comcast",
+				"SubscriptionID": 100,
+				"ExpirationDate": "2027-06-30T23:59:59.000Z",
+				"MaximumProjects": -1,
+				"Workspaces": {"_ref": "https://rally1.rallydev.com/slm/webservice/v2.0/Subscription/1234/Workspaces", "_type": "Workspace", "Count": 3}
+			}}`)},
+		},
+	}
+
+	rallyClient := New("abcdef", "http://myRallyUrl", fakeClient)
+
+	sub, err := rallyClient.GetSubscription(context.Background())
+	if err != nil {
+		t.Fatalf("GetSubscription failed unexpectedly: %v", err)
+	}
+	if got := fakeClient.SpyRequest.URL.Path; got != "/subscription" {
+		t.Errorf("expected /subscription, got %s", got)
+	}
+	if fakeClient.SpyRequest.Method != "GET" {
+		t.Errorf("expected GET, got %s", fakeClient.SpyRequest.Method)
+	}
+	if sub.Name != "Comcast" || sub.SubscriptionID != 100 || sub.ObjectID != 1234 {
+		t.Errorf("unexpected subscription: %+v", sub)
+	}
+	if sub.ExpirationDate != "2027-06-30T23:59:59.000Z" {
+		t.Errorf("unexpected ExpirationDate: %q", sub.ExpirationDate)
+	}
+	if sub.MaximumProjects != -1 {
+		t.Errorf("expected MaximumProjects=-1, got %d", sub.MaximumProjects)
+	}
+	if sub.Workspaces == nil || sub.Workspaces.Count != 3 {
+		t.Errorf("expected 3 workspaces, got %+v", sub.Workspaces)
+	}
+}
+
+func TestGetSubscription_Unauthorized(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{
+		FakeResponse: &http.Response{
+			StatusCode: http.StatusUnauthorized,
+			Body:       &fakes.FakeResponseBody{Reader: bytes.NewBufferString(`{}`)},
+		},
+	}
+
+	rallyClient := New("badkey", "http://myRallyUrl", fakeClient)
+
+	if _, err := rallyClient.GetSubscription(context.Background()); err == nil {
+		t.Fatal("expected GetSubscription to fail on 401")
+	}
+}
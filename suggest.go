@@ -0,0 +1,45 @@
+/**
+* Copyright 2014 Comcast Cable Communications Management, LLC
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+* http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package rallyresttoolkit
+
+import (
+	"context"
+
+	"github.com/aleksofficial/go-rally-rest-toolkit/models"
+)
+
+// DefaultSuggestLimit is the number of suggestions Suggest returns when limit
+// is not positive.
+const DefaultSuggestLimit = 10
+
+// Suggest returns up to limit objects of queryType whose Name contains prefix,
+// ordered by Name and fetching only ObjectID, FormattedID and Name, for
+// typeahead and autocomplete.
+func (s *RallyClient) Suggest(ctx context.Context, queryType string, prefix string, limit int) ([]models.Suggestion, error) {
+	if limit <= 0 {
+		limit = DefaultSuggestLimit
+	}
+	opts := QueryOptions{
+		Order:    "Name",
+		PageSize: limit,
+		Fetch:    []string{"ObjectID", "FormattedID", "Name"},
+	}
+
+	var resp models.QueryResponse[models.Suggestion]
+	err := s.QueryRequestExpr(ctx, Contains("Name", prefix), queryType, opts, &resp)
+	return resp.QueryResult.Results, err
+}
@@ -0,0 +1,73 @@
+/**
+* Copyright 2014 Comcast Cable Communications Management, LLC
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+* http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package rallyresttoolkit_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	. "github.com/aleksofficial/go-rally-rest-toolkit"
+	"github.com/aleksofficial/go-rally-rest-toolkit/fakes"
+)
+
+func TestSuggest(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{
+		FakeResponse: jsonResponse(`{"QueryResult": {"TotalResultCount": 2, "Results": [
+			{"ObjectID": 11, "FormattedID": "US11", "Name": "Login page"},
+			{"ObjectID": 12, "FormattedID": "US12", "Name": "Login timeout"}]}}`),
+	}
+	rallyClient := New("abcdef", "http://myRallyUrl", fakeClient)
+
+	suggestions, err := rallyClient.Suggest(context.Background(), "HierarchicalRequirement", "Login p", 5)
+	if err != nil {
+		t.Fatalf("Suggest failed unexpectedly: %v", err)
+	}
+	if len(suggestions) != 2 || suggestions[0].FormattedID != "US11" || suggestions[1].Name != "Login timeout" {
+		t.Errorf("unexpected suggestions: %+v", suggestions)
+	}
+
+	params := fakeClient.SpyRequest.URL.Query()
+	if got := params.Get("query"); got != `( Name contains "Login p" )` {
+		t.Errorf("unexpected query %s", got)
+	}
+	if got := params.Get("fetch"); got != "ObjectID,FormattedID,Name" {
+		t.Errorf("unexpected fetch %s", got)
+	}
+	if got := params.Get("pagesize"); got != "5" {
+		t.Errorf("unexpected pagesize %s", got)
+	}
+	if got := params.Get("order"); got != "Name" {
+		t.Errorf("unexpected order %s", got)
+	}
+}
+
+func TestSuggest_DefaultAndInvalidLimit(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{FakeResponse: jsonResponse(`{"QueryResult": {"Results": []}}`)}
+	rallyClient := New("abcdef", "http://myRallyUrl", fakeClient)
+
+	if _, err := rallyClient.Suggest(context.Background(), "defect", "crash", 0); err != nil {
+		t.Fatalf("Suggest failed unexpectedly: %v", err)
+	}
+	if got := fakeClient.SpyRequest.URL.Query().Get("pagesize"); got != "10" {
+		t.Errorf("expected the default pagesize 10, got %s", got)
+	}
+
+	if _, err := rallyClient.Suggest(context.Background(), "defect", "crash", MaxPageSize+1); !errors.Is(err, ErrInvalidPageSize) {
+		t.Errorf("expected ErrInvalidPageSize, got %v", err)
+	}
+}
@@ -29,12 +29,7 @@ type Task struct {
 }
 
 // QueryTaskResponse - struct to contain query response
-type QueryTaskResponse struct {
-	QueryResult struct {
-		Results          []models.Task
-		TotalResultCount int
-	}
-}
+type QueryTaskResponse = models.QueryResponse[models.Task]
 
 // GetTaskResponse - Struct to contain response
 type GetTaskResponse struct {
@@ -55,9 +50,7 @@ type taskResult struct {
 }
 
 // OperationResponse - struct to contain response
-type taskOperationResponse struct {
-	OperationalResult taskResult
-}
+type taskOperationResponse = models.OperationResponse[models.Task]
 
 // NewTask - creates new Task
 func NewTask(client *RallyClient) (de *Task) {
@@ -73,6 +66,46 @@ func (s *Task) QueryTask(ctx context.Context, query map[string]string) (des []mo
 	return qdes.QueryResult.Results, err
 }
 
+// QueryTaskWithMeta - QueryRequest that also returns the envelope's paging metadata and Warnings
+func (s *Task) QueryTaskWithMeta(ctx context.Context, query map[string]string) ([]models.Task, QueryMeta, error) {
+	return Query[models.Task](ctx, s.client, "task", query, QueryOptions{})
+}
+
+// QueryTaskPage - abstraction for QueryRequestWithOptions that keeps the paging metadata
+func (s *Task) QueryTaskPage(ctx context.Context, query map[string]string, opts QueryOptions) (Page[models.Task], error) {
+	return queryPage[models.Task](ctx, s.client, query, "task", opts)
+}
+
+// QueryTaskRaw - abstraction for QueryRequestRaw, for query clauses written by hand
+func (s *Task) QueryTaskRaw(ctx context.Context, rawQuery string, opts QueryOptions) (Page[models.Task], error) {
+	return queryRawPage[models.Task](ctx, s.client, rawQuery, "task", opts)
+}
+
+// QueryTaskWith - abstraction for QueryRequestWith
+func (s *Task) QueryTaskWith(ctx context.Context, b *QueryBuilder) (Page[models.Task], error) {
+	return queryWithPage[models.Task](ctx, s.client, b, "task")
+}
+
+// QueryTaskByState - lists every task in the given state, page by page
+func (s *Task) QueryTaskByState(ctx context.Context, state string) ([]models.Task, error) {
+	return queryAllExprResults[models.Task](ctx, s.client, Eq("State", state), "task")
+}
+
+// QueryTaskByOwner - lists every task owned by the user a ref points at
+func (s *Task) QueryTaskByOwner(ctx context.Context, userRef string) ([]models.Task, error) {
+	return queryAllExprResults[models.Task](ctx, s.client, RefEq("Owner", RefValue(userRef)), "task")
+}
+
+// QueryTaskByIteration - lists every task scheduled in the iteration a ref points at
+func (s *Task) QueryTaskByIteration(ctx context.Context, iterationRef string) ([]models.Task, error) {
+	return queryAllExprResults[models.Task](ctx, s.client, RefEq("Iteration", RefValue(iterationRef)), "task")
+}
+
+// FindTasksByName - lists every task whose Name contains name, ignoring case
+func (s *Task) FindTasksByName(ctx context.Context, name string) ([]models.Task, error) {
+	return queryAllExprResults[models.Task](ctx, s.client, NameContainsAnyCase("Name", name), "task")
+}
+
 // GetTask - abstraction for GetRequest
 func (s *Task) GetTask(ctx context.Context, objectID string) (de models.Task, err error) {
 	gde := new(GetTaskResponse)
@@ -82,8 +115,9 @@ func (s *Task) GetTask(ctx context.Context, objectID string) (de models.Task, er
 
 // CreateTask - abstraction for CreateRequest
 func (s *Task) CreateTask(ctx context.Context, task models.Task) (der models.Task, err error) {
-	createRequest := CreateTaskRequest{
-		Task: task,
+	createRequest, err := writeRequest("Task", task)
+	if err != nil {
+		return der, err
 	}
 	ude := new(CreateTaskResponse)
 	err = s.client.CreateRequest(ctx, "task", createRequest, &ude)
@@ -91,10 +125,22 @@ func (s *Task) CreateTask(ctx context.Context, task models.Task) (der models.Tas
 	return der, err
 }
 
+// FindOrCreateTask - returns the first task matching matchQuery, e.g. on a unique
+// external ID, or else creates task; the bool reports whether it was created
+func (s *Task) FindOrCreateTask(ctx context.Context, matchQuery map[string]string, task models.Task) (models.Task, bool, error) {
+	return findOrCreate[models.Task](ctx, s.client, "task", matchQuery, func(ctx context.Context) (models.Task, error) {
+		return s.CreateTask(ctx, task)
+	})
+}
+
 // UpdateTask - abstraction for UpdateRequest
 func (s *Task) UpdateTask(ctx context.Context, task models.Task) (taskr models.Task, err error) {
+	updateRequest, err := writeRequest("Task", task)
+	if err != nil {
+		return taskr, err
+	}
 	ude := new(taskOperationResponse)
-	err = s.client.UpdateRequest(ctx, strconv.Itoa(task.ObjectID), "task", task, &ude)
+	err = s.client.UpdateRequest(ctx, strconv.Itoa(task.ObjectID), "task", updateRequest, &ude)
 	taskr = ude.OperationalResult.Object
 	return taskr, err
 }
@@ -20,6 +20,7 @@ import (
 	"bytes"
 	"context"
 	"net/http"
+	"strings"
 	"testing"
 
 	. "github.com/aleksofficial/go-rally-rest-toolkit"
@@ -125,8 +126,8 @@ func TestUpdateTask_ValidRequest(t *testing.T) {
 	ctx := context.Background()
 
 	updateTask := models.Task{
-		Name:     ctrlName,
-		ObjectID: 50137325678,
+		Name:              ctrlName,
+		PersistableObject: models.PersistableObject{ObjectID: 50137325678},
 	}
 	result, err := taskClient.UpdateTask(ctx, updateTask)
 	if err != nil {
@@ -157,3 +158,69 @@ func TestDeleteTask_ValidObjectID(t *testing.T) {
 		t.Fatalf("DeleteTask failed unexpectedly: %v", err)
 	}
 }
+
+func TestQueryTaskPage_LastPage(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{
+		FakeResponse: &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       &fakes.FakeResponseBody{Reader: bytes.NewBufferString(`{"QueryResult": { "TotalResultCount": 3, "StartIndex": 1, "PageSize": 20, "Results": [{"ObjectID": 1}, {"ObjectID": 2}, {"ObjectID": 3}]}}`)},
+		},
+	}
+
+	rallyClient := New("abcdef", "http://myRallyUrl", fakeClient)
+	taskClient := NewTask(rallyClient)
+
+	page, err := taskClient.QueryTaskPage(context.Background(), map[string]string{}, QueryOptions{})
+	if err != nil {
+		t.Fatalf("QueryTaskPage failed unexpectedly: %v", err)
+	}
+	if len(page.Results) != 3 {
+		t.Fatalf("expected 3 results, got %d", len(page.Results))
+	}
+	if page.HasMore() {
+		t.Error("expected HasMore to be false on the last page")
+	}
+}
+
+func TestQueryTaskByOwner(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{
+		FakeResponse: &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       &fakes.FakeResponseBody{Reader: bytes.NewBufferString(`{"QueryResult": { "TotalResultCount": 1, "Results": [{"FormattedID": "TA1"}]}}`)},
+		},
+	}
+	taskClient := NewTask(New("abcdef", "http://myRallyUrl", fakeClient))
+
+	tasks, err := taskClient.QueryTaskByOwner(context.Background(), "/user/999")
+	if err != nil {
+		t.Fatalf("QueryTaskByOwner failed unexpectedly: %v", err)
+	}
+	if len(tasks) != 1 || tasks[0].FormattedID != "TA1" {
+		t.Errorf("unexpected tasks: %+v", tasks)
+	}
+	if got := fakeClient.SpyRequest.URL.Query().Get("query"); got != "( Owner = /user/999 )" {
+		t.Errorf("unexpected query %s", got)
+	}
+	if !strings.HasSuffix(fakeClient.SpyRequest.URL.Path, "/task") {
+		t.Errorf("unexpected path %s", fakeClient.SpyRequest.URL.Path)
+	}
+}
+
+func TestFindOrCreateTask_Creates(t *testing.T) {
+	doer := &scriptedDoer{
+		statuses: []int{http.StatusOK, http.StatusOK},
+		bodies: []string{
+			`{"QueryResult": {"TotalResultCount": 0, "Results": []}}`,
+			`{"CreateResult": {"Object": {"ObjectID": 3, "Name": "Imported"}}}`,
+		},
+	}
+	taskClient := NewTask(New("abcdef", "http://myRallyUrl", doer))
+
+	task, created, err := taskClient.FindOrCreateTask(context.Background(), map[string]string{"Name": "Imported"}, models.Task{Name: "Imported"})
+	if err != nil {
+		t.Fatalf("FindOrCreateTask failed unexpectedly: %v", err)
+	}
+	if !created || task.ObjectID != 3 {
+		t.Errorf("expected the created task 3, got %+v (created=%v)", task, created)
+	}
+}
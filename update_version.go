@@ -0,0 +1,60 @@
+/**
+* Copyright 2014 Comcast Cable Communications Management, LLC
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+* http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package rallyresttoolkit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/aleksofficial/go-rally-rest-toolkit/models"
+)
+
+// UpdateWithVersion updates fields of an object only if it is still at
+// expectedVersion, its VersionId from an earlier read, and returns its new
+// VersionId. The version is sent with the fields for Rally to compare. If the
+// object has changed since, the error matches ErrConcurrentModification and
+// the caller can re-read the object and try again with its new VersionId.
+// Conflicts are never retried, even if listed in Config.RetryableErrorMessages,
+// since a retry would send the same stale version.
+func (s *RallyClient) UpdateWithVersion(ctx context.Context, objectID string, queryType string, fields map[string]interface{}, expectedVersion string) (string, error) {
+	if expectedVersion == "" {
+		return "", errors.New("expected version must not be empty")
+	}
+
+	baseURL, err := s.endpoint(queryType, objectID)
+	if err != nil {
+		return "", err
+	}
+
+	versioned := make(map[string]interface{}, len(fields)+1)
+	for name, value := range fields {
+		versioned[name] = value
+	}
+	versioned["VersionId"] = expectedVersion
+	inputByteArray, err := json.Marshal(map[string]interface{}{queryType: versioned})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal request body: %w", err)
+	}
+
+	var response models.OperationResponse[models.PersistableObject]
+	if err := s.executeOnce(ctx, "POST", baseURL, inputByteArray, &response); err != nil {
+		return "", err
+	}
+	return response.OperationalResult.Object.VersionId, nil
+}
@@ -0,0 +1,100 @@
+/**
+* Copyright 2014 Comcast Cable Communications Management, LLC
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+* http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package rallyresttoolkit_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	. "github.com/aleksofficial/go-rally-rest-toolkit"
+	"github.com/aleksofficial/go-rally-rest-toolkit/fakes"
+)
+
+func TestUpdateWithVersion(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{FakeResponse: &http.Response{
+		StatusCode: http.StatusOK,
+		Body:       &fakes.FakeResponseBody{Reader: bytes.NewBufferString(`{"OperationResult": {"Object": {"ObjectID": 12345, "VersionId": "13"}}}`)},
+	}}
+	rallyClient := New("abcdef", "http://myRallyUrl", fakeClient)
+
+	version, err := rallyClient.UpdateWithVersion(context.Background(), "12345", "defect", map[string]interface{}{"State": "Fixed"}, "12")
+	if err != nil {
+		t.Fatalf("UpdateWithVersion failed unexpectedly: %v", err)
+	}
+	if version != "13" {
+		t.Errorf("expected new version 13, got %q", version)
+	}
+
+	var body struct {
+		Defect map[string]string `json:"defect"`
+	}
+	if err := json.NewDecoder(fakeClient.SpyRequest.Body).Decode(&body); err != nil {
+		t.Fatalf("failed to decode request body: %v", err)
+	}
+	if body.Defect["State"] != "Fixed" || body.Defect["VersionId"] != "12" {
+		t.Errorf("expected State and the expected VersionId in the body, got %v", body.Defect)
+	}
+}
+
+func TestUpdateWithVersion_Conflict(t *testing.T) {
+	doer := &scriptedDoer{statuses: []int{http.StatusOK}, bodies: []string{conflictBody}}
+	rallyClient, err := NewClient(WithHTTPClient(doer), WithRetryableErrorMessages("Concurrency conflict"))
+	if err != nil {
+		t.Fatalf("NewClient failed unexpectedly: %v", err)
+	}
+
+	_, err = rallyClient.UpdateWithVersion(context.Background(), "12345", "defect", map[string]interface{}{"State": "Fixed"}, "12")
+	if !errors.Is(err, ErrConcurrentModification) {
+		t.Fatalf("expected ErrConcurrentModification, got %v", err)
+	}
+	var apiErr *RallyAPIError
+	if !errors.As(err, &apiErr) {
+		t.Errorf("expected the underlying RallyAPIError, got %T", err)
+	}
+	if len(doer.calls) != 1 {
+		t.Errorf("expected a conflict not to be retried, got %v", doer.calls)
+	}
+}
+
+func TestUpdateWithVersion_RequiresVersion(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{}
+	rallyClient := New("abcdef", "http://myRallyUrl", fakeClient)
+
+	if _, err := rallyClient.UpdateWithVersion(context.Background(), "12345", "defect", nil, ""); err == nil {
+		t.Fatal("expected an error for an empty version")
+	}
+	if fakeClient.CallCount != 0 {
+		t.Errorf("expected no request, got %d", fakeClient.CallCount)
+	}
+}
+
+func TestUpdateRequest_ConcurrentModification(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{FakeResponse: &http.Response{
+		StatusCode: http.StatusOK,
+		Body:       &fakes.FakeResponseBody{Reader: bytes.NewBufferString(conflictBody)},
+	}}
+	rallyClient := New("abcdef", "http://myRallyUrl", fakeClient)
+
+	err := rallyClient.UpdateRequest(context.Background(), "12345", "defect", map[string]interface{}{}, nil)
+	if !errors.Is(err, ErrConcurrentModification) {
+		t.Errorf("expected ErrConcurrentModification, got %v", err)
+	}
+}
@@ -0,0 +1,82 @@
+/**
+* Copyright 2014 Comcast Cable Communications Management, LLC
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+* http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package rallyresttoolkit
+
+import (
+	"context"
+
+	"github.com/aleksofficial/go-rally-rest-toolkit/models"
+)
+
+// User - struct to hold client
+type User struct {
+	client *RallyClient
+}
+
+// QueryUserResponse - struct to contain query response
+type QueryUserResponse = models.QueryResponse[models.User]
+
+// GetUserResponse - Struct to contain response
+type GetUserResponse struct {
+	User models.User
+}
+
+// NewUser - creates new User
+func NewUser(client *RallyClient) (u *User) {
+	return &User{
+		client: client,
+	}
+}
+
+// QueryUser - abstraction for QueryRequest, e.g. by EmailAddress or UserName
+func (s *User) QueryUser(ctx context.Context, query map[string]string) (us []models.User, err error) {
+	qus := new(QueryUserResponse)
+	err = s.client.QueryRequest(ctx, query, "user", &qus)
+	return qus.QueryResult.Results, err
+}
+
+// QueryUserWithMeta - QueryRequest that also returns the envelope's paging metadata and Warnings
+func (s *User) QueryUserWithMeta(ctx context.Context, query map[string]string) ([]models.User, QueryMeta, error) {
+	return Query[models.User](ctx, s.client, "user", query, QueryOptions{})
+}
+
+// QueryUserPage - abstraction for QueryRequestWithOptions that keeps the paging metadata
+func (s *User) QueryUserPage(ctx context.Context, query map[string]string, opts QueryOptions) (Page[models.User], error) {
+	return queryPage[models.User](ctx, s.client, query, "user", opts)
+}
+
+// QueryUserRaw - abstraction for QueryRequestRaw, for query clauses written by hand
+func (s *User) QueryUserRaw(ctx context.Context, rawQuery string, opts QueryOptions) (Page[models.User], error) {
+	return queryRawPage[models.User](ctx, s.client, rawQuery, "user", opts)
+}
+
+// QueryUserWith - abstraction for QueryRequestWith
+func (s *User) QueryUserWith(ctx context.Context, b *QueryBuilder) (Page[models.User], error) {
+	return queryWithPage[models.User](ctx, s.client, b, "user")
+}
+
+// QueryUserByEmail - looks users up by EmailAddress, the key most integrations use
+func (s *User) QueryUserByEmail(ctx context.Context, email string) (us []models.User, err error) {
+	return s.QueryUser(ctx, map[string]string{"EmailAddress": email})
+}
+
+// GetUser - abstraction for GetRequest
+func (s *User) GetUser(ctx context.Context, objectID string) (u models.User, err error) {
+	gu := new(GetUserResponse)
+	err = s.client.GetRequest(ctx, objectID, "user", &gu)
+	return gu.User, err
+}
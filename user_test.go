@@ -0,0 +1,127 @@
+/**
+* Copyright 2014 Comcast Cable Communications Management, LLC
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+* http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package rallyresttoolkit_test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	. "github.com/aleksofficial/go-rally-rest-toolkit"
+	"github.com/aleksofficial/go-rally-rest-toolkit/fakes"
+)
+
+const userFixture = `{
+	"_ref": "https://rally1.rallydev.com/slm/webservice/v2.0/user/12345",
+	"_type": "User",
+	"ObjectID": 12345,
+	"UserName": "jdoe@example.com",
+	"EmailAddress": "jane.doe@example.com",
+	"DisplayName": "Jane Doe",
+	"FirstName": "Jane",
+	"LastName": "Doe",
+	"Disabled": true,
+	"Role": "Developer",
+	"OfficeLocation": "Philadelphia",
+	"LastLoginDate": "2016-02-29T14:03:11.120Z"
+}`
+
+func TestGetUser_IdentityFields(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{
+		FakeResponse: &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       &fakes.FakeResponseBody{Reader: bytes.NewBufferString(`{"User": ` + userFixture + `}`)},
+		},
+	}
+
+	rallyClient := New("abcdef", "http://myRallyUrl", fakeClient)
+	userClient := NewUser(rallyClient)
+
+	user, err := userClient.GetUser(context.Background(), "12345")
+	if err != nil {
+		t.Fatalf("GetUser failed unexpectedly: %v", err)
+	}
+	if user.UserName != "jdoe@example.com" || user.EmailAddress != "jane.doe@example.com" {
+		t.Errorf("unexpected UserName/EmailAddress: %q/%q", user.UserName, user.EmailAddress)
+	}
+	if user.DisplayName != "Jane Doe" || user.FirstName != "Jane" || user.LastName != "Doe" {
+		t.Errorf("unexpected names: %q/%q/%q", user.DisplayName, user.FirstName, user.LastName)
+	}
+	if !user.Disabled {
+		t.Error("expected Disabled=true")
+	}
+	if user.Role != "Developer" || user.OfficeLocation != "Philadelphia" {
+		t.Errorf("unexpected Role/OfficeLocation: %q/%q", user.Role, user.OfficeLocation)
+	}
+	if user.LastLoginDate == nil || !user.LastLoginDate.Equal(time.Date(2016, 2, 29, 14, 3, 11, 120000000, time.UTC)) {
+		t.Errorf("unexpected LastLoginDate: %v", user.LastLoginDate)
+	}
+}
+
+func TestQueryUserByEmail(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{
+		FakeResponse: &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       &fakes.FakeResponseBody{Reader: bytes.NewBufferString(`{"QueryResult": {"TotalResultCount": 1, "Results": [` + userFixture + `]}}`)},
+		},
+	}
+
+	rallyClient := New("abcdef", "http://myRallyUrl", fakeClient)
+	userClient := NewUser(rallyClient)
+
+	users, err := userClient.QueryUserByEmail(context.Background(), "jane.doe@example.com")
+	if err != nil {
+		t.Fatalf("QueryUserByEmail failed unexpectedly: %v", err)
+	}
+	if got := fakeClient.SpyRequest.URL.Path; got != "/user" {
+		t.Errorf("expected query against /user, got %s", got)
+	}
+	if got := fakeClient.SpyRequest.URL.Query().Get("query"); got != "( EmailAddress = jane.doe@example.com )" {
+		t.Errorf("unexpected query: %s", got)
+	}
+	if len(users) != 1 || users[0].EmailAddress != "jane.doe@example.com" {
+		t.Errorf("unexpected users: %+v", users)
+	}
+}
+
+func TestQueryUser_NeverLoggedIn(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{
+		FakeResponse: &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       &fakes.FakeResponseBody{Reader: bytes.NewBufferString(`{"QueryResult": {"TotalResultCount": 1, "Results": [{"ObjectID": 12346, "UserName": "new@example.com", "Disabled": false, "LastLoginDate": null}]}}`)},
+		},
+	}
+
+	rallyClient := New("abcdef", "http://myRallyUrl", fakeClient)
+	userClient := NewUser(rallyClient)
+
+	users, err := userClient.QueryUser(context.Background(), map[string]string{"UserName": "new@example.com"})
+	if err != nil {
+		t.Fatalf("QueryUser failed unexpectedly: %v", err)
+	}
+	if len(users) != 1 {
+		t.Fatalf("expected 1 user, got %d", len(users))
+	}
+	if users[0].Disabled {
+		t.Error("expected Disabled=false")
+	}
+	if users[0].LastLoginDate != nil {
+		t.Errorf("expected no LastLoginDate, got %v", users[0].LastLoginDate)
+	}
+}
@@ -0,0 +1,76 @@
+/**
+* Copyright 2014 Comcast Cable Communications Management, LLC
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+* http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package rallyresttoolkit
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ValidateCreate checks the fields of a create or update body against the
+// fields of the model for queryType and returns an *UnknownFieldsError listing
+// any it does not recognise. The body may be a model, a map or either one
+// wrapped in its {"<Type>": {...}} envelope. Custom fields (prefixed "c_") are
+// always allowed, and types without a model are not validated.
+//
+// Rally silently ignores unknown fields, so this catches typos before the
+// round trip. Set Config.StrictFields to run it on every create and update.
+func ValidateCreate(queryType string, body interface{}) error {
+	e, ok := lookupEntity(queryType)
+	if !ok {
+		return nil
+	}
+
+	content, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("failed to marshal request body: %w", err)
+	}
+	fields := map[string]json.RawMessage{}
+	if err := json.Unmarshal(content, &fields); err != nil {
+		return fmt.Errorf("request body must be a JSON object: %w", err)
+	}
+	if len(fields) == 1 {
+		for key, inner := range fields {
+			if strings.EqualFold(key, e.name) {
+				fields = map[string]json.RawMessage{}
+				if err := json.Unmarshal(inner, &fields); err != nil {
+					return fmt.Errorf("%s must be a JSON object: %w", key, err)
+				}
+			}
+		}
+	}
+
+	known := map[string]bool{}
+	for _, name := range fieldNames(e.model) {
+		known[strings.ToLower(name)] = true
+	}
+
+	var unknown []string
+	for key := range fields {
+		if known[strings.ToLower(key)] || strings.HasPrefix(key, "c_") {
+			continue
+		}
+		unknown = append(unknown, key)
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return &UnknownFieldsError{QueryType: queryType, Fields: unknown}
+	}
+	return nil
+}
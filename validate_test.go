@@ -0,0 +1,150 @@
+/**
+* Copyright 2014 Comcast Cable Communications Management, LLC
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+* http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package rallyresttoolkit_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	. "github.com/aleksofficial/go-rally-rest-toolkit"
+	"github.com/aleksofficial/go-rally-rest-toolkit/fakes"
+	"github.com/aleksofficial/go-rally-rest-toolkit/models"
+)
+
+func TestValidateCreate_UnknownFields(t *testing.T) {
+	body := map[string]interface{}{
+		"Defect": map[string]interface{}{
+			"Name":     "Bug in login",
+			"Severty":  "Major Problem",
+			"Priorty":  "High",
+			"c_Region": "EMEA",
+		},
+	}
+
+	err := ValidateCreate("defect", body)
+	var fieldsErr *UnknownFieldsError
+	if !errors.As(err, &fieldsErr) {
+		t.Fatalf("expected *UnknownFieldsError, got %v", err)
+	}
+	if !reflect.DeepEqual(fieldsErr.Fields, []string{"Priorty", "Severty"}) {
+		t.Errorf("expected unknown fields [Priorty Severty], got %v", fieldsErr.Fields)
+	}
+}
+
+func TestValidateCreate_KnownFields(t *testing.T) {
+	tests := map[string]interface{}{
+		"bare map":       map[string]interface{}{"Name": "Bug in login", "severity": "Major Problem"},
+		"wrapped map":    map[string]interface{}{"Defect": map[string]interface{}{"Name": "Bug in login", "_ref": "/defect/1"}},
+		"model":          models.Defect{Name: "Bug in login", Blocked: models.Bool(true)},
+		"wrapped model":  CreateDefectRequest{Defect: models.Defect{Name: "Bug in login"}},
+		"custom field":   map[string]interface{}{"c_Region": "EMEA"},
+		"embedded field": map[string]interface{}{"ObjectID": 12345},
+	}
+
+	for name, body := range tests {
+		if err := ValidateCreate("Defect", body); err != nil {
+			t.Errorf("%s: expected no error, got %v", name, err)
+		}
+	}
+}
+
+func TestValidateCreate_UnknownTypeIsNotValidated(t *testing.T) {
+	if err := ValidateCreate("milestone", map[string]interface{}{"Anything": 1}); err != nil {
+		t.Errorf("expected no error for a type without a model, got %v", err)
+	}
+}
+
+func TestCreateRequest_StrictFieldsRejectsBeforeSending(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{FakeResponse: okResponse()}
+
+	rallyClient := New("abcdef", "http://myRallyUrl", fakeClient)
+	rallyClient.SetConfig(&Config{StrictFields: true})
+
+	body := map[string]interface{}{"Defect": map[string]interface{}{"Nmae": "Bug in login"}}
+	output := map[string]interface{}{}
+	err := rallyClient.CreateRequest(context.Background(), "defect", body, &output)
+
+	var fieldsErr *UnknownFieldsError
+	if !errors.As(err, &fieldsErr) {
+		t.Fatalf("expected *UnknownFieldsError, got %v", err)
+	}
+	if fakeClient.CallCount != 0 {
+		t.Errorf("expected no request to be sent, got %d calls", fakeClient.CallCount)
+	}
+}
+
+func TestUpdateRequest_NotStrictByDefault(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{FakeResponse: okResponse()}
+
+	rallyClient := New("abcdef", "http://myRallyUrl", fakeClient)
+
+	body := map[string]interface{}{"Defect": map[string]interface{}{"Nmae": "Bug in login"}}
+	output := map[string]interface{}{}
+	if err := rallyClient.UpdateRequest(context.Background(), "12345", "defect", body, &output); err != nil {
+		t.Fatalf("UpdateRequest failed unexpectedly: %v", err)
+	}
+	if fakeClient.CallCount != 1 {
+		t.Errorf("expected 1 call, got %d", fakeClient.CallCount)
+	}
+}
+
+func TestQueryRequest_ValidateEntityTypes(t *testing.T) {
+	tests := []struct {
+		queryType string
+		known     bool
+	}{
+		{"defect", true},
+		{"HierarchicalRequirement", true},
+		{"portfolioitem/Epic", true},
+		{"milestone", true},
+		{"defct", false},
+		{"portfolioitem/feature/extra", false},
+	}
+
+	for _, tt := range tests {
+		fakeClient := &fakes.FakeHTTPClient{FakeResponse: okResponse()}
+		rallyClient := New("abcdef", "http://myRallyUrl", fakeClient)
+		rallyClient.SetConfig(&Config{ValidateEntityTypes: true})
+
+		output := map[string]interface{}{}
+		err := rallyClient.QueryRequest(context.Background(), nil, tt.queryType, &output)
+		if tt.known {
+			if err != nil {
+				t.Errorf("%s: unexpected error %v", tt.queryType, err)
+			}
+			continue
+		}
+		if !errors.Is(err, ErrUnknownEntityType) {
+			t.Errorf("%s: expected ErrUnknownEntityType, got %v", tt.queryType, err)
+		}
+		if fakeClient.CallCount != 0 {
+			t.Errorf("%s: expected no request to be sent, got %d calls", tt.queryType, fakeClient.CallCount)
+		}
+	}
+}
+
+func TestGetRequest_UnknownEntityTypeAllowedByDefault(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{FakeResponse: okResponse()}
+	rallyClient := New("abcdef", "http://myRallyUrl", fakeClient)
+
+	output := map[string]interface{}{}
+	if err := rallyClient.GetRequest(context.Background(), "1", "customtype", &output); err != nil {
+		t.Errorf("expected custom types to work by default, got %v", err)
+	}
+}
@@ -0,0 +1,115 @@
+/**
+* Copyright 2014 Comcast Cable Communications Management, LLC
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+* http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package rallyresttoolkit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+
+	"github.com/aleksofficial/go-rally-rest-toolkit/models"
+)
+
+// webhookPath is where Rally serves webhook subscriptions, on the same host
+// as WSAPI but outside its base path.
+const webhookPath = "/apps/pigeon/api/v2/webhook"
+
+// webhookPageSize is the page size ListWebhooks requests.
+const webhookPageSize = 200
+
+// Webhook - struct to hold client
+type Webhook struct {
+	client *RallyClient
+}
+
+// NewWebhook - creates new Webhook
+func NewWebhook(client *RallyClient) (wh *Webhook) {
+	return &Webhook{
+		client: client,
+	}
+}
+
+// CreateWebhook - subscribes TargetUrl to the events matching the webhook's
+// ObjectTypes and Expressions, returning the created webhook
+func (s *Webhook) CreateWebhook(ctx context.Context, wh models.Webhook) (whr models.Webhook, err error) {
+	u, err := s.endpoint()
+	if err != nil {
+		return whr, err
+	}
+	body, err := json.Marshal(wh)
+	if err != nil {
+		return whr, fmt.Errorf("failed to marshal request body: %w", err)
+	}
+	err = s.client.execute(ctx, "POST", u, body, &whr)
+	return whr, err
+}
+
+// ListWebhooks - lists every webhook of the subscription, page by page
+func (s *Webhook) ListWebhooks(ctx context.Context) (whs []models.Webhook, err error) {
+	u, err := s.endpoint()
+	if err != nil {
+		return nil, err
+	}
+	params := url.Values{}
+	params.Set("pagesize", strconv.Itoa(webhookPageSize))
+	for {
+		u.RawQuery = params.Encode()
+		list := new(models.WebhookList)
+		if err := s.client.execute(ctx, "GET", u, nil, list); err != nil {
+			return whs, err
+		}
+		whs = append(whs, list.Results...)
+		if len(list.Results) == 0 || len(whs) >= list.TotalResultCount {
+			return whs, nil
+		}
+		params.Set("start", strconv.Itoa(list.StartIndex+len(list.Results)))
+	}
+}
+
+// GetWebhook - reads a webhook by ObjectUUID
+func (s *Webhook) GetWebhook(ctx context.Context, objectUUID string) (whr models.Webhook, err error) {
+	u, err := s.endpoint(objectUUID)
+	if err != nil {
+		return whr, err
+	}
+	err = s.client.execute(ctx, "GET", u, nil, &whr)
+	return whr, err
+}
+
+// DeleteWebhook - deletes a webhook by ObjectUUID
+func (s *Webhook) DeleteWebhook(ctx context.Context, objectUUID string) error {
+	u, err := s.endpoint(objectUUID)
+	if err != nil {
+		return err
+	}
+	return s.client.execute(ctx, "DELETE", u, nil, nil)
+}
+
+// endpoint builds a webhook URL on the host of the client's base URL.
+func (s *Webhook) endpoint(objectUUID ...string) (*url.URL, error) {
+	base, err := url.Parse(s.client.apiurl)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse URL: %w", err)
+	}
+	u := &url.URL{Scheme: base.Scheme, Host: base.Host, Path: webhookPath}
+	if len(objectUUID) > 0 {
+		u = u.JoinPath(objectUUID...)
+	}
+	return u, nil
+}
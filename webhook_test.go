@@ -0,0 +1,184 @@
+/**
+* Copyright 2014 Comcast Cable Communications Management, LLC
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+* http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package rallyresttoolkit_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	. "github.com/aleksofficial/go-rally-rest-toolkit"
+	"github.com/aleksofficial/go-rally-rest-toolkit/fakes"
+	"github.com/aleksofficial/go-rally-rest-toolkit/models"
+)
+
+const webhookJSON = `{
+	"ObjectUUID": "6a2c4e1e-3b1f-4f6a-9d3e-0c1b2a3d4e5f",
+	"Name": "Defect events",
+	"AppName": "event-bus",
+	"AppUrl": "https://events.example.com",
+	"TargetUrl": "https://events.example.com/rally",
+	"ObjectTypes": ["Defect"],
+	"Expressions": [{"AttributeName": "State", "Operator": "=", "Value": "Open"}],
+	"Enabled": true
+}`
+
+func TestCreateWebhook_PostsToWebhookPath(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{
+		FakeResponse: &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       &fakes.FakeResponseBody{Reader: bytes.NewBufferString(webhookJSON)},
+		},
+	}
+	webhookClient := NewWebhook(New("abcdef", "https://rally1.rallydev.com/slm/webservice/v2.0", fakeClient))
+
+	wh, err := webhookClient.CreateWebhook(context.Background(), models.Webhook{
+		Name:        "Defect events",
+		AppName:     "event-bus",
+		AppUrl:      "https://events.example.com",
+		TargetUrl:   "https://events.example.com/rally",
+		ObjectTypes: []string{"Defect"},
+		Expressions: []models.WebhookExpression{{AttributeName: "State", Operator: "=", Value: "Open"}},
+		Enabled:     true,
+	})
+	if err != nil {
+		t.Fatalf("CreateWebhook failed unexpectedly: %v", err)
+	}
+	if wh.ObjectUUID != "6a2c4e1e-3b1f-4f6a-9d3e-0c1b2a3d4e5f" || !wh.Enabled {
+		t.Errorf("unexpected webhook: %+v", wh)
+	}
+
+	req := fakeClient.SpyRequest
+	if req.Method != "POST" || req.URL.String() != "https://rally1.rallydev.com/apps/pigeon/api/v2/webhook" {
+		t.Errorf("unexpected request %s %s", req.Method, req.URL)
+	}
+	if req.Header.Get("ZSESSIONID") != "abcdef" {
+		t.Errorf("expected API key header, got %q", req.Header.Get("ZSESSIONID"))
+	}
+
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		t.Fatalf("failed to read request body: %v", err)
+	}
+	var sent map[string]interface{}
+	if err := json.Unmarshal(body, &sent); err != nil {
+		t.Fatalf("request body is not JSON: %v", err)
+	}
+	if sent["TargetUrl"] != "https://events.example.com/rally" || sent["Enabled"] != true {
+		t.Errorf("unexpected request body: %s", body)
+	}
+	if _, ok := sent["ObjectUUID"]; ok {
+		t.Errorf("expected ObjectUUID to be omitted, got %s", body)
+	}
+}
+
+func TestListWebhooks(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{
+		FakeResponse: &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       &fakes.FakeResponseBody{Reader: bytes.NewBufferString(`{"Results": [` + webhookJSON + `], "TotalResultCount": 1, "StartIndex": 0, "PageSize": 200}`)},
+		},
+	}
+	webhookClient := NewWebhook(New("abcdef", "https://rally1.rallydev.com/slm/webservice/v2.0", fakeClient))
+
+	whs, err := webhookClient.ListWebhooks(context.Background())
+	if err != nil {
+		t.Fatalf("ListWebhooks failed unexpectedly: %v", err)
+	}
+	if len(whs) != 1 || whs[0].Name != "Defect events" || whs[0].Expressions[0].AttributeName != "State" {
+		t.Errorf("unexpected webhooks: %+v", whs)
+	}
+	if fakeClient.SpyRequest.Method != "GET" || fakeClient.SpyRequest.URL.Path != "/apps/pigeon/api/v2/webhook" {
+		t.Errorf("unexpected request %s %s", fakeClient.SpyRequest.Method, fakeClient.SpyRequest.URL)
+	}
+}
+
+func TestListWebhooks_AllPages(t *testing.T) {
+	page := func(startIndex, count, total int) *http.Response {
+		results := make([]string, count)
+		for i := range results {
+			results[i] = fmt.Sprintf(`{"ObjectUUID": "uuid-%d"}`, startIndex+i)
+		}
+		body := fmt.Sprintf(`{"Results": [%s], "TotalResultCount": %d, "StartIndex": %d, "PageSize": 200}`,
+			strings.Join(results, ","), total, startIndex)
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       &fakes.FakeResponseBody{Reader: bytes.NewBufferString(body)},
+		}
+	}
+	fakeClient := &fakes.FakeHTTPClient{
+		FakeResponses: []*http.Response{page(0, 200, 250), page(200, 50, 250)},
+	}
+	webhookClient := NewWebhook(New("abcdef", "https://rally1.rallydev.com/slm/webservice/v2.0", fakeClient))
+
+	whs, err := webhookClient.ListWebhooks(context.Background())
+	if err != nil {
+		t.Fatalf("ListWebhooks failed unexpectedly: %v", err)
+	}
+	if len(whs) != 250 || whs[249].ObjectUUID != "uuid-249" {
+		t.Errorf("expected all 250 webhooks, got %d", len(whs))
+	}
+	if fakeClient.CallCount != 2 {
+		t.Errorf("expected 2 requests, got %d", fakeClient.CallCount)
+	}
+	if got := fakeClient.SpyRequest.URL.Query().Get("start"); got != "200" {
+		t.Errorf("expected the second page to start at 200, got %q", got)
+	}
+}
+
+func TestGetWebhook(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{
+		FakeResponse: &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       &fakes.FakeResponseBody{Reader: bytes.NewBufferString(webhookJSON)},
+		},
+	}
+	webhookClient := NewWebhook(New("abcdef", "https://rally1.rallydev.com/slm/webservice/v2.0", fakeClient))
+
+	wh, err := webhookClient.GetWebhook(context.Background(), "6a2c4e1e-3b1f-4f6a-9d3e-0c1b2a3d4e5f")
+	if err != nil {
+		t.Fatalf("GetWebhook failed unexpectedly: %v", err)
+	}
+	if wh.TargetUrl != "https://events.example.com/rally" {
+		t.Errorf("unexpected webhook: %+v", wh)
+	}
+	if got := fakeClient.SpyRequest.URL.Path; got != "/apps/pigeon/api/v2/webhook/6a2c4e1e-3b1f-4f6a-9d3e-0c1b2a3d4e5f" {
+		t.Errorf("unexpected path %s", got)
+	}
+}
+
+func TestDeleteWebhook(t *testing.T) {
+	fakeClient := &fakes.FakeHTTPClient{
+		FakeResponse: &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       &fakes.FakeResponseBody{Reader: bytes.NewBufferString(``)},
+		},
+	}
+	webhookClient := NewWebhook(New("abcdef", "https://rally1.rallydev.com/slm/webservice/v2.0", fakeClient))
+
+	if err := webhookClient.DeleteWebhook(context.Background(), "6a2c4e1e-3b1f-4f6a-9d3e-0c1b2a3d4e5f"); err != nil {
+		t.Fatalf("DeleteWebhook failed unexpectedly: %v", err)
+	}
+	if fakeClient.SpyRequest.Method != "DELETE" || fakeClient.SpyRequest.URL.Path != "/apps/pigeon/api/v2/webhook/6a2c4e1e-3b1f-4f6a-9d3e-0c1b2a3d4e5f" {
+		t.Errorf("unexpected request %s %s", fakeClient.SpyRequest.Method, fakeClient.SpyRequest.URL)
+	}
+}